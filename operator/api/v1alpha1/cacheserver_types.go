@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,25 +26,346 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // CacheServerSpec defines the desired state of CacheServer
+// +kubebuilder:validation:XValidation:rule="!has(self.rollingUpdate) || !has(self.deploymentStrategy) || self.deploymentStrategy == 'RollingUpdate'",message="rollingUpdate requires the RollingUpdate deploymentStrategy"
+// +kubebuilder:validation:XValidation:rule="!has(self.storage) || !has(self.storage.enabled) || !self.storage.enabled || !has(self.rollingUpdate)",message="rollingUpdate does not apply with storage, the cache server is recreated"
+// +kubebuilder:validation:XValidation:rule="!has(self.storage) || !has(self.storage.enabled) || !self.storage.enabled || self.replicas <= 1 || has(self.workloadType) && self.workloadType == 'StatefulSet'",message="storage requires a single replica or the StatefulSet workloadType, the PersistentVolumeClaim is ReadWriteOnce"
+// +kubebuilder:validation:XValidation:rule="!has(self.rollingUpdate) || !has(self.workloadType) || self.workloadType != 'StatefulSet'",message="rollingUpdate does not apply to the StatefulSet workloadType"
+// +kubebuilder:validation:XValidation:rule="!has(self.storage) || !has(self.storage.enabled) || !self.storage.enabled || !has(self.device)",message="device does not apply with storage, the cache is stored on the volume"
+// +kubebuilder:validation:XValidation:rule="!has(self.monitoring) || !has(self.monitoring.enabled) || !self.monitoring.enabled || !has(self.monitoring.metricsPort) || self.monitoring.metricsPort != self.port",message="monitoring.metricsPort must differ from port"
+// +kubebuilder:validation:XValidation:rule="!has(self.storage) || !has(self.storage.enabled) || !self.storage.enabled || !has(self.autoscaling) || !has(self.autoscaling.enabled) || !self.autoscaling.enabled || has(self.workloadType) && self.workloadType == 'StatefulSet'",message="autoscaling with storage requires the StatefulSet workloadType, the PersistentVolumeClaim is ReadWriteOnce"
+// +kubebuilder:validation:XValidation:rule="(has(self.mode) && self.mode == 'external') == has(self.external)",message="external is required in external mode and not allowed in lmcache mode"
+// +kubebuilder:validation:XValidation:rule="has(self.mode) && self.mode == 'external' || has(self.image)",message="image is required in lmcache mode"
+// +kubebuilder:validation:XValidation:rule="!has(self.servicePort) || !has(self.monitoring) || !has(self.monitoring.enabled) || !self.monitoring.enabled || !has(self.monitoring.metricsPort) || self.monitoring.metricsPort != self.servicePort",message="monitoring.metricsPort must differ from servicePort"
+// +kubebuilder:validation:XValidation:rule="!has(self.networkPolicy) || !has(self.networkPolicy.enabled) || !self.networkPolicy.enabled || !has(self.network) || !has(self.network.hostNetwork) || !self.network.hostNetwork",message="networkPolicy does not apply to pods on the host network"
 type CacheServerSpec struct {
-	// Image configuration for the cache server
-	Image ImageSpec `json:"image"`
+	// Mode runs the lmcache server, or points the runtimes at an external
+	// Redis or Valkey backend. The external mode creates no pods, the fields
+	// configuring them are ignored. Switching modes deletes the resources of
+	// the previous one, except the storage PersistentVolumeClaims.
+	// +kubebuilder:validation:Enum=lmcache;external
+	// +kubebuilder:default=lmcache
+	Mode string `json:"mode,omitempty"`
+
+	// External is the backend of the external mode
+	External *CacheServerExternalSpec `json:"external,omitempty"`
+
+	// Image configuration for the cache server. Required in lmcache mode.
+	Image ImageSpec `json:"image,omitempty"`
 
 	// Container port for the cache server
 	// +kubebuilder:default=8000
 	Port int32 `json:"port"`
 
+	// ServicePort is the port of the cache server Service, and of the
+	// lm:// endpoint of the runtimes. Defaults to port. Does not apply on the
+	// host network, where the headless Service exposes port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ServicePort int32 `json:"servicePort,omitempty"`
+
 	// Resource requirements
-	Resources ResourceRequirements `json:"resources"`
+	Resources CacheServerResources `json:"resources,omitempty"`
+
+	// Serde is the serialization format of the cached KV, which must match
+	// the lmCacheConfig.remoteSerde of the runtimes using the cache server
+	// +kubebuilder:validation:Enum=naive;cachegen
+	Serde string `json:"serde,omitempty"`
+
+	// Device holding the cache, the server default cpu when not set. cuda
+	// requires resources.gpu.
+	// +kubebuilder:validation:Enum=cpu;cuda
+	Device string `json:"device,omitempty"`
+
+	// ChunkSize is the number of tokens in a cached KV chunk, the LMCache
+	// default when not set
+	// +kubebuilder:validation:Minimum=1
+	ChunkSize int32 `json:"chunkSize,omitempty"`
+
+	// ExtraArgs are appended to the cache server command
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// Env sets environment variables of the cache server, e.g. LMCACHE_LOG_LEVEL.
+	// Variables set by the operator, like LMCACHE_CHUNK_SIZE, cannot be
+	// overridden.
+	Env []EnvVar `json:"env,omitempty"`
+
+	// Sources to populate environment variables from ConfigMaps and Secrets.
+	// Variables set in env take precedence over envFrom.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Config is the LMCache configuration file of the cache server, for the
+	// options without a dedicated field. Changes to it roll the pods.
+	Config *CacheServerConfigSpec `json:"config,omitempty"`
 
-	// Number of replicas
+	// Number of replicas. Ignored while autoscaling is enabled.
 	// +kubebuilder:default=1
 	Replicas int32 `json:"replicas"`
 
-	// Deployment strategy
+	// Autoscaling scales the Deployment or StatefulSet of the cache server
+	// with a HorizontalPodAutoscaler instead of replicas
+	// +kubebuilder:default={}
+	Autoscaling CacheServerAutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// PodDisruptionBudget limits voluntary disruptions of the cache server
+	// pods, e.g. node drains, so that connected runtimes keep a cache
+	PodDisruptionBudget PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// NetworkPolicy restricts the clients of the cache server to the
+	// VLLMRuntimes using it
+	NetworkPolicy CacheServerNetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// WorkloadType runs the cache server as a Deployment, or as a StatefulSet
+	// giving each replica a stable name behind the <name>-pods headless Service
+	// and, with storage, its own PersistentVolumeClaim. Switching deletes the
+	// previous workload before creating the new one.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +kubebuilder:default=Deployment
+	WorkloadType string `json:"workloadType,omitempty"`
+
+	// Deployment strategy. Recreate avoids two cache servers holding the
+	// same volume during a rollout, and is always used when storage is
+	// enabled. Does not apply to the StatefulSet workloadType.
 	// +kubebuilder:validation:Enum=RollingUpdate;Recreate
 	// +kubebuilder:default=RollingUpdate
 	DeploymentStrategy string `json:"deploymentStrategy"`
+
+	// RollingUpdate tunes the RollingUpdate deployment strategy
+	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
+
+	// Termination lets the cache server flush in-flight writes when its pods
+	// are stopped, e.g. during rollouts
+	// +kubebuilder:default={}
+	Termination CacheServerTerminationSpec `json:"termination,omitempty"`
+
+	// PodLabels are added to the cache server pods, e.g. for cost attribution.
+	// The app label is reserved for the operator, which selects the pods with it.
+	// +kubebuilder:validation:XValidation:rule="!('app' in self)",message="the app label is reserved for the operator"
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// PodAnnotations are added to the cache server pods, e.g. mesh injection
+	// toggles. Annotations managed by the operator take precedence.
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// SecurityContext of the cache server container, e.g. runAsNonRoot,
+	// dropped capabilities and a seccompProfile for the restricted Pod
+	// Security Standard. The lmcache/vllm-openai image runs as root, so
+	// runAsNonRoot also needs a runAsUser.
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// PodSecurityContext of the cache server pods. A non-root cache server
+	// with storage needs an fsGroup to write to the volume.
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// Network runs the cache server on the host network, e.g. for RDMA
+	// transports
+	Network CacheServerNetworkSpec `json:"network,omitempty"`
+
+	// NodeSelector constrains the cache server pods to the nodes with these labels
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the cache server pods, e.g. for the taints of GPU nodes
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of the cache server pods
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// ColocateWithRuntime prefers scheduling the cache server pods on the
+	// nodes running the pods of a VLLMRuntime in the CacheServer namespace,
+	// in addition to affinity
+	ColocateWithRuntime *VLLMRuntimeReference `json:"colocateWithRuntime,omitempty"`
+
+	// Storage keeps the cache on a PersistentVolumeClaim instead of the
+	// memory of the cache server
+	// +kubebuilder:default={}
+	Storage CacheServerStorageSpec `json:"storage,omitempty"`
+
+	// Monitoring exposes the Prometheus metrics of the cache server and
+	// creates a ServiceMonitor scraping them
+	// +kubebuilder:default={}
+	Monitoring CacheServerMonitoringSpec `json:"monitoring,omitempty"`
+}
+
+// CacheServerMonitoringSpec defines the metrics endpoint of the cache server
+// and the ServiceMonitor scraping it. The ServiceMonitor is only created when
+// the Prometheus Operator CRDs are installed.
+// +kubebuilder:validation:XValidation:rule="!has(self.enabled) || !self.enabled || has(self.metricsPort)",message="metricsPort is required when monitoring is enabled"
+type CacheServerMonitoringSpec struct {
+	MonitoringSpec `json:",inline"`
+
+	// MetricsPort is the port of the cache server container serving
+	// /metrics, exposed as the Service port named metrics. It is required
+	// when monitoring is enabled and has no default: the operator does not
+	// start a metrics endpoint and lmcache_experimental_server serves none,
+	// so the image or extraArgs must serve it on this port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	MetricsPort int32 `json:"metricsPort,omitempty"`
+}
+
+// VLLMRuntimeReference identifies a VLLMRuntime in the same namespace
+type VLLMRuntimeReference struct {
+	// Name of the VLLMRuntime
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// CacheServerResources defines the resources of the cache server container
+type CacheServerResources struct {
+	ResourceRequirements `json:",inline"`
+
+	// Extra extended resources requested and limited to the given count, e.g.
+	// rdma/hca: "1". The GPUs are requested with gpu.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// CacheServerNetworkPolicySpec defines the NetworkPolicy of the cache server
+// pods. It is only enforced by network plugins supporting NetworkPolicies.
+type CacheServerNetworkPolicySpec struct {
+	// Enabled creates a NetworkPolicy admitting traffic to the cache server
+	// port only from the pods of the VLLMRuntimes whose cacheServerRef or
+	// autoDiscover resolves to this CacheServer, in any namespace. The
+	// operator labels those pods with production-stack.vllm.ai/cache-server
+	// and production-stack.vllm.ai/cache-server-namespace. With monitoring,
+	// the metrics port stays open to all pods.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// CacheServerNetworkSpec defines the network of the cache server pods
+type CacheServerNetworkSpec struct {
+	// HostNetwork runs the pods in the network namespace of their node. The
+	// container ports are then host ports, so two replicas never share a
+	// node, and the cache server is reached through the <name>-pods headless
+	// Service instead of the ClusterIP Service.
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+
+	// DNSPolicy of the pods. Defaults to ClusterFirstWithHostNet with
+	// hostNetwork, so that the pods still resolve cluster names, and to
+	// ClusterFirst otherwise.
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+}
+
+// CacheServerStorageSpec defines the PersistentVolumeClaim created for the
+// cache, named <name>-storage. The StatefulSet workloadType claims one per
+// replica, named storage-<name>-<ordinal>.
+type CacheServerStorageSpec struct {
+	// Enabled creates the PersistentVolumeClaim and stores the cache in it.
+	// Disabling storage keeps the claim, and the cache in it, until the
+	// CacheServer is deleted.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Size of the PersistentVolumeClaim. Defaults to 10Gi. Increasing it
+	// expands the claim when its storage class allows volume expansion; a
+	// claim cannot shrink.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the PersistentVolumeClaim, the cluster default
+	// storage class when not set. Changing it does not affect an existing
+	// claim.
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// MountPath is where the claim is mounted in the cache server container
+	// +kubebuilder:validation:Pattern=`^/`
+	// +kubebuilder:default="/var/lib/lmcache"
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// CacheServerConfigSpec defines the LMCache configuration file, passed to the
+// server as LMCACHE_CONFIG_FILE. Inline and raw configs are rendered into the
+// <name>-config ConfigMap.
+// +kubebuilder:validation:XValidation:rule="(has(self.inline) ? 1 : 0) + (has(self.raw) ? 1 : 0) + (has(self.existingConfigMap) ? 1 : 0) == 1",message="exactly one of inline, raw and existingConfigMap must be set"
+type CacheServerConfigSpec struct {
+	// Inline options of the config file, e.g. local_cpu: "false". The values
+	// are written unquoted, so YAML types apply.
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[a-z0-9_]+$'))",message="inline keys must be LMCache option names"
+	Inline map[string]string `json:"inline,omitempty"`
+
+	// Raw YAML content of the config file
+	Raw string `json:"raw,omitempty"`
+
+	// ExistingConfigMap selects the key of a ConfigMap in the CacheServer
+	// namespace holding the config file
+	ExistingConfigMap *ConfigMapKeyReference `json:"existingConfigMap,omitempty"`
+}
+
+// CacheServerAutoscalingSpec defines the HorizontalPodAutoscaler of a cache
+// server, scaling on CPU utilization
+// +kubebuilder:validation:XValidation:rule="!has(self.enabled) || !self.enabled || has(self.maxReplicas)",message="maxReplicas is required when autoscaling is enabled"
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || !has(self.maxReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type CacheServerAutoscalingSpec struct {
+	// Enabled creates the HorizontalPodAutoscaler. The replicas of the
+	// workload are left to it while enabled.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MinReplicas is the lower limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// TargetCPUUtilization is the target average CPU utilization in percent of
+	// the requested CPU. Defaults to 80.
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+}
+
+// CacheServerExternalSpec defines the Redis or Valkey backend of a CacheServer
+// in external mode
+type CacheServerExternalSpec struct {
+	// URL of the backend with the redis, rediss or redis-sentinel scheme of
+	// LMCache, e.g. redis://valkey.cache.svc:6379. Valkey is reached with the
+	// redis schemes.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// CredentialsSecretRef selects the key of a Secret in the CacheServer
+	// namespace holding the password of the backend. Runtimes in the
+	// CacheServer namespace receive it as LMCACHE_REMOTE_PASSWORD, which the
+	// remote URL references, so it must not need URL escaping.
+	CredentialsSecretRef *SecretKeyReference `json:"credentialsSecretRef,omitempty"`
+
+	// Service creates an ExternalName Service named after the CacheServer,
+	// resolving to the host of url, which the runtimes then connect to.
+	// Requires a single host.
+	Service bool `json:"service,omitempty"`
+}
+
+// CacheServerTerminationSpec defines the shutdown of the cache server pods
+// +kubebuilder:validation:XValidation:rule="!(has(self.preStopCommand) && has(self.preStopHTTPGet))",message="preStopCommand and preStopHTTPGet are mutually exclusive"
+type CacheServerTerminationSpec struct {
+	// GracePeriodSeconds is the pod termination grace period, covering the
+	// preStop hook and the flush of the cache server after SIGTERM. With the
+	// RollingUpdate deploymentStrategy a new pod serves while the old one
+	// flushes, so a longer grace period only slows the rollout. With Recreate,
+	// storage or the StatefulSet workloadType, the replacement pod waits for
+	// the old one to exit, so the grace period adds to the time without a
+	// cache.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// PreStopCommand is run in the cache server container before it receives
+	// SIGTERM, e.g. to flush the cache
+	PreStopCommand []string `json:"preStopCommand,omitempty"`
+
+	// PreStopHTTPGet calls an endpoint of the cache server before it receives
+	// SIGTERM, for LMCache releases serving a flush endpoint
+	PreStopHTTPGet *CacheServerPreStopHTTPGet `json:"preStopHTTPGet,omitempty"`
+}
+
+// CacheServerPreStopHTTPGet defines the HTTP request of the preStop hook
+type CacheServerPreStopHTTPGet struct {
+	// Path of the endpoint, e.g. /flush
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// Port of the endpoint. Defaults to port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
 }
 
 // CacheServerStatus defines the observed state of CacheServer
@@ -52,11 +375,62 @@ type CacheServerStatus struct {
 
 	// Current status of the cache server
 	Status string `json:"status,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ReadyReplicas is the number of ready cache server pods
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is the label selector of the cache server pods, used by the
+	// scale subresource
+	Selector string `json:"selector,omitempty"`
+
+	// DesiredReplicas is the number of replicas wanted by the
+	// HorizontalPodAutoscaler when autoscaling is enabled
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// Ready is the number of ready pods out of the desired replicas, e.g. 1/2
+	Ready string `json:"ready,omitempty"`
+
+	// Endpoint is the in-cluster host and port of the cache server Service,
+	// the remote URL of LMCache with the lm:// scheme. In external mode it is
+	// the host and port of the backend, or of its ExternalName Service.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Conditions represent the latest available observations of the CacheServer state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// CacheServerLabel and CacheServerNamespaceLabel carry the name and the
+// namespace of the CacheServer a VLLMRuntime uses on its pods. The
+// NetworkPolicy of the CacheServer admits the pods labeled with both.
+const (
+	CacheServerLabel          = "production-stack.vllm.ai/cache-server"
+	CacheServerNamespaceLabel = "production-stack.vllm.ai/cache-server-namespace"
+)
+
+// ConditionStorageReady reports whether the PersistentVolumeClaims of the
+// cache server are bound with the requested size
+const ConditionStorageReady = "StorageReady"
+
+// ConditionConfigResolved reports whether the config file of the cache server
+// exists and parses
+const ConditionConfigResolved = "ConfigResolved"
+
+// ConditionWorkloadReplaced reports that the previous workload of the cache
+// server is being deleted before its replacement is created, after a change
+// of workloadType or of the claims of the StatefulSet
+const ConditionWorkloadReplaced = "WorkloadReplaced"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.readyReplicas,selectorpath=.status.selector
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".status.endpoint"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // CacheServer is the Schema for the cacheservers API
@@ -16,6 +16,11 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
 // ResourceRequirements defines the resource requirements
 type ResourceRequirements struct {
 	CPU    string `json:"cpu,omitempty"`
@@ -24,9 +29,215 @@ type ResourceRequirements struct {
 }
 
 // ImageSpec defines the container image configuration
+// +kubebuilder:validation:XValidation:rule="!(has(self.tag) && has(self.digest))",message="tag and digest are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.tag) || has(self.digest)) || !self.name.matches(':[^/]*$')",message="tag and digest cannot be combined with a tag in name"
 type ImageSpec struct {
-	Registry       string `json:"registry"`
-	Name           string `json:"name"`
+	Registry string `json:"registry"`
+	// Name of the image, optionally with a tag when tag and digest are not set
+	Name string `json:"name"`
+
+	// Tag of the image
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the image, e.g. sha256:<hex>
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Digest string `json:"digest,omitempty"`
+
 	PullPolicy     string `json:"pullPolicy,omitempty"`
 	PullSecretName string `json:"pullSecretName,omitempty"`
+
+	// RolloutOnChange records the resolved image in a pod template annotation
+	// so that any image change rolls the pods. Only used by VLLMRuntime.
+	RolloutOnChange bool `json:"rolloutOnChange,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines a PodDisruptionBudget protecting the pods of a Deployment
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type PodDisruptionBudgetSpec struct {
+	// Enabled creates the PodDisruptionBudget
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MinAvailable is the number or percentage of pods that must remain available
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that can be disrupted.
+	// Defaults to 1 when minAvailable is not set.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// RollingUpdateSpec defines the surge parameters of a rolling update
+type RollingUpdateSpec struct {
+	// MaxSurge is the number or percentage of pods created above the desired
+	// replicas during a rollout. Defaults to 25%.
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that can be
+	// unavailable during a rollout. Defaults to 25%.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// AutoscalingSpec defines a HorizontalPodAutoscaler scaling a Deployment
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.maxReplicas)",message="maxReplicas is required when autoscaling is enabled"
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || !has(self.maxReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+// +kubebuilder:validation:XValidation:rule="!has(self.scaledObject) || !self.scaledObject.enabled || !has(self.targetCPUUtilization) && !has(self.metrics)",message="targetCPUUtilization and metrics configure the HorizontalPodAutoscaler and are not allowed with scaledObject"
+type AutoscalingSpec struct {
+	// Enabled creates the HorizontalPodAutoscaler. The replicas of the
+	// Deployment are left to it while enabled.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MinReplicas is the lower limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// TargetCPUUtilization is the target average CPU utilization in percent of
+	// the requested CPU. Defaults to 80 when no metrics are set.
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+
+	// Metrics are additional metrics to scale on
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+
+	// ScaledObject scales with a KEDA ScaledObject instead of the
+	// HorizontalPodAutoscaler, e.g. on the request metrics of the router
+	ScaledObject ScaledObjectSpec `json:"scaledObject,omitempty"`
+}
+
+// ScaledObjectSpec defines a KEDA ScaledObject scaling a Deployment between
+// the minReplicas and maxReplicas of its AutoscalingSpec
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.triggers)",message="triggers are required when scaledObject is enabled"
+type ScaledObjectSpec struct {
+	// Enabled creates the ScaledObject. Requires the KEDA CRDs.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// PollingInterval is the interval in seconds at which KEDA checks the
+	// triggers. Defaults to the KEDA default of 30.
+	// +kubebuilder:validation:Minimum=1
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+
+	// CooldownPeriod is the time in seconds to wait after the last active
+	// trigger before scaling to minReplicas. Defaults to the KEDA default of 300.
+	// +kubebuilder:validation:Minimum=0
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+
+	// Triggers are the KEDA scalers, e.g. a prometheus trigger on
+	// vllm:num_requests_running scraped from the router
+	// +kubebuilder:validation:MaxItems=16
+	Triggers []ScaledObjectTrigger `json:"triggers,omitempty"`
+}
+
+// ScaledObjectTrigger is a KEDA trigger, see https://keda.sh/docs/latest/scalers/
+type ScaledObjectTrigger struct {
+	// Type is the scaler, e.g. prometheus
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// Name identifies the trigger
+	Name string `json:"name,omitempty"`
+
+	// MetricType is the target type of the metric
+	// +kubebuilder:validation:Enum=AverageValue;Value;Utilization
+	MetricType string `json:"metricType,omitempty"`
+
+	// Metadata configures the scaler, e.g. serverAddress, query and threshold
+	Metadata map[string]string `json:"metadata"`
+
+	// AuthenticationRef names the TriggerAuthentication of the scaler
+	AuthenticationRef *ScaledObjectAuthenticationRef `json:"authenticationRef,omitempty"`
+}
+
+// ScaledObjectAuthenticationRef references a KEDA TriggerAuthentication or
+// ClusterTriggerAuthentication
+type ScaledObjectAuthenticationRef struct {
+	// Name of the TriggerAuthentication
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the referenced object
+	// +kubebuilder:validation:Enum=TriggerAuthentication;ClusterTriggerAuthentication
+	// +kubebuilder:default=TriggerAuthentication
+	Kind string `json:"kind,omitempty"`
+}
+
+// MonitoringSpec defines a Prometheus Operator ServiceMonitor scraping a Service
+type MonitoringSpec struct {
+	// Enabled creates the ServiceMonitor
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Interval between scrapes, e.g. 30s. Defaults to the Prometheus scrape interval.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(ms|s|m|h))+$`
+	Interval string `json:"interval,omitempty"`
+
+	// ExtraLabels are added to the ServiceMonitor, e.g. to match the
+	// serviceMonitorSelector of a Prometheus
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+}
+
+// ConditionMonitoringCRDsMissing reports that monitoring is enabled but the
+// Prometheus Operator CRDs are not installed in the cluster
+const ConditionMonitoringCRDsMissing = "MonitoringCRDsMissing"
+
+// IngressSpec defines an Ingress exposing a Service
+type IngressSpec struct {
+	// Enabled creates the Ingress
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ClassName is the IngressClass handling the Ingress
+	ClassName string `json:"className,omitempty"`
+
+	// Host is the host name routed to the Service
+	Host string `json:"host,omitempty"`
+
+	// Path is the path prefix routed to the Service
+	// +kubebuilder:default="/"
+	Path string `json:"path,omitempty"`
+
+	// TLS terminates HTTPS at the Ingress
+	TLS *IngressTLS `json:"tls,omitempty"`
+
+	// Annotations added to the Ingress, e.g. for the ingress controller
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// IngressTLS defines the TLS configuration of an Ingress
+type IngressTLS struct {
+	// SecretName is the Secret holding the TLS certificate and key
+	SecretName string `json:"secretName"`
+}
+
+// HTTPRouteSpec defines a Gateway API HTTPRoute exposing a Service
+// +kubebuilder:validation:XValidation:rule="!self.enabled || (has(self.parentRefs) && size(self.parentRefs) > 0)",message="parentRefs is required when the HTTPRoute is enabled"
+type HTTPRouteSpec struct {
+	// Enabled creates the HTTPRoute
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ParentRefs are the Gateways the HTTPRoute attaches to
+	// +kubebuilder:validation:MaxItems=32
+	ParentRefs []GatewayParentReference `json:"parentRefs,omitempty"`
+
+	// Hostnames matched by the HTTPRoute
+	// +kubebuilder:validation:MaxItems=16
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// PathPrefix is the path prefix routed to the Service
+	// +kubebuilder:default="/"
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// GatewayParentReference identifies a Gateway an HTTPRoute attaches to
+type GatewayParentReference struct {
+	// Name of the Gateway
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the namespace of the route
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName selects a listener of the Gateway
+	SectionName string `json:"sectionName,omitempty"`
 }
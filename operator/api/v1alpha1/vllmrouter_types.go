@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,48 +26,160 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // VLLMRouterSpec defines the desired state of VLLMRouter
+// +kubebuilder:validation:XValidation:rule="!has(self.routingLogic) || self.routingLogic != 'session' || (has(self.sessionKey) && size(self.sessionKey) > 0)",message="sessionKey is required with session routing"
+// +kubebuilder:validation:XValidation:rule="has(self.routingLogic) && self.routingLogic == 'kvaware' || !has(self.lmcacheControllerPort)",message="lmcacheControllerPort requires kvaware routing"
+// +kubebuilder:validation:XValidation:rule="has(self.routingLogic) && self.routingLogic == 'disaggregated_prefill' ? has(self.prefillModelLabels) && has(self.decodeModelLabels) : !has(self.prefillModelLabels) && !has(self.decodeModelLabels)",message="prefillModelLabels and decodeModelLabels are required with, and only allowed with, disaggregated_prefill routing"
+// +kubebuilder:validation:XValidation:rule="!has(self.rollingUpdate) || !has(self.deploymentStrategy) || self.deploymentStrategy == 'RollingUpdate'",message="rollingUpdate requires the RollingUpdate deploymentStrategy"
+// +kubebuilder:validation:XValidation:rule="!has(self.retryPolicy) || !has(self.retryPolicy.maxRetries) || self.retryPolicy.maxRetries == 0 || has(self.backendTimeouts) && (has(self.backendTimeouts.connectSeconds) || has(self.backendTimeouts.readSeconds))",message="retryPolicy.maxRetries requires a backendTimeouts timeout, a backend hanging without one is never retried"
+// +kubebuilder:validation:XValidation:rule="!has(self.runtimeSelector) || (has(self.serviceDiscovery) && self.serviceDiscovery == 'static')",message="runtimeSelector requires serviceDiscovery static"
+// +kubebuilder:validation:XValidation:rule="!has(self.watchNamespaces) || (has(self.serviceDiscovery) && self.serviceDiscovery == 'k8s')",message="watchNamespaces requires serviceDiscovery k8s"
+// +kubebuilder:validation:XValidation:rule="!has(self.watchNamespaces) || !self.watchNamespaces.exists(n, n == '*') || size(self.watchNamespaces) == 1",message="watchNamespaces must not list namespaces next to *"
 type VLLMRouterSpec struct {
-	// EnableRouter determines if the router should be deployed
+	// EnableRouter determines if the router should be deployed. Set to false,
+	// the router Deployment and Service are deleted and the other resources of
+	// the router are left alone until it is enabled again.
 	// +kubebuilder:default=true
-	EnableRouter bool `json:"enableRouter,omitempty"`
+	EnableRouter *bool `json:"enableRouter,omitempty"`
 
 	// Replicas specifies the number of router replicas
 	// +kubebuilder:default=1
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// PodDisruptionBudget limits voluntary disruptions of the router pods,
+	// e.g. node drains. It is only created with more than one replica.
+	PodDisruptionBudget PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// Autoscaling scales the router with a HorizontalPodAutoscaler, or a KEDA
+	// ScaledObject, instead of replicas
+	Autoscaling AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// IgnoreReplicaDrift leaves the replica count of an existing Deployment
+	// alone, e.g. when it is managed by an external HorizontalPodAutoscaler
+	IgnoreReplicaDrift bool `json:"ignoreReplicaDrift,omitempty"`
+
+	// Deploy strategy of the router Deployment
+	// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+	// +kubebuilder:default=RollingUpdate
+	DeployStrategy string `json:"deploymentStrategy,omitempty"`
+
+	// RollingUpdate tunes the RollingUpdate deploy strategy
+	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
+
+	// Termination configures how router pods finish in-flight streamed
+	// responses on shutdown. For streaming workloads, gracePeriodSeconds should
+	// cover the longest expected completion plus drainSleepSeconds, and
+	// drainSleepSeconds the time load balancers take to stop sending new
+	// connections, e.g. 300 and 15.
+	// +kubebuilder:default={}
+	Termination RouterTerminationSpec `json:"termination,omitempty"`
+
 	// ServiceDiscovery specifies the service discovery method (k8s or static)
 	// +kubebuilder:validation:Enum=k8s;static
 	// +kubebuilder:default=k8s
 	ServiceDiscovery string `json:"serviceDiscovery,omitempty"`
 
-	// K8sLabelSelector specifies the label selector for vLLM runtime pods when using k8s service discovery
-	// +kubebuilder:validation:RequiredWhen=ServiceDiscovery=k8s
+	// K8sLabelSelector specifies the label selector for vLLM runtime pods when using k8s service discovery.
+	// Required with k8s service discovery.
 	K8sLabelSelector string `json:"k8sLabelSelector,omitempty"`
 
-	// StaticBackends is required when using static service discovery
-	// +kubebuilder:validation:RequiredWhen=ServiceDiscovery=static
+	// WatchNamespaces are the namespaces whose pods are discovered with k8s
+	// service discovery, e.g. per-team runtime namespaces served by a shared
+	// router. Unset watches the router namespace and "*" watches all
+	// namespaces. Namespaces other than the router namespace require
+	// rbac.clusterWide when the operator creates the router RBAC.
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Pattern=`^(\*|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	// +kubebuilder:validation:items:MaxLength=63
+	// +listType=set
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// StaticBackends are the comma-separated http(s) URLs of the backends,
+	// required when using static service discovery without runtimeSelector
 	StaticBackends string `json:"staticBackends,omitempty"`
 
-	// StaticModels is required when using static service discovery
-	// +kubebuilder:validation:RequiredWhen=ServiceDiscovery=static
+	// StaticModels are the comma-separated models served by staticBackends,
+	// one per backend, required when using static service discovery without
+	// runtimeSelector
 	StaticModels string `json:"staticModels,omitempty"`
 
+	// RuntimeSelector selects the VLLMRuntimes in the namespace whose Services
+	// and models are passed to the router as static backends, replacing
+	// staticBackends and staticModels. Requires static service discovery.
+	RuntimeSelector *metav1.LabelSelector `json:"runtimeSelector,omitempty"`
+
 	// RoutingLogic specifies the routing strategy
-	// +kubebuilder:validation:Enum=roundrobin;session
+	// +kubebuilder:validation:Enum=roundrobin;session;prefixaware;kvaware;disaggregated_prefill
 	// +kubebuilder:default=roundrobin
 	RoutingLogic string `json:"routingLogic,omitempty"`
 
-	// SessionKey for session-based routing
-	// +kubebuilder:validation:RequiredWhen=RoutingLogic=session
+	// SessionKey for session-based routing, required with session routing
 	// +kubebuilder:default=""
 	SessionKey string `json:"sessionKey,omitempty"`
 
+	// LMCacheControllerPort is the port the LMCache controller of kvaware
+	// routing listens on for the engines. Defaults to 9000.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	LMCacheControllerPort int32 `json:"lmcacheControllerPort,omitempty"`
+
+	// PrefillModelLabels are the model labels of the prefill engines with
+	// disaggregated_prefill routing
+	// +kubebuilder:validation:MinItems=1
+	PrefillModelLabels []string `json:"prefillModelLabels,omitempty"`
+
+	// DecodeModelLabels are the model labels of the decode engines with
+	// disaggregated_prefill routing
+	// +kubebuilder:validation:MinItems=1
+	DecodeModelLabels []string `json:"decodeModelLabels,omitempty"`
+
 	// EngineScrapeInterval for collecting engine statistics
 	EngineScrapeInterval int32 `json:"engineScrapeInterval,omitempty"`
 
 	// RequestStatsWindow for request statistics
 	RequestStatsWindow int32 `json:"requestStatsWindow,omitempty"`
 
+	// LogLevel of the router, passed as --log-level. Supported by all router
+	// releases of this repository. Defaults to the router default, info.
+	// +kubebuilder:validation:Enum=debug;info;warning;error
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// RequestTimeoutSeconds bounds the time the router waits for a backend
+	// response, passed as --request-timeout. Accepted by router releases from
+	// 0.1.3; routers without it exit on the unknown argument. Not set, the
+	// router does not time out requests.
+	// +kubebuilder:validation:Minimum=1
+	RequestTimeoutSeconds int32 `json:"requestTimeoutSeconds,omitempty"`
+
+	// UvicornWorkers is the number of uvicorn worker processes of the router,
+	// passed as --workers. Accepted by router releases from 0.1.3; routers
+	// without it exit on the unknown argument. Not set, the router runs a
+	// single worker.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	UvicornWorkers int32 `json:"uvicornWorkers,omitempty"`
+
+	// BackendTimeouts bound the connection to and the responses of the
+	// backends, so that a hanging backend fails the request
+	BackendTimeouts RouterBackendTimeouts `json:"backendTimeouts,omitempty"`
+
+	// RetryPolicy retries requests on another attempt when a backend fails
+	// before the response starts
+	RetryPolicy RouterRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// MaxConcurrentRequests is the number of requests a router replica
+	// forwards at a time, further requests are rejected with 429. Passed as
+	// --max-concurrent-requests, accepted by router releases from 0.1.3. Not
+	// set, the router does not limit concurrency.
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
+
+	// RouterVersion is the release of the router image, e.g. 0.1.2. When set,
+	// the flags the release does not accept are left out of the router args
+	// and reported by the RouterArgsOmitted condition. Not set, every flag is
+	// rendered.
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?([-+].*)?$`
+	RouterVersion string `json:"routerVersion,omitempty"`
+
 	// ExtraArgs for additional router arguments
 	ExtraArgs []string `json:"extraArgs,omitempty"`
 
@@ -76,10 +189,45 @@ type VLLMRouterSpec struct {
 	// ServiceAccountName for the router pod
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
+	// RBAC configures the ServiceAccount, Role and RoleBinding of the router
+	RBAC RBACSpec `json:"rbac,omitempty"`
+
 	// ContainerPort for the router service
 	// +kubebuilder:default=80
 	Port int32 `json:"port,omitempty"`
 
+	// Service configures the router Service
+	Service RouterServiceSpec `json:"service,omitempty"`
+
+	// TLS serves HTTPS from the router itself
+	TLS RouterTLSSpec `json:"tls,omitempty"`
+
+	// FeatureGates enable optional router APIs
+	// +kubebuilder:default={}
+	FeatureGates RouterFeatureGates `json:"featureGates,omitempty"`
+
+	// FileStorage is the volume holding the files of the batch API
+	// +kubebuilder:default={}
+	FileStorage RouterFileStorageSpec `json:"fileStorage,omitempty"`
+
+	// DynamicConfig mounts a dynamic config ConfigMap, e.g. one generated by a
+	// StaticRoute, into the router
+	DynamicConfig RouterDynamicConfigSpec `json:"dynamicConfig,omitempty"`
+
+	// Ingress exposes the router Service outside the cluster
+	Ingress IngressSpec `json:"ingress,omitempty"`
+
+	// HTTPRoute exposes the router Service through a Gateway API Gateway
+	HTTPRoute HTTPRouteSpec `json:"httpRoute,omitempty"`
+
+	// Monitoring creates a ServiceMonitor scraping /metrics on the router
+	// Service
+	Monitoring MonitoringSpec `json:"monitoring,omitempty"`
+
+	// Probes tunes the liveness and readiness probes of the router, both
+	// against /health
+	Probes RouterProbes `json:"probes,omitempty"`
+
 	// Image configuration
 	Image ImageSpec `json:"image"`
 
@@ -89,9 +237,196 @@ type VLLMRouterSpec struct {
 	// Environment variables
 	Env []EnvVar `json:"env,omitempty"`
 
+	// PodLabels are added to the router pods, e.g. for cost attribution.
+	// The app label is reserved for the operator.
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// PodAnnotations are added to the router pods, e.g. Prometheus scrape
+	// annotations. Annotations managed by the operator take precedence.
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// Volumes added to the router pods, e.g. CA bundles for backend TLS. The
+	// names dynamic-config, tmp, tls and files are reserved for volumes created by the operator.
+	// +kubebuilder:validation:MaxItems=32
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts added to the router container. Each mount must reference
+	// a volume in volumes.
+	// +kubebuilder:validation:MaxItems=32
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Sidecars are added to the router pods after the router container, e.g.
+	// an auth proxy fronting the router, see service.targetPortName. They
+	// share the pod volumes. A pod only receives Service traffic once all of
+	// its containers are ready.
+	// +kubebuilder:validation:MaxItems=8
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// SecurityContext of the router container, e.g. runAsNonRoot, dropped
+	// capabilities and a seccompProfile for the restricted Pod Security
+	// Standard. readOnlyRootFilesystem is not set by default; when enabled the
+	// operator mounts an emptyDir at /tmp, the only path written by the router.
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// PodSecurityContext of the router pods
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
 	// VLLM API Key configuration
 	VLLMApiKeySecret corev1.LocalObjectReference `json:"vllmApiKeySecret,omitempty"`
 	VLLMApiKeyName   string                      `json:"vllmApiKeyName,omitempty"`
+
+	// RestartOnSecretChange rolls the router pods when the API key in
+	// vllmApiKeySecret changes
+	RestartOnSecretChange bool `json:"restartOnSecretChange,omitempty"`
+}
+
+// RouterTerminationSpec defines the shutdown behavior of the router pods
+// +kubebuilder:validation:XValidation:rule="!has(self.drainSleepSeconds) || self.drainSleepSeconds == 0 || !has(self.gracePeriodSeconds) || self.drainSleepSeconds < self.gracePeriodSeconds",message="drainSleepSeconds must be less than gracePeriodSeconds"
+type RouterTerminationSpec struct {
+	// GracePeriodSeconds is the pod termination grace period. It should cover
+	// the longest expected streamed response.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=120
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// DrainSleepSeconds delays shutdown with a preStop hook so load balancers
+	// stop sending traffic to the pod before the router receives SIGTERM. 0
+	// disables the hook.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	DrainSleepSeconds int32 `json:"drainSleepSeconds,omitempty"`
+
+	// DrainEndpoint calls the /drain endpoint of the router from the preStop
+	// hook before the drain sleep, so /health fails and load balancers stop
+	// opening new connections. Requires a router release serving /drain.
+	DrainEndpoint bool `json:"drainEndpoint,omitempty"`
+}
+
+// RouterBackendTimeouts defines the timeouts of the requests to the backends
+type RouterBackendTimeouts struct {
+	// ConnectSeconds bounds the connection to a backend, passed as
+	// --backend-connect-timeout. Accepted by router releases from 0.1.3.
+	// +kubebuilder:validation:Minimum=1
+	ConnectSeconds int32 `json:"connectSeconds,omitempty"`
+
+	// ReadSeconds bounds the wait for each chunk of a backend response,
+	// passed as --backend-read-timeout. Accepted by router releases from 0.1.3.
+	// +kubebuilder:validation:Minimum=1
+	ReadSeconds int32 `json:"readSeconds,omitempty"`
+}
+
+// RouterRetryPolicy defines the retries of failed backend requests
+// +kubebuilder:validation:XValidation:rule="!self.retryOn5xx || (has(self.maxRetries) && self.maxRetries > 0)",message="retryOn5xx requires maxRetries"
+type RouterRetryPolicy struct {
+	// MaxRetries is the number of retries of a request whose backend cannot
+	// be reached or times out, passed as --max-retries. Accepted by router
+	// releases from 0.1.3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+
+	// RetryOn5xx also retries requests answered with a 5xx status, passed as
+	// --retry-on-5xx. Accepted by router releases from 0.1.3.
+	RetryOn5xx bool `json:"retryOn5xx,omitempty"`
+}
+
+// RouterServiceSpec defines the router Service
+// +kubebuilder:validation:XValidation:rule="has(self.type) && self.type == 'LoadBalancer' || !has(self.loadBalancerIP) && !has(self.loadBalancerClass)",message="loadBalancerIP and loadBalancerClass require the LoadBalancer type"
+// +kubebuilder:validation:XValidation:rule="has(self.type) && self.type != 'ClusterIP' || !has(self.externalTrafficPolicy)",message="externalTrafficPolicy requires the NodePort or LoadBalancer type"
+type RouterServiceSpec struct {
+	// Type of the Service
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default=ClusterIP
+	Type string `json:"type,omitempty"`
+
+	// Port exposed by the Service, forwarded to the router port or to
+	// targetPortName
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	Port int32 `json:"port,omitempty"`
+
+	// TargetPortName forwards the Service port to a named port of a sidecar
+	// instead of the router, so that clients go through e.g. an auth proxy
+	// +kubebuilder:validation:MaxLength=15
+	TargetPortName string `json:"targetPortName,omitempty"`
+
+	// Annotations added to the Service, e.g. to configure a cloud load balancer
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels added to the Service. The app label is reserved for the operator.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// LoadBalancerIP requests a static address from the load balancer of a
+	// LoadBalancer Service, if the cloud provider supports it
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// LoadBalancerClass selects the load balancer implementation of a
+	// LoadBalancer Service. Kubernetes does not allow changing it.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="loadBalancerClass is immutable"
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+
+	// ExternalTrafficPolicy of a NodePort or LoadBalancer Service. Local
+	// preserves the client address, e.g. for ClientIP session affinity.
+	// Defaults to Cluster.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy string `json:"externalTrafficPolicy,omitempty"`
+
+	// SessionAffinity of the Service. Defaults to ClientIP with session
+	// routing, so that a client keeps reaching the same router replica, and
+	// to None otherwise.
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity string `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityTimeoutSeconds is how long a client sticks to a router
+	// replica with ClientIP affinity. Defaults to 10800 (3 hours).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	SessionAffinityTimeoutSeconds *int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+}
+
+// RouterProbes defines the probes of the router container
+type RouterProbes struct {
+	// Liveness restarts the router when /health fails. Defaults to an initial
+	// delay of 30s, a period of 5s and a failure threshold of 3.
+	Liveness ProbeSettings `json:"liveness,omitempty"`
+
+	// Readiness removes the router from the Service while /health fails, e.g.
+	// during startup. Defaults to an initial delay of 5s, a period of 5s and a
+	// failure threshold of 3.
+	Readiness ProbeSettings `json:"readiness,omitempty"`
+}
+
+// ProbeSettings defines the timing of a probe. Unset fields use the defaults
+// of the probe.
+type ProbeSettings struct {
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// RBACSpec defines the RBAC resources created for the router
+type RBACSpec struct {
+	// Create makes the controller create and own a ServiceAccount, and a Role
+	// and RoleBinding allowing the router to discover pods, services and
+	// endpointslices. Defaults to true when serviceDiscovery is k8s and no
+	// serviceAccountName is set.
+	// +optional
+	Create *bool `json:"create,omitempty"`
+
+	// ClusterWide creates a ClusterRole and ClusterRoleBinding instead of the
+	// Role and RoleBinding, letting the router discover the pods of
+	// watchNamespaces. They are named <namespace>-<name> after the router and
+	// deleted with it.
+	ClusterWide bool `json:"clusterWide,omitempty"`
 }
 
 // VLLMRouterStatus defines the observed state of VLLMRouter
@@ -99,15 +434,171 @@ type VLLMRouterStatus struct {
 	// Router status
 	Status string `json:"status,omitempty"`
 
+	// ObservedGeneration is the generation of the spec the status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ServiceEndpoint is the in-cluster URL of the router Service
+	ServiceEndpoint string `json:"serviceEndpoint,omitempty"`
+
+	// ExternalEndpoint is the URL of a LoadBalancer Service once the load
+	// balancer has an address
+	ExternalEndpoint string `json:"externalEndpoint,omitempty"`
+
 	// Last updated timestamp
 	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
 
-	// Number of active runtimes
+	// ActiveRuntimes is the number of backends available to the router: the
+	// ready pods matching k8sLabelSelector, or the static backends
 	ActiveRuntimes int32 `json:"activeRuntimes,omitempty"`
+
+	// Runtimes lists the VLLMRuntimes selected by runtimeSelector and passed to the router
+	Runtimes []string `json:"runtimes,omitempty"`
+
+	// URL is the external URL of the router once its Ingress has a load
+	// balancer address
+	URL string `json:"url,omitempty"`
+
+	// Replicas is the number of router pods
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of ready router pods
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is the label selector of the router pods, used by the scale
+	// subresource
+	Selector string `json:"selector,omitempty"`
+
+	// DesiredReplicas is the number of replicas wanted by the
+	// HorizontalPodAutoscaler when autoscaling is enabled
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// Conditions represent the latest available observations of the VLLMRouter state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// RouterTLSSpec references the Secret holding the certificate served by the
+// router. Requires a router release providing --ssl-certfile and --ssl-keyfile.
+// The router is rolled when the certificate changes.
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.secretName)",message="secretName is required when tls is enabled"
+type RouterTLSSpec struct {
+	// Enabled serves HTTPS on the router port and names the Service port https
+	Enabled bool `json:"enabled,omitempty"`
+
+	// SecretName is the Secret in the router namespace holding the certificate and key
+	SecretName string `json:"secretName,omitempty"`
+
+	// CertKey is the key of the PEM certificate chain within the Secret
+	// +kubebuilder:default="tls.crt"
+	CertKey string `json:"certKey,omitempty"`
+
+	// KeyKey is the key of the PEM private key within the Secret
+	// +kubebuilder:default="tls.key"
+	KeyKey string `json:"keyKey,omitempty"`
+}
+
+// RouterDynamicConfigSpec references the ConfigMap holding the dynamic config
+// JSON of the router. The router watches the mounted file and reconfigures
+// itself when the ConfigMap changes, without a rollout.
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.configMapName)",message="configMapName is required when dynamicConfig is enabled"
+type RouterDynamicConfigSpec struct {
+	// Enabled passes the dynamic config to the router with --dynamic-config-json
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ConfigMapName is the ConfigMap in the router namespace holding the dynamic config
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Key of the dynamic config JSON within the ConfigMap
+	// +kubebuilder:default="dynamic_config.json"
+	Key string `json:"key,omitempty"`
+}
+
+// RouterFeatureGates enable optional router APIs
+type RouterFeatureGates struct {
+	// EnableBatchAPI serves the files and batch APIs, passed as
+	// --enable-batch-api. Uploaded files are kept in fileStorage.
+	EnableBatchAPI bool `json:"enableBatchAPI,omitempty"`
+
+	// FileStorePath is where fileStorage is mounted and the router stores
+	// files, passed as --file-storage-path
+	// +kubebuilder:default="/var/lib/vllm-router/files"
+	// +kubebuilder:validation:Pattern=`^/`
+	FileStorePath string `json:"fileStorePath,omitempty"`
+}
+
+// RouterFileStorageSpec defines the PersistentVolumeClaim mounted for the
+// batch API, either an existing one or one created by the operator
+// +kubebuilder:validation:XValidation:rule="!has(self.pvcName) || !has(self.size) && !has(self.storageClassName)",message="size and storageClassName only apply to the PersistentVolumeClaim created by the operator, not to pvcName"
+type RouterFileStorageSpec struct {
+	// PVCName is an existing PersistentVolumeClaim in the router namespace.
+	// Not set, the operator creates a ReadWriteOnce claim named <name>-files;
+	// routers with several replicas across nodes need a ReadWriteMany claim.
+	PVCName string `json:"pvcName,omitempty"`
+
+	// Size of the created PersistentVolumeClaim. Defaults to 10Gi. Changing
+	// it does not resize an existing claim.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the created PersistentVolumeClaim, the cluster
+	// default storage class when not set
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// RetainOnDisable keeps the created PersistentVolumeClaim, and the files
+	// in it, when enableBatchAPI is turned off. It is always deleted with the
+	// router.
+	// +kubebuilder:default=true
+	RetainOnDisable *bool `json:"retainOnDisable,omitempty"`
 }
 
+// ConditionDynamicConfigResolved reports whether dynamicConfig resolved to an
+// existing ConfigMap key
+const ConditionDynamicConfigResolved = "DynamicConfigResolved"
+
+// ConditionAutoscalingActive reports whether the HorizontalPodAutoscaler of
+// the router, or the one created by KEDA for its ScaledObject, is able to
+// compute replica counts, as reported by its ScalingActive condition
+const ConditionAutoscalingActive = "AutoscalingActive"
+
+// ConditionDisabled reports that enableRouter is false and the router
+// Deployment and Service are removed
+const ConditionDisabled = "Disabled"
+
+// ConditionRouterArgsOmitted reports the flags left out of the router args
+// because routerVersion does not accept them
+const ConditionRouterArgsOmitted = "RouterArgsOmitted"
+
+// ConditionKEDAUnavailable reports that autoscaling.scaledObject is enabled
+// but the KEDA CRDs are not installed in the cluster
+const ConditionKEDAUnavailable = "KEDAUnavailable"
+
+const (
+	// ConditionAvailable reports whether the router Deployment has the
+	// minimum number of replicas available
+	ConditionAvailable = "Available"
+
+	// ConditionProgressing reports whether a rollout of the router
+	// Deployment is in progress
+	ConditionProgressing = "Progressing"
+
+	// ConditionBackendsDiscovered reports whether the router has at least one
+	// backend, i.e. activeRuntimes is positive
+	ConditionBackendsDiscovered = "BackendsDiscovered"
+)
+
+// ConditionHTTPRouteAccepted reports whether the Gateways of the router
+// HTTPRoute accepted it and resolved its backend
+const ConditionHTTPRouteAccepted = "HTTPRouteAccepted"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.readyReplicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+// +kubebuilder:printcolumn:name="Backends",type="integer",JSONPath=".status.activeRuntimes"
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".status.serviceEndpoint",priority=1
+// +kubebuilder:printcolumn:name="External",type="string",JSONPath=".status.externalEndpoint",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // VLLMRouter is the Schema for the vllmrouters API
 type VLLMRouter struct {
@@ -25,6 +25,7 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // VLLMRuntimeSpec defines the desired state of VLLMRuntime
+// +kubebuilder:validation:XValidation:rule="!has(self.loraModules) || (has(self.model.enableLoRA) && self.model.enableLoRA)",message="loraModules requires model.enableLoRA"
 type VLLMRuntimeSpec struct {
 	// Model configuration
 	Model ModelSpec `json:"model"`
@@ -44,9 +45,27 @@ type VLLMRuntimeSpec struct {
 	// Maximum number of LoRAs
 	MaxLoras int32 `json:"maxLoras,omitempty"`
 
+	// LoRA adapters loaded at startup via --lora-modules
+	// +listType=map
+	// +listMapKey=name
+	LoRAModules []LoRAModule `json:"loraModules,omitempty"`
+
 	// LM Cache configuration
 	LMCacheConfig LMCacheConfig `json:"lmCacheConfig,omitempty"`
 
+	// Speculative decoding configuration
+	SpeculativeDecoding *SpeculativeDecodingSpec `json:"speculativeDecoding,omitempty"`
+
+	// Command overrides the container entrypoint, e.g. for wrapper scripts
+	Command []string `json:"command,omitempty"`
+
+	// CLIStyle selects how the model is passed to vLLM: apiServerModule runs
+	// the OpenAI API server module with --model, vllmServe passes the model
+	// positionally to vllm serve
+	// +kubebuilder:validation:Enum=apiServerModule;vllmServe
+	// +kubebuilder:default=apiServerModule
+	CLIStyle string `json:"cliStyle,omitempty"`
+
 	// Extra arguments for vllm serve
 	ExtraArgs []string `json:"extraArgs,omitempty"`
 
@@ -57,9 +76,32 @@ type VLLMRuntimeSpec struct {
 	// +kubebuilder:default=8000
 	Port int32 `json:"port,omitempty"`
 
+	// MetricsPort is the Service port named metrics, for ServiceMonitor
+	// selectors. vLLM serves metrics on its HTTP port, which is the target port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:XValidation:rule="self != 80",message="metricsPort must differ from the Service port 80"
+	// +kubebuilder:default=9090
+	MetricsPort int32 `json:"metricsPort,omitempty"`
+
+	// Volumes added to the pods, e.g. CA bundles or scratch space for LMCache
+	// disk offloading. The names model, hf-cache and chat-template are reserved
+	// for volumes created by the operator.
+	// +kubebuilder:validation:MaxItems=32
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts added to the vllm container. Each mount must reference a
+	// volume in volumes or a volume created by the operator.
+	// +kubebuilder:validation:MaxItems=32
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
 	// Environment variables
 	Env []EnvVar `json:"env,omitempty"`
 
+	// Sources to populate environment variables from ConfigMaps and Secrets.
+	// Variables set in env take precedence over envFrom.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
 	// Resource requirements
 	Resources ResourceRequirements `json:"resources"`
 
@@ -72,20 +114,119 @@ type VLLMRuntimeSpec struct {
 	// +kubebuilder:validation:RequiredWhen=HFTokenSecret.Name!=""
 	HFTokenName string `json:"hfTokenName,omitempty"`
 
+	// APIKeySecret holds the API key required by the OpenAI endpoints. It is
+	// passed to vLLM as VLLM_API_KEY; /health stays unauthenticated. VLLMRouters
+	// routing to this runtime must set vllmApiKeySecret and vllmApiKeyName to
+	// the same Secret and key, which are published in status.apiKeySecret.
+	APIKeySecret *SecretKeyReference `json:"apiKeySecret,omitempty"`
+
+	// RestartOnSecretChange rolls the pods when the HF token in hfTokenSecret changes
+	RestartOnSecretChange bool `json:"restartOnSecretChange,omitempty"`
+
 	// Replicas
 	// +kubebuilder:default=1
 	Replicas int32 `json:"replicas,omitempty"`
 
-	// Deploy strategy
-	// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+	// Deploy strategy. Auto selects Recreate for a single replica requesting
+	// GPUs, whose surge pod usually cannot be scheduled during a rolling update,
+	// and RollingUpdate otherwise.
+	// +kubebuilder:validation:Enum=RollingUpdate;Recreate;Auto
 	// +kubebuilder:default=RollingUpdate
 	DeployStrategy string `json:"deploymentStrategy,omitempty"`
+
+	// GPU device selection and communication tuning
+	GPU *GPUSpec `json:"gpu,omitempty"`
+
+	// EnableSleepMode allows putting the engines to sleep with the
+	// production-stack.vllm.ai/sleep annotation (--enable-sleep-mode). It also
+	// sets VLLM_SERVER_DEV_MODE=1, which exposes the sleep endpoints. Sleeping
+	// engines keep answering the /health probes, so their pods are not restarted.
+	EnableSleepMode bool `json:"enableSleepMode,omitempty"`
+
+	// SchedulerName is the scheduler dispatching the pods, e.g. volcano for gang scheduling
+	// +kubebuilder:validation:XValidation:rule="self.trim() != ''",message="schedulerName must not be empty"
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// PodAnnotations are added to the pods, e.g. for scheduler queue assignment.
+	// Annotations managed by the operator take precedence.
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// HeadlessService creates a headless Service <name>-pods giving each pod a
+	// stable DNS name <pod>.<name>-pods.<namespace>.svc
+	HeadlessService bool `json:"headlessService,omitempty"`
+
+	// Ingress exposes the runtime Service outside the cluster
+	Ingress IngressSpec `json:"ingress,omitempty"`
+
+	// HTTPRoute exposes the runtime Service through a Gateway API Gateway
+	HTTPRoute HTTPRouteSpec `json:"httpRoute,omitempty"`
+
+	// Termination configures how pods drain in-flight requests on shutdown
+	// +kubebuilder:default={}
+	Termination TerminationSpec `json:"termination,omitempty"`
+}
+
+// GPUSpec defines GPU device selection and NCCL tuning
+type GPUSpec struct {
+	// VisibleDevices sets CUDA_VISIBLE_DEVICES as comma-separated device
+	// indices or GPU/MIG UUIDs. The number of devices should match resources.gpu.
+	// +kubebuilder:validation:Pattern=`^([0-9]+|(GPU|MIG)-[0-9a-fA-F-]+)(,([0-9]+|(GPU|MIG)-[0-9a-fA-F-]+))*$`
+	VisibleDevices string `json:"visibleDevices,omitempty"`
+
+	// NCCLEnv sets NCCL environment variables, e.g. NCCL_SOCKET_IFNAME.
+	// Variables set in env take precedence.
+	// +kubebuilder:validation:MaxProperties=64
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.startsWith('NCCL_'))",message="ncclEnv keys must start with NCCL_"
+	NCCLEnv map[string]string `json:"ncclEnv,omitempty"`
+}
+
+// TerminationSpec defines the pod shutdown behavior
+// +kubebuilder:validation:XValidation:rule="!has(self.drainSleepSeconds) || self.drainSleepSeconds == 0 || !has(self.gracePeriodSeconds) || self.drainSleepSeconds < self.gracePeriodSeconds",message="drainSleepSeconds must be less than gracePeriodSeconds"
+type TerminationSpec struct {
+	// GracePeriodSeconds is the pod termination grace period. It should cover
+	// the longest expected generation.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=120
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// DrainSleepSeconds delays shutdown with a preStop hook so the router stops
+	// sending traffic to the pod before vLLM receives SIGTERM. 0 disables the hook.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	DrainSleepSeconds int32 `json:"drainSleepSeconds,omitempty"`
 }
 
 // ModelSpec defines the model configuration
+// +kubebuilder:validation:XValidation:rule="has(self.source) && self.source != 'huggingface' ? true : has(self.modelURL) && !has(self.pvcName) && !has(self.path)",message="source huggingface requires modelURL and does not allow pvcName or path"
+// +kubebuilder:validation:XValidation:rule="has(self.source) && self.source == 'pvc' ? has(self.pvcName) && !has(self.modelURL) : true",message="source pvc requires pvcName and does not allow modelURL"
+// +kubebuilder:validation:XValidation:rule="has(self.source) && self.source == 'hostPath' ? has(self.path) && !has(self.modelURL) && !has(self.pvcName) : true",message="source hostPath requires path and does not allow modelURL or pvcName"
 type ModelSpec struct {
-	// Model URL
-	ModelURL string `json:"modelURL"`
+	// Model URL, used when source is huggingface
+	ModelURL string `json:"modelURL,omitempty"`
+
+	// Source of the model weights. Models from a pvc or hostPath are mounted
+	// read-only and served from the local path with HF_HUB_OFFLINE=1.
+	// +kubebuilder:validation:Enum=huggingface;pvc;hostPath
+	// +kubebuilder:default=huggingface
+	Source string `json:"source,omitempty"`
+
+	// PVCName is the PersistentVolumeClaim holding the model weights when source is pvc
+	PVCName string `json:"pvcName,omitempty"`
+
+	// Path to the model weights, relative to the volume root for pvc or
+	// absolute on the node for hostPath
+	Path string `json:"path,omitempty"`
+
+	// HFEndpoint is the HuggingFace Hub endpoint, e.g. an internal mirror.
+	// Sets HF_ENDPOINT; the HF token secret is still sent to the mirror.
+	HFEndpoint string `json:"hfEndpoint,omitempty"`
+
+	// HFHubOffline prevents any access to the HuggingFace Hub by setting HF_HUB_OFFLINE=1
+	HFHubOffline bool `json:"hfHubOffline,omitempty"`
+
+	// HFCachePath sets HF_HOME and HUGGINGFACE_HUB_CACHE (<path>/hub).
+	// An emptyDir is mounted at the path unless it lies within another volume mount.
+	HFCachePath string `json:"hfCachePath,omitempty"`
 
 	// Enable LoRA
 	EnableLoRA bool `json:"enableLoRA,omitempty"`
@@ -102,11 +243,76 @@ type ModelSpec struct {
 	// Data type
 	DType string `json:"dtype,omitempty"`
 
+	// Quantization method used for the model weights
+	// +kubebuilder:validation:Enum=awq;awq_marlin;gptq;gptq_marlin;fp8;fbgemm_fp8;bitsandbytes;gguf;compressed-tensors;experts_int8;modelopt;marlin
+	Quantization string `json:"quantization,omitempty"`
+
+	// Format of the model weights to load
+	// +kubebuilder:validation:Enum=auto;pt;safetensors;npcache;dummy;tensorizer;sharded_state;gguf;bitsandbytes;mistral;runai_streamer
+	LoadFormat string `json:"loadFormat,omitempty"`
+
+	// Tokenizer name or path, defaults to the model
+	Tokenizer string `json:"tokenizer,omitempty"`
+
+	// Tokenizer mode
+	// +kubebuilder:validation:Enum=auto;slow;mistral;custom
+	TokenizerMode string `json:"tokenizerMode,omitempty"`
+
+	// TrustRemoteCode allows executing code shipped with the model or tokenizer
+	// (--trust-remote-code). It can be forbidden operator-wide.
+	TrustRemoteCode bool `json:"trustRemoteCode,omitempty"`
+
+	// ChatTemplateConfigMap references a ConfigMap key holding a Jinja chat template.
+	// The ConfigMap is mounted and passed to vLLM with --chat-template.
+	ChatTemplateConfigMap *ConfigMapKeyReference `json:"chatTemplateConfigMap,omitempty"`
+
 	// Maximum number of sequences
 	MaxNumSeqs int32 `json:"maxNumSeqs,omitempty"`
 }
 
+// LoRAModule defines a LoRA adapter loaded at startup
+type LoRAModule struct {
+	// Name the adapter is served as
+	Name string `json:"name"`
+
+	// Path or HuggingFace repository of the adapter
+	Path string `json:"path"`
+}
+
+// SpeculativeDecodingSpec defines the speculative decoding configuration
+// +kubebuilder:validation:XValidation:rule="self.method == 'ngram' ? !has(self.draftModelURL) : has(self.draftModelURL)",message="draftModelURL is required for all methods except ngram"
+// +kubebuilder:validation:XValidation:rule="self.method == 'ngram' || (!has(self.promptLookupMax) && !has(self.promptLookupMin))",message="promptLookupMax and promptLookupMin are only valid for ngram"
+type SpeculativeDecodingSpec struct {
+	// Method is the speculative decoding method
+	// +kubebuilder:validation:Enum=ngram;eagle;eagle3;medusa;mlp_speculator;draft_model
+	Method string `json:"method"`
+
+	// DraftModelURL is the draft or speculator model
+	DraftModelURL string `json:"draftModelURL,omitempty"`
+
+	// NumSpeculativeTokens is the number of tokens proposed per step
+	// +kubebuilder:validation:Minimum=1
+	NumSpeculativeTokens int32 `json:"numSpeculativeTokens"`
+
+	// DraftTensorParallelSize is the tensor parallel size of the draft model
+	DraftTensorParallelSize int32 `json:"draftTensorParallelSize,omitempty"`
+
+	// PromptLookupMax is the maximum n-gram window for ngram
+	PromptLookupMax int32 `json:"promptLookupMax,omitempty"`
+
+	// PromptLookupMin is the minimum n-gram window for ngram
+	PromptLookupMin int32 `json:"promptLookupMin,omitempty"`
+
+	// ArgStyle selects how the configuration is passed to vLLM: a single
+	// --speculative-config JSON argument, or the discrete flags used by older releases
+	// +kubebuilder:validation:Enum=json;flags
+	// +kubebuilder:default=json
+	ArgStyle string `json:"argStyle,omitempty"`
+}
+
 // LMCacheConfig defines the LM Cache configuration
+// +kubebuilder:validation:XValidation:rule="!(has(self.remoteUrl) && has(self.cacheServerRef))",message="remoteUrl and cacheServerRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.autoDiscover) || !self.autoDiscover || !has(self.remoteUrl) && !has(self.cacheServerRef)",message="autoDiscover is mutually exclusive with remoteUrl and cacheServerRef"
 type LMCacheConfig struct {
 	// Enabled enables LM Cache
 	// +kubebuilder:default=false
@@ -123,14 +329,54 @@ type LMCacheConfig struct {
 	// RemoteURL is the URL of the remote cache server
 	RemoteURL string `json:"remoteUrl,omitempty"`
 
+	// CacheServerRef references a CacheServer managed by this operator.
+	// The remote URL is resolved from the CacheServer's Service.
+	CacheServerRef *CacheServerReference `json:"cacheServerRef,omitempty"`
+
+	// AutoDiscover uses the CacheServer of the VLLMRuntime namespace as with
+	// cacheServerRef. The runtime waits, reporting the CacheServerResolved
+	// condition, while the namespace has no CacheServer or more than one.
+	AutoDiscover bool `json:"autoDiscover,omitempty"`
+
 	// RemoteSerde is the serialization format for the remote cache
 	RemoteSerde string `json:"remoteSerde,omitempty"`
 }
 
+// ConfigMapKeyReference selects a key of a ConfigMap in the same namespace
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key within the ConfigMap
+	Key string `json:"key"`
+}
+
+// SecretKeyReference selects a key of a Secret in the same namespace
+type SecretKeyReference struct {
+	// Name of the Secret
+	Name string `json:"name"`
+
+	// Key within the Secret
+	Key string `json:"key"`
+}
+
+// CacheServerReference identifies a CacheServer
+type CacheServerReference struct {
+	// Name of the CacheServer
+	Name string `json:"name"`
+
+	// Namespace of the CacheServer, defaults to the namespace of the VLLMRuntime
+	Namespace string `json:"namespace,omitempty"`
+}
+
 // EnvVar represents an environment variable
+// +kubebuilder:validation:XValidation:rule="!(has(self.value) && has(self.valueFrom))",message="value and valueFrom are mutually exclusive"
 type EnvVar struct {
 	Name  string `json:"name"`
-	Value string `json:"value"`
+	Value string `json:"value,omitempty"`
+
+	// ValueFrom sources the value from a field, resource, ConfigMap or Secret
+	ValueFrom *corev1.EnvVarSource `json:"valueFrom,omitempty"`
 }
 
 // VLLMRuntimeStatus defines the observed state of VLLMRuntime
@@ -140,11 +386,109 @@ type VLLMRuntimeStatus struct {
 
 	// Last updated timestamp
 	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+
+	// SleepState is Sleeping when the engines released their GPU memory and
+	// Awake once they were woken up
+	SleepState string `json:"sleepState,omitempty"`
+
+	// ModelName is the model served by vLLM
+	ModelName string `json:"modelName,omitempty"`
+
+	// ServiceEndpoint is the in-cluster URL of the runtime Service
+	ServiceEndpoint string `json:"serviceEndpoint,omitempty"`
+
+	// APIKeySecret is the Secret holding the API key of the runtime, to be used
+	// by clients such as the VLLMRouter
+	APIKeySecret *SecretKeyReference `json:"apiKeySecret,omitempty"`
+
+	// HeadlessServiceName is the name of the headless Service when enabled
+	HeadlessServiceName string `json:"headlessServiceName,omitempty"`
+
+	// URL is the external URL of the runtime when an Ingress is enabled
+	URL string `json:"url,omitempty"`
+
+	// LoRAModules lists the adapters loaded at startup, as opposed to adapters loaded dynamically
+	LoRAModules []string `json:"loraModules,omitempty"`
+
+	// Conditions represent the latest available observations of the VLLMRuntime state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// ConditionCacheServerResolved reports whether lmCacheConfig.cacheServerRef
+	// resolved to a ready CacheServer
+	ConditionCacheServerResolved = "CacheServerResolved"
+
+	// ConditionRemoteSerdeMismatch reports that lmCacheConfig.remoteSerde
+	// differs from the serde of the resolved CacheServer
+	ConditionRemoteSerdeMismatch = "RemoteSerdeMismatch"
+
+	// ConditionChatTemplateResolved reports whether model.chatTemplateConfigMap
+	// resolved to an existing ConfigMap key
+	ConditionChatTemplateResolved = "ChatTemplateResolved"
+
+	// ConditionSpecForbidden reports that the spec uses a feature forbidden by the operator
+	ConditionSpecForbidden = "SpecForbidden"
+
+	// ConditionGatewayAPIUnavailable reports that httpRoute is enabled but the
+	// Gateway API CRDs are not installed in the cluster
+	ConditionGatewayAPIUnavailable = "GatewayAPIUnavailable"
+
+	// ConditionSecretMissing reports that a referenced Secret, e.g.
+	// hfTokenSecret, or its key does not exist
+	ConditionSecretMissing = "SecretMissing"
+
+	// ConditionHFTokenOverridden reports that spec.env defines HF_TOKEN, which
+	// takes precedence over hfTokenSecret
+	ConditionHFTokenOverridden = "HFTokenOverridden"
+
+	// ConditionGPUDevicesConflict reports that gpu.visibleDevices does not match
+	// the number of GPUs requested in resources.gpu
+	ConditionGPUDevicesConflict = "GPUDevicesConflict"
+
+	// ConditionSpecInvalid reports a spec the API server cannot validate,
+	// e.g. volume mounts referencing undeclared volumes
+	ConditionSpecInvalid = "SpecInvalid"
+
+	// ConditionAsleep reports whether the engines are asleep as requested by the
+	// production-stack.vllm.ai/sleep annotation
+	ConditionAsleep = "Asleep"
+
+	// ConditionDegraded reports pods whose containers cannot start or keep
+	// crashing, e.g. CrashLoopBackOff or ImagePullBackOff
+	ConditionDegraded = "Degraded"
+)
+
+// SleepAnnotation puts the engines of a VLLMRuntime with enableSleepMode to
+// sleep when set to "true" and wakes them up when removed
+const SleepAnnotation = "production-stack.vllm.ai/sleep"
+
+// DeployStrategyAuto lets the operator select the Deployment strategy
+const DeployStrategyAuto = "Auto"
+
+const (
+	// SleepStateSleeping means all engines are asleep
+	SleepStateSleeping = "Sleeping"
+	// SleepStateAwake means all engines are awake
+	SleepStateAwake = "Awake"
+)
+
+// ModelLabel is the label carrying the served model on VLLMRuntime pods. The
+// model name is lower-cased, characters not allowed in label values are
+// replaced by '-', and names longer than 63 characters are truncated with a
+// hash suffix. The key is a stable contract: the router's Kubernetes service
+// discovery and the LoRA adapter controller select engine pods by it.
+const ModelLabel = "model"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=vr
+// +kubebuilder:printcolumn:name="Model",type="string",JSONPath=".spec.model.modelURL"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.modelStatus"
+// +kubebuilder:printcolumn:name="TrustRemoteCode",type="boolean",JSONPath=".spec.model.trustRemoteCode",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // VLLMRuntime is the Schema for the vllmruntimes API
 type VLLMRuntime struct {
@@ -21,173 +21,1082 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingSpec) DeepCopyInto(out *AutoscalingSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilization != nil {
+		in, out := &in.TargetCPUUtilization, &out.TargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ScaledObject.DeepCopyInto(&out.ScaledObject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingSpec.
+func (in *AutoscalingSpec) DeepCopy() *AutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CacheServer) DeepCopyInto(out *CacheServer) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServer.
+func (in *CacheServer) DeepCopy() *CacheServer {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CacheServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerAutoscalingSpec) DeepCopyInto(out *CacheServerAutoscalingSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilization != nil {
+		in, out := &in.TargetCPUUtilization, &out.TargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerAutoscalingSpec.
+func (in *CacheServerAutoscalingSpec) DeepCopy() *CacheServerAutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerAutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerConfigSpec) DeepCopyInto(out *CacheServerConfigSpec) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExistingConfigMap != nil {
+		in, out := &in.ExistingConfigMap, &out.ExistingConfigMap
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerConfigSpec.
+func (in *CacheServerConfigSpec) DeepCopy() *CacheServerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerExternalSpec) DeepCopyInto(out *CacheServerExternalSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerExternalSpec.
+func (in *CacheServerExternalSpec) DeepCopy() *CacheServerExternalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerExternalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerList) DeepCopyInto(out *CacheServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CacheServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerList.
+func (in *CacheServerList) DeepCopy() *CacheServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CacheServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerMonitoringSpec) DeepCopyInto(out *CacheServerMonitoringSpec) {
+	*out = *in
+	in.MonitoringSpec.DeepCopyInto(&out.MonitoringSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerMonitoringSpec.
+func (in *CacheServerMonitoringSpec) DeepCopy() *CacheServerMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerNetworkPolicySpec) DeepCopyInto(out *CacheServerNetworkPolicySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerNetworkPolicySpec.
+func (in *CacheServerNetworkPolicySpec) DeepCopy() *CacheServerNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerNetworkSpec) DeepCopyInto(out *CacheServerNetworkSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerNetworkSpec.
+func (in *CacheServerNetworkSpec) DeepCopy() *CacheServerNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerPreStopHTTPGet) DeepCopyInto(out *CacheServerPreStopHTTPGet) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerPreStopHTTPGet.
+func (in *CacheServerPreStopHTTPGet) DeepCopy() *CacheServerPreStopHTTPGet {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerPreStopHTTPGet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerReference) DeepCopyInto(out *CacheServerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerReference.
+func (in *CacheServerReference) DeepCopy() *CacheServerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerResources) DeepCopyInto(out *CacheServerResources) {
+	*out = *in
+	out.ResourceRequirements = in.ResourceRequirements
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerResources.
+func (in *CacheServerResources) DeepCopy() *CacheServerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerSpec) DeepCopyInto(out *CacheServerSpec) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(CacheServerExternalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Image = in.Image
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(CacheServerConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Autoscaling.DeepCopyInto(&out.Autoscaling)
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+	out.NetworkPolicy = in.NetworkPolicy
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Termination.DeepCopyInto(&out.Termination)
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Network = in.Network
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ColocateWithRuntime != nil {
+		in, out := &in.ColocateWithRuntime, &out.ColocateWithRuntime
+		*out = new(VLLMRuntimeReference)
+		**out = **in
+	}
+	in.Storage.DeepCopyInto(&out.Storage)
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerSpec.
+func (in *CacheServerSpec) DeepCopy() *CacheServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerStatus) DeepCopyInto(out *CacheServerStatus) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerStatus.
+func (in *CacheServerStatus) DeepCopy() *CacheServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerStorageSpec) DeepCopyInto(out *CacheServerStorageSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerStorageSpec.
+func (in *CacheServerStorageSpec) DeepCopy() *CacheServerStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheServerTerminationSpec) DeepCopyInto(out *CacheServerTerminationSpec) {
+	*out = *in
+	if in.PreStopCommand != nil {
+		in, out := &in.PreStopCommand, &out.PreStopCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreStopHTTPGet != nil {
+		in, out := &in.PreStopHTTPGet, &out.PreStopHTTPGet
+		*out = new(CacheServerPreStopHTTPGet)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerTerminationSpec.
+func (in *CacheServerTerminationSpec) DeepCopy() *CacheServerTerminationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheServerTerminationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvVar) DeepCopyInto(out *EnvVar) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(v1.EnvVarSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVar.
+func (in *EnvVar) DeepCopy() *EnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUSpec) DeepCopyInto(out *GPUSpec) {
+	*out = *in
+	if in.NCCLEnv != nil {
+		in, out := &in.NCCLEnv, &out.NCCLEnv
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUSpec.
+func (in *GPUSpec) DeepCopy() *GPUSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayParentReference) DeepCopyInto(out *GatewayParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParentReference.
+func (in *GatewayParentReference) DeepCopy() *GatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRouteSpec) DeepCopyInto(out *HTTPRouteSpec) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]GatewayParentReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteSpec.
+func (in *HTTPRouteSpec) DeepCopy() *HTTPRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
+func (in *ImageSpec) DeepCopy() *ImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(IngressTLS)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressSpec.
+func (in *IngressSpec) DeepCopy() *IngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressTLS) DeepCopyInto(out *IngressTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressTLS.
+func (in *IngressTLS) DeepCopy() *IngressTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LMCacheConfig) DeepCopyInto(out *LMCacheConfig) {
+	*out = *in
+	if in.CacheServerRef != nil {
+		in, out := &in.CacheServerRef, &out.CacheServerRef
+		*out = new(CacheServerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LMCacheConfig.
+func (in *LMCacheConfig) DeepCopy() *LMCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LMCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoRAModule) DeepCopyInto(out *LoRAModule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoRAModule.
+func (in *LoRAModule) DeepCopy() *LoRAModule {
+	if in == nil {
+		return nil
+	}
+	out := new(LoRAModule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
+	*out = *in
+	if in.ChatTemplateConfigMap != nil {
+		in, out := &in.ChatTemplateConfigMap, &out.ChatTemplateConfigMap
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
+func (in *ModelSpec) DeepCopy() *ModelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSettings) DeepCopyInto(out *ProbeSettings) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSettings.
+func (in *ProbeSettings) DeepCopy() *ProbeSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBACSpec) DeepCopyInto(out *RBACSpec) {
+	*out = *in
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBACSpec.
+func (in *RBACSpec) DeepCopy() *RBACSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RBACSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServer.
-func (in *CacheServer) DeepCopy() *CacheServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
+func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
 	if in == nil {
 		return nil
 	}
-	out := new(CacheServer)
+	out := new(ResourceRequirements)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CacheServer) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateSpec) DeepCopyInto(out *RollingUpdateSpec) {
+	*out = *in
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
 	}
-	return nil
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateSpec.
+func (in *RollingUpdateSpec) DeepCopy() *RollingUpdateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CacheServerList) DeepCopyInto(out *CacheServerList) {
+func (in *RouterBackendTimeouts) DeepCopyInto(out *RouterBackendTimeouts) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]CacheServer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterBackendTimeouts.
+func (in *RouterBackendTimeouts) DeepCopy() *RouterBackendTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterBackendTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterDynamicConfigSpec) DeepCopyInto(out *RouterDynamicConfigSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterDynamicConfigSpec.
+func (in *RouterDynamicConfigSpec) DeepCopy() *RouterDynamicConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterDynamicConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterFeatureGates) DeepCopyInto(out *RouterFeatureGates) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterFeatureGates.
+func (in *RouterFeatureGates) DeepCopy() *RouterFeatureGates {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterFeatureGates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterFileStorageSpec) DeepCopyInto(out *RouterFileStorageSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RetainOnDisable != nil {
+		in, out := &in.RetainOnDisable, &out.RetainOnDisable
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterFileStorageSpec.
+func (in *RouterFileStorageSpec) DeepCopy() *RouterFileStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterFileStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterProbes) DeepCopyInto(out *RouterProbes) {
+	*out = *in
+	in.Liveness.DeepCopyInto(&out.Liveness)
+	in.Readiness.DeepCopyInto(&out.Readiness)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterProbes.
+func (in *RouterProbes) DeepCopy() *RouterProbes {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterProbes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterRetryPolicy) DeepCopyInto(out *RouterRetryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterRetryPolicy.
+func (in *RouterRetryPolicy) DeepCopy() *RouterRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterServiceSpec) DeepCopyInto(out *RouterServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	if in.SessionAffinityTimeoutSeconds != nil {
+		in, out := &in.SessionAffinityTimeoutSeconds, &out.SessionAffinityTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerList.
-func (in *CacheServerList) DeepCopy() *CacheServerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterServiceSpec.
+func (in *RouterServiceSpec) DeepCopy() *RouterServiceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CacheServerList)
+	out := new(RouterServiceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CacheServerList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterTLSSpec) DeepCopyInto(out *RouterTLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterTLSSpec.
+func (in *RouterTLSSpec) DeepCopy() *RouterTLSSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(RouterTLSSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CacheServerSpec) DeepCopyInto(out *CacheServerSpec) {
+func (in *RouterTerminationSpec) DeepCopyInto(out *RouterTerminationSpec) {
 	*out = *in
-	out.Image = in.Image
-	out.Resources = in.Resources
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerSpec.
-func (in *CacheServerSpec) DeepCopy() *CacheServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterTerminationSpec.
+func (in *RouterTerminationSpec) DeepCopy() *RouterTerminationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CacheServerSpec)
+	out := new(RouterTerminationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CacheServerStatus) DeepCopyInto(out *CacheServerStatus) {
+func (in *ScaledObjectAuthenticationRef) DeepCopyInto(out *ScaledObjectAuthenticationRef) {
 	*out = *in
-	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheServerStatus.
-func (in *CacheServerStatus) DeepCopy() *CacheServerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectAuthenticationRef.
+func (in *ScaledObjectAuthenticationRef) DeepCopy() *ScaledObjectAuthenticationRef {
 	if in == nil {
 		return nil
 	}
-	out := new(CacheServerStatus)
+	out := new(ScaledObjectAuthenticationRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvVar) DeepCopyInto(out *EnvVar) {
+func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 	*out = *in
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]ScaledObjectTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVar.
-func (in *EnvVar) DeepCopy() *EnvVar {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectSpec.
+func (in *ScaledObjectSpec) DeepCopy() *ScaledObjectSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvVar)
+	out := new(ScaledObjectSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+func (in *ScaledObjectTrigger) DeepCopyInto(out *ScaledObjectTrigger) {
 	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AuthenticationRef != nil {
+		in, out := &in.AuthenticationRef, &out.AuthenticationRef
+		*out = new(ScaledObjectAuthenticationRef)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
-func (in *ImageSpec) DeepCopy() *ImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectTrigger.
+func (in *ScaledObjectTrigger) DeepCopy() *ScaledObjectTrigger {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSpec)
+	out := new(ScaledObjectTrigger)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LMCacheConfig) DeepCopyInto(out *LMCacheConfig) {
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LMCacheConfig.
-func (in *LMCacheConfig) DeepCopy() *LMCacheConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
 	if in == nil {
 		return nil
 	}
-	out := new(LMCacheConfig)
+	out := new(SecretKeyReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
+func (in *SpeculativeDecodingSpec) DeepCopyInto(out *SpeculativeDecodingSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
-func (in *ModelSpec) DeepCopy() *ModelSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpeculativeDecodingSpec.
+func (in *SpeculativeDecodingSpec) DeepCopy() *SpeculativeDecodingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelSpec)
+	out := new(SpeculativeDecodingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
+func (in *TerminationSpec) DeepCopyInto(out *TerminationSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
-func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerminationSpec.
+func (in *TerminationSpec) DeepCopy() *TerminationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceRequirements)
+	out := new(TerminationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -254,6 +1163,41 @@ func (in *VLLMRouterList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VLLMRouterSpec) DeepCopyInto(out *VLLMRouterSpec) {
 	*out = *in
+	if in.EnableRouter != nil {
+		in, out := &in.EnableRouter, &out.EnableRouter
+		*out = new(bool)
+		**out = **in
+	}
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+	in.Autoscaling.DeepCopyInto(&out.Autoscaling)
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Termination = in.Termination
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuntimeSelector != nil {
+		in, out := &in.RuntimeSelector, &out.RuntimeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrefillModelLabels != nil {
+		in, out := &in.PrefillModelLabels, &out.PrefillModelLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DecodeModelLabels != nil {
+		in, out := &in.DecodeModelLabels, &out.DecodeModelLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.BackendTimeouts = in.BackendTimeouts
+	out.RetryPolicy = in.RetryPolicy
 	if in.ExtraArgs != nil {
 		in, out := &in.ExtraArgs, &out.ExtraArgs
 		*out = make([]string, len(*in))
@@ -266,12 +1210,69 @@ func (in *VLLMRouterSpec) DeepCopyInto(out *VLLMRouterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.RBAC.DeepCopyInto(&out.RBAC)
+	in.Service.DeepCopyInto(&out.Service)
+	out.TLS = in.TLS
+	out.FeatureGates = in.FeatureGates
+	in.FileStorage.DeepCopyInto(&out.FileStorage)
+	out.DynamicConfig = in.DynamicConfig
+	in.Ingress.DeepCopyInto(&out.Ingress)
+	in.HTTPRoute.DeepCopyInto(&out.HTTPRoute)
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
+	in.Probes.DeepCopyInto(&out.Probes)
 	out.Image = in.Image
 	out.Resources = in.Resources
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
 	}
 	out.VLLMApiKeySecret = in.VLLMApiKeySecret
 }
@@ -290,6 +1291,18 @@ func (in *VLLMRouterSpec) DeepCopy() *VLLMRouterSpec {
 func (in *VLLMRouterStatus) DeepCopyInto(out *VLLMRouterStatus) {
 	*out = *in
 	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.Runtimes != nil {
+		in, out := &in.Runtimes, &out.Runtimes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLLMRouterStatus.
@@ -361,24 +1374,97 @@ func (in *VLLMRuntimeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLLMRuntimeReference) DeepCopyInto(out *VLLMRuntimeReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLLMRuntimeReference.
+func (in *VLLMRuntimeReference) DeepCopy() *VLLMRuntimeReference {
+	if in == nil {
+		return nil
+	}
+	out := new(VLLMRuntimeReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VLLMRuntimeSpec) DeepCopyInto(out *VLLMRuntimeSpec) {
 	*out = *in
-	out.Model = in.Model
-	out.LMCacheConfig = in.LMCacheConfig
+	in.Model.DeepCopyInto(&out.Model)
+	if in.LoRAModules != nil {
+		in, out := &in.LoRAModules, &out.LoRAModules
+		*out = make([]LoRAModule, len(*in))
+		copy(*out, *in)
+	}
+	in.LMCacheConfig.DeepCopyInto(&out.LMCacheConfig)
+	if in.SpeculativeDecoding != nil {
+		in, out := &in.SpeculativeDecoding, &out.SpeculativeDecoding
+		*out = new(SpeculativeDecodingSpec)
+		**out = **in
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExtraArgs != nil {
 		in, out := &in.ExtraArgs, &out.ExtraArgs
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	out.Resources = in.Resources
 	out.Image = in.Image
 	out.HFTokenSecret = in.HFTokenSecret
+	if in.APIKeySecret != nil {
+		in, out := &in.APIKeySecret, &out.APIKeySecret
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Ingress.DeepCopyInto(&out.Ingress)
+	in.HTTPRoute.DeepCopyInto(&out.HTTPRoute)
+	out.Termination = in.Termination
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLLMRuntimeSpec.
@@ -395,6 +1481,23 @@ func (in *VLLMRuntimeSpec) DeepCopy() *VLLMRuntimeSpec {
 func (in *VLLMRuntimeStatus) DeepCopyInto(out *VLLMRuntimeStatus) {
 	*out = *in
 	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.APIKeySecret != nil {
+		in, out := &in.APIKeySecret, &out.APIKeySecret
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.LoRAModules != nil {
+		in, out := &in.LoRAModules, &out.LoRAModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLLMRuntimeStatus.
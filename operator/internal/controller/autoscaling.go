@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// defaultTargetCPUUtilization is the CPU target of a HorizontalPodAutoscaler
+// without metrics
+const defaultTargetCPUUtilization = 80
+
+// horizontalPodAutoscalerFor returns a HorizontalPodAutoscaler named name
+// scaling the apps/v1 workload of the given kind and the same name
+func horizontalPodAutoscalerFor(owner metav1.Object, spec productionstackv1alpha1.AutoscalingSpec, kind, name string, labels map[string]string, scheme *runtime.Scheme) *autoscalingv2.HorizontalPodAutoscaler {
+	// Defaulted fields are set so that the comparison with the live object is stable
+	minReplicas := int32(1)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+
+	metrics := append([]autoscalingv2.MetricSpec{}, spec.Metrics...)
+	if spec.TargetCPUUtilization != nil || len(metrics) == 0 {
+		targetCPUUtilization := int32(defaultTargetCPUUtilization)
+		if spec.TargetCPUUtilization != nil {
+			targetCPUUtilization = *spec.TargetCPUUtilization
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &targetCPUUtilization,
+				},
+			},
+		})
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       kind,
+				Name:       name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, hpa, scheme)
+	return hpa
+}
+
+// reconcileHorizontalPodAutoscaler creates, updates or deletes the
+// HorizontalPodAutoscaler owned by owner so that it matches spec and scales
+// the workload of the given kind. It returns the live
+// HorizontalPodAutoscaler, or nil when autoscaling is disabled.
+func reconcileHorizontalPodAutoscaler(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.AutoscalingSpec, kind, name string, labels map[string]string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	log := log.FromContext(ctx)
+
+	found := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	if !spec.Enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting HorizontalPodAutoscaler", "HorizontalPodAutoscaler.Namespace", found.Namespace, "HorizontalPodAutoscaler.Name", found.Name)
+			return nil, client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil, nil
+	}
+
+	expected := horizontalPodAutoscalerFor(owner, spec, kind, name, labels, scheme)
+	if !exists {
+		log.Info("Creating a new HorizontalPodAutoscaler", "HorizontalPodAutoscaler.Namespace", expected.Namespace, "HorizontalPodAutoscaler.Name", expected.Name)
+		return expected, c.Create(ctx, expected)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, expected.Spec) {
+		log.Info("Updating HorizontalPodAutoscaler", "HorizontalPodAutoscaler.Namespace", found.Namespace, "HorizontalPodAutoscaler.Name", found.Name)
+		found.Spec = expected.Spec
+		return found, c.Update(ctx, found)
+	}
+
+	return found, nil
+}
+
+// autoscalingActiveCondition derives the AutoscalingActive condition from the
+// ScalingActive condition of a HorizontalPodAutoscaler
+func autoscalingActiveCondition(hpa *autoscalingv2.HorizontalPodAutoscaler) metav1.Condition {
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type != autoscalingv2.ScalingActive {
+			continue
+		}
+		return metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionAutoscalingActive,
+			Status:  metav1.ConditionStatus(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		}
+	}
+	return metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionAutoscalingActive,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "Pending",
+		Message: "The HorizontalPodAutoscaler has not computed replica counts yet",
+	}
+}
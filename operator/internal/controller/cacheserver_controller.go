@@ -19,35 +19,97 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"path"
 	"reflect"
+	"sort"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 
 	productionstackv1alpha1 "production-stack/api/v1alpha1"
 )
 
+// cacheServerFieldOwner is the server-side apply field manager of the
+// CacheServer controller
+const cacheServerFieldOwner = client.FieldOwner("cacheserver-controller")
+
+// cacheServerConfigHashAnnotation records the hash of the config file on the
+// pod template so that editing it rolls the cache server
+const cacheServerConfigHashAnnotation = annotationPrefix + "config-hash"
+
+// cacheServerConfigKey is the key of the config file in the ConfigMap
+// rendered from an inline or raw config
+const cacheServerConfigKey = "lmcache.yaml"
+
+// cacheServerConfigMountPath is where the config ConfigMap is mounted
+const cacheServerConfigMountPath = "/etc/lmcache"
+
+// lmCacheRemotePasswordEnv is the environment variable holding the password
+// of an external backend, referenced by the remote URL of its clients
+const lmCacheRemotePasswordEnv = "LMCACHE_REMOTE_PASSWORD"
+
+// externalBackendDefaultPorts are the ports of the external backend URL
+// schemes, used when the URL has none
+var externalBackendDefaultPorts = map[string]string{"redis": "6379", "rediss": "6379", "redis-sentinel": "26379"}
+
+// defaultCacheServerStorageSize is the size of the PersistentVolumeClaim
+// created for the cache when storage.size is not set
+var defaultCacheServerStorageSize = resource.MustParse("10Gi")
+
+// defaultCacheServerStorageMountPath is where the PersistentVolumeClaim is
+// mounted when storage.mountPath is not set
+const defaultCacheServerStorageMountPath = "/var/lib/lmcache"
+
+// cacheServerInputs are the resolved external inputs of the CacheServer
+// workload
+type cacheServerInputs struct {
+	// annotations are added to the pod template, e.g. the config hash
+	annotations map[string]string
+}
+
 // CacheServerReconciler reconciles a CacheServer object
 type CacheServerReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=cacheservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=cacheservers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=cacheservers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -69,23 +131,147 @@ func (r *CacheServerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Check if the service already exists, if not create a new one
-	foundService := &corev1.Service{}
-	err = r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, foundService)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new service
+	// An external backend runs no pods, only its endpoint is published
+	if cacheServerExternal(cacheServer) {
+		if err := r.reconcileExternal(ctx, cacheServer); err != nil {
+			log.Error(err, "Failed to reconcile the external backend of the CacheServer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Without the webhook unparsable quantities reach the controller. The
+	// workload is left unchanged until they are fixed.
+	invalidCondition := cacheServerResourcesCondition(cacheServer)
+	r.recordConditionWarning(cacheServer, invalidCondition)
+	if err := r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionSpecInvalid, invalidCondition); err != nil {
+		log.Error(err, "Failed to update CacheServer status")
+		return ctrl.Result{}, err
+	}
+	if invalidCondition != nil {
+		log.Info("CacheServer spec is invalid", "Message", invalidCondition.Message)
+		return ctrl.Result{}, nil
+	}
+
+	// The operator values of the variables it sets win
+	if names := overriddenEnvNames(cacheServer); len(names) > 0 {
+		r.Recorder.Eventf(cacheServer, corev1.EventTypeWarning, "EnvOverridden",
+			"spec.env defines %s, which the operator sets, ignoring them", strings.Join(names, ", "))
+	}
+
+	// Apply the service. Server-side apply converges the ports, selector and
+	// labels in place and keeps the clusterIP. On the host network the
+	// clients reach the pods through the headless service instead.
+	if cacheServer.Spec.Network.HostNetwork {
+		if err := r.deleteService(ctx, cacheServer, cacheServer.Name); err != nil {
+			log.Error(err, "Failed to delete Service")
+			return ctrl.Result{}, err
+		}
+	} else {
 		svc := r.serviceForCacheServer(cacheServer)
-		log.Info("Creating a new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
-		err = r.Create(ctx, svc)
-		if err != nil {
-			log.Error(err, "Failed to create new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, cacheServer, svc, cacheServerFieldOwner); err != nil {
+			log.Error(err, "Failed to apply Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
 			return ctrl.Result{}, err
 		}
-		// Service created successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Service")
+	}
+
+	// Admit only the runtimes using the cache server
+	if cacheServer.Spec.NetworkPolicy.Enabled {
+		policy := r.networkPolicyForCacheServer(cacheServer)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, cacheServer, policy, cacheServerFieldOwner); err != nil {
+			log.Error(err, "Failed to apply NetworkPolicy", "NetworkPolicy.Namespace", policy.Namespace, "NetworkPolicy.Name", policy.Name)
+			return ctrl.Result{}, err
+		}
+	} else if err := r.deleteNetworkPolicy(ctx, cacheServer); err != nil {
+		log.Error(err, "Failed to delete NetworkPolicy")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the ServiceMonitor scraping the cache server metrics
+	monitoringCondition, err := reconcileServiceMonitor(ctx, r.Client, r.Scheme, cacheServer, cacheServer.Spec.Monitoring.MonitoringSpec, cacheServer.Name, map[string]string{"app": cacheServer.Name}, "metrics")
+	if err != nil {
+		log.Error(err, "Failed to reconcile ServiceMonitor")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionMonitoringCRDsMissing, monitoringCondition); err != nil {
+		log.Error(err, "Failed to update CacheServer status")
+		return ctrl.Result{}, err
+	}
+
+	// Apply or remove the headless service naming the StatefulSet pods or
+	// listing the host network pods
+	if cacheServerStatefulSet(cacheServer) || cacheServer.Spec.Network.HostNetwork {
+		headlessSvc := r.headlessServiceForCacheServer(cacheServer)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, cacheServer, headlessSvc, cacheServerFieldOwner); err != nil {
+			log.Error(err, "Failed to apply Service", "Service.Namespace", headlessSvc.Namespace, "Service.Name", headlessSvc.Name)
+			return ctrl.Result{}, err
+		}
+	} else if err := r.deleteService(ctx, cacheServer, cacheServerHeadlessServiceName(cacheServer)); err != nil {
+		log.Error(err, "Failed to delete headless Service")
+		return ctrl.Result{}, err
+	}
+
+	// Create or expand the PersistentVolumeClaims of the cache
+	if err := r.reconcileStorage(ctx, cacheServer); err != nil {
+		log.Error(err, "Failed to reconcile the storage of the CacheServer")
+		return ctrl.Result{}, err
+	}
+
+	// Render or resolve the config file. Pods are not started with a missing
+	// or invalid config, the ConfigMap watch updates the CacheServer once it
+	// is fixed.
+	inputs := &cacheServerInputs{annotations: map[string]string{}}
+	configHash, configCondition, err := r.reconcileConfig(ctx, cacheServer)
+	if err != nil {
+		log.Error(err, "Failed to reconcile the config of the CacheServer")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionConfigResolved, configCondition); err != nil {
+		log.Error(err, "Failed to update CacheServer status")
+		return ctrl.Result{}, err
+	}
+	if configCondition != nil && configCondition.Status != metav1.ConditionTrue {
+		log.Info("Waiting for config", "Message", configCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if configHash != "" {
+		inputs.annotations[cacheServerConfigHashAnnotation] = configHash
+	}
+
+	// Scale the workload with a HorizontalPodAutoscaler
+	hpa, err := reconcileHorizontalPodAutoscaler(ctx, r.Client, r.Scheme, cacheServer, cacheServerAutoscalingSpec(cacheServer),
+		cacheServerWorkloadKind(cacheServer), cacheServer.Name, map[string]string{"app": cacheServer.Name})
+	if err != nil {
+		log.Error(err, "Failed to reconcile HorizontalPodAutoscaler")
+		return ctrl.Result{}, err
+	}
+
+	// Protect the cache server from voluntary disruptions such as node drains
+	pdbSpec := cacheServer.Spec.PodDisruptionBudget
+	if pdbSpec.Enabled && cacheServerReplicas(cacheServer) <= 1 {
+		r.Recorder.Event(cacheServer, corev1.EventTypeWarning, "PodDisruptionBudgetSingleReplica",
+			"podDisruptionBudget with a single replica either blocks node drains or lets the only pod be evicted")
+	}
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, cacheServer, pdbSpec, pdbSpec.Enabled, cacheServer.Name, map[string]string{"app": cacheServer.Name}); err != nil {
+		log.Error(err, "Failed to reconcile PodDisruptionBudget")
+		return ctrl.Result{}, err
+	}
+
+	// Delete the workload of the previous workloadType. The workload watch
+	// requeues the CacheServer once it is gone.
+	var previous client.Object = &appsv1.StatefulSet{}
+	if cacheServerStatefulSet(cacheServer) {
+		previous = &appsv1.Deployment{}
+	}
+	if replaced, err := r.replaceWorkload(ctx, cacheServer, previous, "workloadType changed to "+cacheServer.Spec.WorkloadType); err != nil {
+		log.Error(err, "Failed to delete the previous workload")
 		return ctrl.Result{}, err
+	} else if !replaced {
+		return ctrl.Result{}, nil
+	}
+
+	if cacheServerStatefulSet(cacheServer) {
+		return r.reconcileStatefulSet(ctx, cacheServer, inputs, hpa)
 	}
 
 	// Check if the deployment already exists, if not create a new one
@@ -93,9 +279,9 @@ func (r *CacheServerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	err = r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, found)
 	if err != nil && errors.IsNotFound(err) {
 		// Define a new deployment
-		dep := r.deploymentForCacheServer(cacheServer)
+		dep := r.deploymentForCacheServer(cacheServer, inputs)
 		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
-		err = r.Create(ctx, dep)
+		err = createAndRecord(ctx, r.Client, r.Recorder, cacheServer, dep)
 		if err != nil {
 			log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
 			return ctrl.Result{}, err
@@ -108,12 +294,15 @@ func (r *CacheServerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Update the deployment if needed
-	if r.deploymentNeedsUpdate(found, cacheServer) {
+	if r.deploymentNeedsUpdate(found, cacheServer, inputs) {
 		log.Info("Updating Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
 		// Create new deployment spec
-		newDep := r.deploymentForCacheServer(cacheServer)
+		newDep := r.deploymentForCacheServer(cacheServer, inputs)
+		if cacheServer.Spec.Autoscaling.Enabled {
+			newDep.Spec.Replicas = found.Spec.Replicas
+		}
 
-		err = r.Update(ctx, newDep)
+		err = updateAndRecord(ctx, r.Client, r.Recorder, cacheServer, found, newDep)
 		if err != nil {
 			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
 			return ctrl.Result{}, err
@@ -122,17 +311,97 @@ func (r *CacheServerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Update the status
-	if err := r.updateStatus(ctx, cacheServer, found); err != nil {
-		log.Error(err, "Failed to update CacheServer status")
+	return r.reportWorkloadStatus(ctx, cacheServer, deploymentWorkloadStatus(found), hpa)
+}
+
+// reconcileStatefulSet creates or updates the StatefulSet of the
+// StatefulSet workloadType. Its volumeClaimTemplates are immutable, so the
+// StatefulSet is replaced when storage is turned on or off.
+func (r *CacheServerReconciler) reconcileStatefulSet(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs, hpa *autoscalingv2.HorizontalPodAutoscaler) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	found := &appsv1.StatefulSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		sts := r.statefulSetForCacheServer(cacheServer, inputs)
+		log.Info("Creating a new StatefulSet", "StatefulSet.Namespace", sts.Namespace, "StatefulSet.Name", sts.Name)
+		if err := createAndRecord(ctx, r.Client, r.Recorder, cacheServer, sts); err != nil {
+			log.Error(err, "Failed to create new StatefulSet", "StatefulSet.Namespace", sts.Namespace, "StatefulSet.Name", sts.Name)
+			return ctrl.Result{}, err
+		}
+		// StatefulSet created successfully - return and requeue
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get StatefulSet")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if (len(found.Spec.VolumeClaimTemplates) > 0) != cacheServer.Spec.Storage.Enabled {
+		if _, err := r.replaceWorkload(ctx, cacheServer, found, "the volumeClaimTemplates changed with storage.enabled"); err != nil {
+			log.Error(err, "Failed to delete StatefulSet", "StatefulSet.Namespace", found.Namespace, "StatefulSet.Name", found.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Update the StatefulSet if needed, keeping its volumeClaimTemplates
+	if r.statefulSetNeedsUpdate(found, cacheServer, inputs) {
+		log.Info("Updating StatefulSet", "StatefulSet.Namespace", found.Namespace, "StatefulSet.Name", found.Name)
+		newSts := r.statefulSetForCacheServer(cacheServer, inputs)
+		newSts.Spec.VolumeClaimTemplates = found.Spec.VolumeClaimTemplates
+		if cacheServer.Spec.Autoscaling.Enabled {
+			newSts.Spec.Replicas = found.Spec.Replicas
+		}
+		if err := updateAndRecord(ctx, r.Client, r.Recorder, cacheServer, found, newSts); err != nil {
+			log.Error(err, "Failed to update StatefulSet", "StatefulSet.Namespace", found.Namespace, "StatefulSet.Name", found.Name)
+			return ctrl.Result{}, err
+		}
+		// StatefulSet updated successfully - return and requeue
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return r.reportWorkloadStatus(ctx, cacheServer, statefulSetWorkloadStatus(found), hpa)
 }
 
-// deploymentForCacheServer returns a CacheServer Deployment object
-func (r *CacheServerReconciler) deploymentForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *appsv1.Deployment {
+// replaceWorkload deletes the workload obj of the CacheServer, reporting why
+// in the WorkloadReplaced condition, and returns true once it is gone. The
+// workloads of a CacheServer never share a PersistentVolumeClaim, so the
+// replacement does not wait for the pods.
+func (r *CacheServerReconciler) replaceWorkload(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer, obj client.Object, reason string) (bool, error) {
+	err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, obj)
+	if errors.IsNotFound(err) {
+		return true, r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionWorkloadReplaced, nil)
+	} else if err != nil {
+		return false, err
+	}
+	if !metav1.IsControlledBy(obj, cacheServer) {
+		return true, nil
+	}
+
+	kind := "Deployment"
+	if _, ok := obj.(*appsv1.StatefulSet); ok {
+		kind = "StatefulSet"
+	}
+	condition := &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionWorkloadReplaced,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Deleting" + kind,
+		Message: fmt.Sprintf("Deleting %s %s before creating its replacement, %s", kind, obj.GetName(), reason),
+	}
+	if obj.GetDeletionTimestamp() == nil {
+		log.FromContext(ctx).Info("Deleting "+kind, kind+".Namespace", obj.GetNamespace(), kind+".Name", obj.GetName())
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			r.Recorder.Eventf(cacheServer, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete %s %s: %v", kind, obj.GetName(), err)
+			return false, err
+		}
+		r.Recorder.Event(cacheServer, corev1.EventTypeNormal, condition.Reason, condition.Message)
+	}
+	return false, r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionWorkloadReplaced, condition)
+}
+
+// podTemplateForCacheServer returns the pod template of the CacheServer
+// workload, mounting the storage volume without declaring it
+func podTemplateForCacheServer(cacheServer *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs) corev1.PodTemplateSpec {
 	labels := map[string]string{
 		"app": cacheServer.Name,
 	}
@@ -143,18 +412,20 @@ func (r *CacheServerReconciler) deploymentForCacheServer(cacheServer *production
 		Limits:   corev1.ResourceList{},
 	}
 
-	if cacheServer.Spec.Resources.CPU != "" {
-		resources.Requests[corev1.ResourceCPU] = resource.MustParse(cacheServer.Spec.Resources.CPU)
-		resources.Limits[corev1.ResourceCPU] = resource.MustParse(cacheServer.Spec.Resources.CPU)
-	}
+	// Reconcile stops at unparsable quantities, so none are left out here
+	setResourceQuantity(&resources, corev1.ResourceCPU, cacheServer.Spec.Resources.CPU)
+	setResourceQuantity(&resources, corev1.ResourceMemory, cacheServer.Spec.Resources.Memory)
+
+	// The webhook requires GPUs for the cuda device
+	setResourceQuantity(&resources, "nvidia.com/gpu", cacheServer.Spec.Resources.GPU)
 
-	if cacheServer.Spec.Resources.Memory != "" {
-		resources.Requests[corev1.ResourceMemory] = resource.MustParse(cacheServer.Spec.Resources.Memory)
-		resources.Limits[corev1.ResourceMemory] = resource.MustParse(cacheServer.Spec.Resources.Memory)
+	// Extended resources, e.g. RDMA devices, are requested and limited alike
+	for name, value := range cacheServer.Spec.Resources.Extra {
+		setResourceQuantity(&resources, corev1.ResourceName(name), value)
 	}
 
 	// Get the image from Image spec
-	image := cacheServer.Spec.Image.Registry + "/" + cacheServer.Spec.Image.Name
+	image := imageReference(cacheServer.Spec.Image)
 
 	// Get the image pull policy
 	imagePullPolicy := corev1.PullIfNotPresent
@@ -162,41 +433,282 @@ func (r *CacheServerReconciler) deploymentForCacheServer(cacheServer *production
 		imagePullPolicy = corev1.PullPolicy(cacheServer.Spec.Image.PullPolicy)
 	}
 
+	// Build image pull secrets
+	var imagePullSecrets []corev1.LocalObjectReference
+	if cacheServer.Spec.Image.PullSecretName != "" {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{
+			Name: cacheServer.Spec.Image.PullSecretName,
+		})
+	}
+
+	command := []string{
+		"lmcache_experimental_server",
+		"0.0.0.0",
+		fmt.Sprintf("%d", cacheServer.Spec.Port)}
+	if cacheServer.Spec.Device != "" {
+		command = append(command, cacheServer.Spec.Device)
+	}
+
+	ports := []corev1.ContainerPort{
+		{
+			Name:          "http",
+			ContainerPort: cacheServer.Spec.Port,
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+	if cacheServer.Spec.Monitoring.Enabled {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "metrics",
+			ContainerPort: cacheServer.Spec.Monitoring.MetricsPort,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	// The API server defaults the host ports of host network pods to their
+	// container ports, which also keeps two replicas off the same node
+	if cacheServer.Spec.Network.HostNetwork {
+		for i := range ports {
+			ports[i].HostPort = ports[i].ContainerPort
+		}
+	}
+
+	// User-defined variables come after the operator ones, which they may
+	// not override
+	env := cacheServerOperatorEnv(cacheServer)
+	for _, e := range cacheServer.Spec.Env {
+		if !envDefined(env, e.Name) {
+			env = append(env, containerEnvVar(e))
+		}
+	}
+	var envFrom []corev1.EnvFromSource
+	for i := range cacheServer.Spec.EnvFrom {
+		envFrom = append(envFrom, *cacheServer.Spec.EnvFrom[i].DeepCopy())
+	}
+
+	// The API server defaults the pod security context to an empty one
+	podSecurityContext := &corev1.PodSecurityContext{}
+	if cacheServer.Spec.PodSecurityContext != nil {
+		podSecurityContext = cacheServer.Spec.PodSecurityContext.DeepCopy()
+	}
+
+	// Merge the user pod labels and annotations, the operator keys take precedence
+	podLabels := make(map[string]string, len(cacheServer.Spec.PodLabels)+len(labels))
+	for k, v := range cacheServer.Spec.PodLabels {
+		podLabels[k] = v
+	}
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+	var annotations map[string]string
+	if len(cacheServer.Spec.PodAnnotations) > 0 || inputs != nil && len(inputs.annotations) > 0 {
+		annotations = make(map[string]string, len(cacheServer.Spec.PodAnnotations))
+		for k, v := range cacheServer.Spec.PodAnnotations {
+			annotations[k] = v
+		}
+		if inputs != nil {
+			for k, v := range inputs.annotations {
+				annotations[k] = v
+			}
+		}
+	}
+
+	// Mount the config file
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if name, _ := cacheServerConfigMapKey(cacheServer); name != "" {
+		defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+		volumes = append(volumes, corev1.Volume{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					DefaultMode:          &defaultMode,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "config",
+			MountPath: cacheServerConfigMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// Let the server flush in-flight writes before it is stopped
+	var lifecycle *corev1.Lifecycle
+	termination := cacheServer.Spec.Termination
+	if len(termination.PreStopCommand) > 0 {
+		lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: termination.PreStopCommand},
+			},
+		}
+	} else if httpGet := termination.PreStopHTTPGet; httpGet != nil {
+		port := httpGet.Port
+		if port == 0 {
+			port = cacheServer.Spec.Port
+		}
+		// The API server defaults the scheme
+		lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path:   httpGet.Path,
+					Port:   intstr.FromInt32(port),
+					Scheme: corev1.URISchemeHTTP,
+				},
+			},
+		}
+	}
+	terminationGracePeriodSeconds := termination.GracePeriodSeconds
+
+	// The server stores the cache on disk when given a path as its device
+	if cacheServer.Spec.Storage.Enabled {
+		mountPath := cacheServerStorageMountPath(cacheServer)
+		command = append(command, mountPath)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "storage",
+			MountPath: mountPath,
+		})
+	}
+	command = append(command, cacheServer.Spec.ExtraArgs...)
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      podLabels,
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets:              imagePullSecrets,
+			SecurityContext:               podSecurityContext,
+			HostNetwork:                   cacheServer.Spec.Network.HostNetwork,
+			DNSPolicy:                     cacheServerDNSPolicy(cacheServer),
+			TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+			Volumes:                       volumes,
+			NodeSelector:                  cacheServer.Spec.NodeSelector,
+			Tolerations:                   cacheServer.Spec.Tolerations,
+			Affinity:                      cacheServerAffinity(cacheServer),
+			Containers: []corev1.Container{
+				{
+					Name:            "cache-server",
+					Image:           image,
+					ImagePullPolicy: imagePullPolicy,
+					Command:         command,
+					Env:             env,
+					EnvFrom:         envFrom,
+					Ports:           ports,
+					Resources:       resources,
+					VolumeMounts:    volumeMounts,
+					Lifecycle:       lifecycle,
+					SecurityContext: cacheServer.Spec.SecurityContext.DeepCopy(),
+				},
+			},
+		},
+	}
+}
+
+// cacheServerOperatorEnv returns the environment variables of the cache
+// server container set by the operator
+func cacheServerOperatorEnv(cacheServer *productionstackv1alpha1.CacheServer) []corev1.EnvVar {
+	// LMCache reads the serde and chunk size from its environment
+	var env []corev1.EnvVar
+	if cacheServer.Spec.Serde != "" {
+		env = append(env, corev1.EnvVar{Name: "LMCACHE_REMOTE_SERDE", Value: cacheServer.Spec.Serde})
+	}
+	if cacheServer.Spec.ChunkSize > 0 {
+		env = append(env, corev1.EnvVar{Name: "LMCACHE_CHUNK_SIZE", Value: fmt.Sprintf("%d", cacheServer.Spec.ChunkSize)})
+	}
+	if name, key := cacheServerConfigMapKey(cacheServer); name != "" {
+		env = append(env, corev1.EnvVar{Name: "LMCACHE_CONFIG_FILE", Value: path.Join(cacheServerConfigMountPath, key)})
+	}
+	return env
+}
+
+// cacheServerResourcesCondition returns a SpecInvalid condition listing the
+// quantities of resources that cannot be parsed, or nil if all can
+func cacheServerResourcesCondition(cacheServer *productionstackv1alpha1.CacheServer) *metav1.Condition {
+	quantities := map[string]string{
+		"resources.cpu":    cacheServer.Spec.Resources.CPU,
+		"resources.memory": cacheServer.Spec.Resources.Memory,
+		"resources.gpu":    cacheServer.Spec.Resources.GPU,
+	}
+	for name, value := range cacheServer.Spec.Resources.Extra {
+		quantities[fmt.Sprintf("resources.extra[%s]", name)] = value
+	}
+	return invalidQuantityCondition(quantities)
+}
+
+// overriddenEnvNames returns the variables of spec.env ignored because the
+// operator sets them
+func overriddenEnvNames(cacheServer *productionstackv1alpha1.CacheServer) []string {
+	operatorEnv := cacheServerOperatorEnv(cacheServer)
+	var names []string
+	for _, e := range cacheServer.Spec.Env {
+		if envDefined(operatorEnv, e.Name) {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
+
+// cacheServerAffinity returns the affinity of the CacheServer pods, adding a
+// preferred pod affinity to the pods of colocateWithRuntime
+func cacheServerAffinity(cacheServer *productionstackv1alpha1.CacheServer) *corev1.Affinity {
+	colocate := cacheServer.Spec.ColocateWithRuntime
+	if colocate == nil {
+		return cacheServer.Spec.Affinity
+	}
+
+	affinity := &corev1.Affinity{}
+	if cacheServer.Spec.Affinity != nil {
+		affinity = cacheServer.Spec.Affinity.DeepCopy()
+	}
+	if affinity.PodAffinity == nil {
+		affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": colocate.Name},
+				},
+				TopologyKey: corev1.LabelHostname,
+			},
+		})
+	return affinity
+}
+
+// deploymentForCacheServer returns a CacheServer Deployment object
+func (r *CacheServerReconciler) deploymentForCacheServer(cacheServer *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs) *appsv1.Deployment {
+	template := podTemplateForCacheServer(cacheServer, inputs)
+
+	// Only one pod may hold the ReadWriteOnce claim, so rollouts recreate
+	// the cache server
+	strategy := deploymentStrategy(cacheServer.Spec.DeploymentStrategy, cacheServer.Spec.RollingUpdate)
+	if cacheServer.Spec.Storage.Enabled {
+		strategy = deploymentStrategy(string(appsv1.RecreateDeploymentStrategyType), nil)
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: "storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: cacheServerStoragePVCName(cacheServer),
+				},
+			},
+		})
+	}
+
+	replicas := cacheServerReplicas(cacheServer)
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cacheServer.Name,
 			Namespace: cacheServer.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &cacheServer.Spec.Replicas,
+			Replicas: &replicas,
+			Strategy: strategy,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: labels,
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:            "cache-server",
-							Image:           image,
-							ImagePullPolicy: imagePullPolicy,
-							Command: []string{
-								"lmcache_experimental_server",
-								"0.0.0.0",
-								fmt.Sprintf("%d", cacheServer.Spec.Port)},
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: cacheServer.Spec.Port,
-								},
-							},
-							Resources: resources,
-						},
-					},
-				},
+				MatchLabels: map[string]string{"app": cacheServer.Name},
 			},
+			Template: template,
 		},
 	}
 
@@ -205,83 +717,1116 @@ func (r *CacheServerReconciler) deploymentForCacheServer(cacheServer *production
 	return dep
 }
 
+// statefulSetForCacheServer returns a CacheServer StatefulSet object. Each
+// replica claims its own storage volume.
+func (r *CacheServerReconciler) statefulSetForCacheServer(cacheServer *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs) *appsv1.StatefulSet {
+	template := podTemplateForCacheServer(cacheServer, inputs)
+
+	replicas := cacheServerReplicas(cacheServer)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServer.Name,
+			Namespace: cacheServer.Namespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: cacheServerHeadlessServiceName(cacheServer),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": cacheServer.Name},
+			},
+			Template: template,
+		},
+	}
+	if cacheServer.Spec.Storage.Enabled {
+		sts.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "storage",
+					Labels: map[string]string{"app": cacheServer.Name},
+				},
+				Spec: cacheServerStoragePVCSpec(cacheServer),
+			},
+		}
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, sts, r.Scheme)
+	return sts
+}
+
 // deploymentNeedsUpdate checks if the deployment needs to be updated
-func (r *CacheServerReconciler) deploymentNeedsUpdate(dep *appsv1.Deployment, cs *productionstackv1alpha1.CacheServer) bool {
-	// Compare replicas
-	if *dep.Spec.Replicas != cs.Spec.Replicas {
+func (r *CacheServerReconciler) deploymentNeedsUpdate(dep *appsv1.Deployment, cs *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs) bool {
+	// Compare replicas, unless they are left to the HorizontalPodAutoscaler
+	if !cs.Spec.Autoscaling.Enabled && *dep.Spec.Replicas != cs.Spec.Replicas {
 		return true
 	}
 
-	// Compare resources
-	expectedResources := r.deploymentForCacheServer(cs).Spec.Template.Spec.Containers[0].Resources
-	actualResources := dep.Spec.Template.Spec.Containers[0].Resources
-	if !reflect.DeepEqual(expectedResources, actualResources) {
+	// Compare the rollout strategy
+	expectedDep := r.deploymentForCacheServer(cs, inputs)
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Strategy, dep.Spec.Strategy) {
 		return true
 	}
 
-	return false
+	return podTemplateNeedsUpdate(&expectedDep.Spec.Template, &dep.Spec.Template)
 }
 
-// updateStatus updates the status of the CacheServer
-func (r *CacheServerReconciler) updateStatus(ctx context.Context, cs *productionstackv1alpha1.CacheServer, dep *appsv1.Deployment) error {
-	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		// Get the latest version of the CacheServer
-		latestCS := &productionstackv1alpha1.CacheServer{}
-		if err := r.Get(ctx, types.NamespacedName{Name: cs.Name, Namespace: cs.Namespace}, latestCS); err != nil {
-			return err
-		}
+// statefulSetNeedsUpdate checks if the StatefulSet needs to be updated,
+// ignoring its immutable volumeClaimTemplates
+func (r *CacheServerReconciler) statefulSetNeedsUpdate(sts *appsv1.StatefulSet, cs *productionstackv1alpha1.CacheServer, inputs *cacheServerInputs) bool {
+	// Compare replicas, unless they are left to the HorizontalPodAutoscaler
+	if !cs.Spec.Autoscaling.Enabled && *sts.Spec.Replicas != cs.Spec.Replicas {
+		return true
+	}
 
-		// Update the status fields
-		latestCS.Status.LastUpdated = metav1.Now()
+	expectedSts := r.statefulSetForCacheServer(cs, inputs)
+	return podTemplateNeedsUpdate(&expectedSts.Spec.Template, &sts.Spec.Template)
+}
 
-		// Update status based on deployment status
-		if dep.Status.AvailableReplicas > 0 {
-			latestCS.Status.Status = "Ready"
-		} else if dep.Status.UpdatedReplicas > 0 {
-			latestCS.Status.Status = "Updating"
-		} else {
-			latestCS.Status.Status = "NotReady"
-		}
+// podTemplateNeedsUpdate checks if the pod template of a CacheServer
+// workload differs from the expected one
+func podTemplateNeedsUpdate(expected, actual *corev1.PodTemplateSpec) bool {
+	// Compare the pod labels and annotations, carrying the hashes of the
+	// referenced Secrets
+	if !equality.Semantic.DeepEqual(expected.Labels, actual.Labels) ||
+		!equality.Semantic.DeepEqual(expected.Annotations, actual.Annotations) {
+		return true
+	}
 
-		return r.Status().Update(ctx, latestCS)
-	})
-}
+	expectedContainer := expected.Spec.Containers[0]
+	actualContainer := actual.Spec.Containers[0]
 
-// serviceForCacheServer returns a CacheServer Service object
-func (r *CacheServerReconciler) serviceForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *corev1.Service {
-	labels := map[string]string{
-		"app": cacheServer.Name,
+	// Compare the image
+	if expectedContainer.Image != actualContainer.Image ||
+		expectedContainer.ImagePullPolicy != actualContainer.ImagePullPolicy ||
+		!equality.Semantic.DeepEqual(expected.Spec.ImagePullSecrets, actual.Spec.ImagePullSecrets) {
+		return true
 	}
 
-	svc := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cacheServer.Name,
-			Namespace: cacheServer.Namespace,
-		},
-		Spec: corev1.ServiceSpec{
-			Type:     corev1.ServiceTypeClusterIP,
-			Selector: labels,
-			Ports: []corev1.ServicePort{
-				{
-					Name:       "http",
-					Port:       80,
-					TargetPort: intstr.FromInt(int(cacheServer.Spec.Port)),
-					Protocol:   corev1.ProtocolTCP,
-				},
-			},
-		},
+	// Compare resources
+	if !equality.Semantic.DeepEqual(expectedContainer.Resources, actualContainer.Resources) {
+		return true
 	}
 
-	// Set the owner reference
-	ctrl.SetControllerReference(cacheServer, svc, r.Scheme)
-	return svc
-}
+	// Compare the command line, carrying the port, device and extra args
+	if !reflect.DeepEqual(expectedContainer.Command, actualContainer.Command) ||
+		!equality.Semantic.DeepEqual(expectedContainer.Env, actualContainer.Env) ||
+		!equality.Semantic.DeepEqual(expectedContainer.EnvFrom, actualContainer.EnvFrom) ||
+		!equality.Semantic.DeepEqual(expectedContainer.Ports, actualContainer.Ports) {
+		return true
+	}
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *CacheServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&productionstackv1alpha1.CacheServer{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&corev1.Service{}).
-		Complete(r)
+	// Compare the security contexts
+	if !equality.Semantic.DeepEqual(expected.Spec.SecurityContext, actual.Spec.SecurityContext) ||
+		!equality.Semantic.DeepEqual(expectedContainer.SecurityContext, actualContainer.SecurityContext) {
+		return true
+	}
+
+	// Compare the network
+	if expected.Spec.HostNetwork != actual.Spec.HostNetwork || expected.Spec.DNSPolicy != actual.Spec.DNSPolicy {
+		return true
+	}
+
+	// Compare the termination
+	if !equality.Semantic.DeepEqual(expected.Spec.TerminationGracePeriodSeconds, actual.Spec.TerminationGracePeriodSeconds) ||
+		!equality.Semantic.DeepEqual(expectedContainer.Lifecycle, actualContainer.Lifecycle) {
+		return true
+	}
+
+	// Compare the node placement
+	if !equality.Semantic.DeepEqual(expected.Spec.NodeSelector, actual.Spec.NodeSelector) ||
+		!equality.Semantic.DeepEqual(expected.Spec.Tolerations, actual.Spec.Tolerations) ||
+		!equality.Semantic.DeepEqual(expected.Spec.Affinity, actual.Spec.Affinity) {
+		return true
+	}
+
+	// Compare the storage volume
+	if !equality.Semantic.DeepEqual(expectedContainer.VolumeMounts, actualContainer.VolumeMounts) ||
+		!equality.Semantic.DeepEqual(expected.Spec.Volumes, actual.Spec.Volumes) {
+		return true
+	}
+
+	return false
+}
+
+// cacheServerWorkloadStatus is the status of the Deployment or StatefulSet
+// running a CacheServer
+type cacheServerWorkloadStatus struct {
+	// replicas is the desired number of pods of the workload
+	replicas          int32
+	readyReplicas     int32
+	availableReplicas int32
+	updatedReplicas   int32
+
+	// conditions are the Available, Progressing and Degraded conditions
+	conditions []metav1.Condition
+}
+
+// deploymentWorkloadStatus returns the status of a CacheServer Deployment
+func deploymentWorkloadStatus(dep *appsv1.Deployment) cacheServerWorkloadStatus {
+	return cacheServerWorkloadStatus{
+		replicas:          replicasValue(dep.Spec.Replicas),
+		readyReplicas:     dep.Status.ReadyReplicas,
+		availableReplicas: dep.Status.AvailableReplicas,
+		updatedReplicas:   dep.Status.UpdatedReplicas,
+		conditions:        deploymentStatusConditions(dep, "cache server"),
+	}
+}
+
+// statefulSetWorkloadStatus returns the status of a CacheServer StatefulSet.
+// StatefulSets report no conditions, so they are derived from the replica
+// counts and revisions.
+func statefulSetWorkloadStatus(sts *appsv1.StatefulSet) cacheServerWorkloadStatus {
+	available := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionAvailable,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NoReplicasAvailable",
+		Message: "The cache server StatefulSet has no available replica",
+	}
+	if sts.Status.AvailableReplicas > 0 {
+		available.Status = metav1.ConditionTrue
+		available.Reason = "ReplicasAvailable"
+		available.Message = fmt.Sprintf("%d replicas of the cache server StatefulSet are available", sts.Status.AvailableReplicas)
+	}
+	progressing := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  "RolloutComplete",
+		Message: "The cache server StatefulSet is up to date",
+	}
+	if sts.Generation > sts.Status.ObservedGeneration {
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "StatefulSetUpdating"
+		progressing.Message = "The cache server StatefulSet spec has not been observed yet"
+	} else if sts.Status.UpdateRevision != sts.Status.CurrentRevision || sts.Status.UpdatedReplicas < sts.Status.Replicas {
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "RollingUpdate"
+		progressing.Message = fmt.Sprintf("%d of %d replicas of the cache server StatefulSet are updated", sts.Status.UpdatedReplicas, sts.Status.Replicas)
+	}
+	degraded := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: "The cache server StatefulSet reports no failure",
+	}
+
+	return cacheServerWorkloadStatus{
+		replicas:          replicasValue(sts.Spec.Replicas),
+		readyReplicas:     sts.Status.ReadyReplicas,
+		availableReplicas: sts.Status.AvailableReplicas,
+		updatedReplicas:   sts.Status.UpdatedReplicas,
+		conditions:        []metav1.Condition{available, progressing, degraded},
+	}
+}
+
+// cacheServerEndpoint returns the in-cluster host and port of the CacheServer
+// Service, or of its headless Service on the host network. In external mode
+// it returns those of the backend, or of its ExternalName Service.
+func cacheServerEndpoint(cacheServer *productionstackv1alpha1.CacheServer) string {
+	if cacheServerExternal(cacheServer) {
+		u, err := url.Parse(cacheServer.Spec.External.URL)
+		if err != nil {
+			return ""
+		}
+		if !cacheServer.Spec.External.Service {
+			return u.Host
+		}
+		port := u.Port()
+		if port == "" {
+			port = externalBackendDefaultPorts[u.Scheme]
+		}
+		return fmt.Sprintf("%s.%s.svc:%s", cacheServer.Name, cacheServer.Namespace, port)
+	}
+	if cacheServer.Spec.Network.HostNetwork {
+		return fmt.Sprintf("%s.%s.svc:%d", cacheServerHeadlessServiceName(cacheServer), cacheServer.Namespace, cacheServer.Spec.Port)
+	}
+	return fmt.Sprintf("%s.%s.svc:%d", cacheServer.Name, cacheServer.Namespace, cacheServerServicePort(cacheServer))
+}
+
+// cacheServerRemoteURL returns the LMCache remote URL of the CacheServer, the
+// lm:// URL of its Service or the URL of its external backend with the host
+// of the ExternalName Service
+func cacheServerRemoteURL(cacheServer *productionstackv1alpha1.CacheServer) string {
+	if !cacheServerExternal(cacheServer) {
+		return "lm://" + cacheServerEndpoint(cacheServer)
+	}
+	u, err := url.Parse(cacheServer.Spec.External.URL)
+	if err != nil {
+		return cacheServer.Spec.External.URL
+	}
+	u.Host = cacheServerEndpoint(cacheServer)
+	return u.String()
+}
+
+// cacheServerClientSecret returns the Secret key the clients of the CacheServer
+// authenticate with, the password of its external backend
+func cacheServerClientSecret(cacheServer *productionstackv1alpha1.CacheServer) *productionstackv1alpha1.SecretKeyReference {
+	if cacheServerExternal(cacheServer) {
+		return cacheServer.Spec.External.CredentialsSecretRef
+	}
+	return nil
+}
+
+// reportWorkloadStatus updates the status of the CacheServer from its workload
+// and pods, and from hpa when autoscaling is enabled. Failing containers, e.g.
+// an image that cannot be pulled, override the Degraded condition of the
+// workload.
+func (r *CacheServerReconciler) reportWorkloadStatus(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer, workload cacheServerWorkloadStatus, hpa *autoscalingv2.HorizontalPodAutoscaler) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	degradedCondition, err := podsDegradedCondition(ctx, r.Client, cacheServer.Namespace, map[string]string{"app": cacheServer.Name})
+	if err != nil {
+		log.Error(err, "Failed to list CacheServer pods")
+		return ctrl.Result{}, err
+	}
+	if degradedCondition != nil {
+		meta.SetStatusCondition(&workload.conditions, *degradedCondition)
+		r.recordConditionWarning(cacheServer, degradedCondition)
+	}
+
+	if err := r.updateStatus(ctx, cacheServer, workload, hpa); err != nil {
+		log.Error(err, "Failed to update CacheServer status")
+		return ctrl.Result{}, err
+	}
+
+	// Pods are not watched, recheck periodically so that the Degraded
+	// condition follows the pods
+	if degradedCondition != nil {
+		return ctrl.Result{RequeueAfter: degradedResyncPeriod}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// recordConditionWarning records a Warning event for a condition reporting a
+// problem, unless the condition is already set with the same reason
+func (r *CacheServerReconciler) recordConditionWarning(cacheServer *productionstackv1alpha1.CacheServer, condition *metav1.Condition) {
+	if condition == nil {
+		return
+	}
+	if previous := meta.FindStatusCondition(cacheServer.Status.Conditions, condition.Type); previous != nil && previous.Reason == condition.Reason {
+		return
+	}
+	r.Recorder.Event(cacheServer, corev1.EventTypeWarning, condition.Reason, condition.Message)
+}
+
+// updateStatus updates the status of the CacheServer
+func (r *CacheServerReconciler) updateStatus(ctx context.Context, cs *productionstackv1alpha1.CacheServer, workload cacheServerWorkloadStatus, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the CacheServer
+		latestCS := &productionstackv1alpha1.CacheServer{}
+		if err := r.Get(ctx, types.NamespacedName{Name: cs.Name, Namespace: cs.Namespace}, latestCS); err != nil {
+			return err
+		}
+
+		// Update the status fields
+		latestCS.Status.LastUpdated = metav1.Now()
+		latestCS.Status.ObservedGeneration = cs.Generation
+		latestCS.Status.ReadyReplicas = workload.readyReplicas
+		latestCS.Status.Ready = fmt.Sprintf("%d/%d", workload.readyReplicas, workload.replicas)
+		latestCS.Status.Selector = labels.SelectorFromSet(labels.Set{"app": cs.Name}).String()
+		latestCS.Status.Endpoint = cacheServerEndpoint(cs)
+
+		// Report the HorizontalPodAutoscaler
+		if hpa != nil {
+			latestCS.Status.DesiredReplicas = hpa.Status.DesiredReplicas
+			condition := autoscalingActiveCondition(hpa)
+			condition.ObservedGeneration = cs.Generation
+			meta.SetStatusCondition(&latestCS.Status.Conditions, condition)
+		} else {
+			latestCS.Status.DesiredReplicas = 0
+			meta.RemoveStatusCondition(&latestCS.Status.Conditions, productionstackv1alpha1.ConditionAutoscalingActive)
+		}
+
+		// Report the health of the workload
+		for _, condition := range workload.conditions {
+			condition.ObservedGeneration = cs.Generation
+			meta.SetStatusCondition(&latestCS.Status.Conditions, condition)
+		}
+
+		// Update status based on the workload status
+		if workload.availableReplicas > 0 {
+			latestCS.Status.Status = "Ready"
+		} else if workload.updatedReplicas > 0 {
+			latestCS.Status.Status = "Updating"
+		} else {
+			latestCS.Status.Status = "NotReady"
+		}
+
+		return r.Status().Update(ctx, latestCS)
+	})
+}
+
+// serviceForCacheServer returns a CacheServer Service object
+func (r *CacheServerReconciler) serviceForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *corev1.Service {
+	labels := map[string]string{
+		"app": cacheServer.Name,
+	}
+
+	ports := []corev1.ServicePort{
+		{
+			Name:       "http",
+			Port:       cacheServerServicePort(cacheServer),
+			TargetPort: intstr.FromInt(int(cacheServer.Spec.Port)),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+	if cacheServer.Spec.Monitoring.Enabled {
+		metricsPort := cacheServer.Spec.Monitoring.MetricsPort
+		ports = append(ports, corev1.ServicePort{
+			Name:       "metrics",
+			Port:       metricsPort,
+			TargetPort: intstr.FromInt(int(metricsPort)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServer.Name,
+			Namespace: cacheServer.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: labels,
+			Ports:    ports,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, svc, r.Scheme)
+	return svc
+}
+
+// cacheServerHeadlessServiceName returns the name of the headless Service of
+// a CacheServer StatefulSet or on the host network
+func cacheServerHeadlessServiceName(cacheServer *productionstackv1alpha1.CacheServer) string {
+	return cacheServer.Name + "-pods"
+}
+
+// headlessServiceForCacheServer returns the headless Service giving the
+// StatefulSet pods their DNS names
+func (r *CacheServerReconciler) headlessServiceForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *corev1.Service {
+	labels := map[string]string{
+		"app": cacheServer.Name,
+	}
+
+	ports := []corev1.ServicePort{
+		{
+			Name:       "http",
+			Port:       cacheServer.Spec.Port,
+			TargetPort: intstr.FromInt(int(cacheServer.Spec.Port)),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+	// The ServiceMonitor scrapes the headless service when it replaces the
+	// ClusterIP one
+	if cacheServer.Spec.Network.HostNetwork && cacheServer.Spec.Monitoring.Enabled {
+		metricsPort := cacheServer.Spec.Monitoring.MetricsPort
+		ports = append(ports, corev1.ServicePort{
+			Name:       "metrics",
+			Port:       metricsPort,
+			TargetPort: intstr.FromInt(int(metricsPort)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServerHeadlessServiceName(cacheServer),
+			Namespace: cacheServer.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
+			Ports:     ports,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, svc, r.Scheme)
+	return svc
+}
+
+// externalNameServiceForCacheServer returns the ExternalName Service resolving
+// to the external backend of the CacheServer
+func (r *CacheServerReconciler) externalNameServiceForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *corev1.Service {
+	var host string
+	if u, err := url.Parse(cacheServer.Spec.External.URL); err == nil {
+		host = u.Hostname()
+	}
+
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServer.Name,
+			Namespace: cacheServer.Namespace,
+			Labels:    map[string]string{"app": cacheServer.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: host,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, svc, r.Scheme)
+	return svc
+}
+
+// reconcileExternal deletes the resources of the lmcache server, except the
+// storage PersistentVolumeClaims, applies or deletes the ExternalName Service
+// and reports the endpoint of the external backend
+func (r *CacheServerReconciler) reconcileExternal(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer) error {
+	log := log.FromContext(ctx)
+
+	children := []struct {
+		kind string
+		name string
+		obj  client.Object
+	}{
+		{"Deployment", cacheServer.Name, &appsv1.Deployment{}},
+		{"StatefulSet", cacheServer.Name, &appsv1.StatefulSet{}},
+		{"Service", cacheServerHeadlessServiceName(cacheServer), &corev1.Service{}},
+		{"ConfigMap", cacheServerConfigMapName(cacheServer), &corev1.ConfigMap{}},
+		{"HorizontalPodAutoscaler", cacheServer.Name, &autoscalingv2.HorizontalPodAutoscaler{}},
+		{"PodDisruptionBudget", cacheServer.Name, &policyv1.PodDisruptionBudget{}},
+		{"NetworkPolicy", cacheServer.Name, &networkingv1.NetworkPolicy{}},
+	}
+	for _, child := range children {
+		if err := r.Get(ctx, types.NamespacedName{Name: child.name, Namespace: cacheServer.Namespace}, child.obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(child.obj, cacheServer) || child.obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		log.Info("Deleting "+child.kind+" of the external CacheServer", child.kind+".Namespace", cacheServer.Namespace, child.kind+".Name", child.name)
+		if err := r.Delete(ctx, child.obj); client.IgnoreNotFound(err) != nil {
+			r.Recorder.Eventf(cacheServer, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete %s %s: %v", child.kind, child.name, err)
+			return err
+		}
+		r.Recorder.Eventf(cacheServer, corev1.EventTypeNormal, "Deleted", "Deleted %s %s, the CacheServer uses an external backend", child.kind, child.name)
+	}
+	if _, err := reconcileServiceMonitor(ctx, r.Client, r.Scheme, cacheServer, productionstackv1alpha1.MonitoringSpec{}, cacheServer.Name, nil, "metrics"); err != nil {
+		return err
+	}
+
+	if cacheServer.Spec.External.Service {
+		svc := r.externalNameServiceForCacheServer(cacheServer)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, cacheServer, svc, cacheServerFieldOwner); err != nil {
+			return err
+		}
+	} else if err := r.deleteService(ctx, cacheServer, cacheServer.Name); err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the CacheServer
+		latestCS := &productionstackv1alpha1.CacheServer{}
+		if err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, latestCS); err != nil {
+			return err
+		}
+
+		endpoint := cacheServerEndpoint(cacheServer)
+		latestCS.Status.LastUpdated = metav1.Now()
+		latestCS.Status.ObservedGeneration = cacheServer.Generation
+		latestCS.Status.Status = "Ready"
+		latestCS.Status.ReadyReplicas = 0
+		latestCS.Status.Ready = ""
+		latestCS.Status.Selector = ""
+		latestCS.Status.DesiredReplicas = 0
+		latestCS.Status.Endpoint = endpoint
+
+		// Only the availability of the backend applies, which the operator
+		// does not probe
+		for _, conditionType := range []string{
+			productionstackv1alpha1.ConditionProgressing,
+			productionstackv1alpha1.ConditionDegraded,
+			productionstackv1alpha1.ConditionWorkloadReplaced,
+			productionstackv1alpha1.ConditionAutoscalingActive,
+			productionstackv1alpha1.ConditionStorageReady,
+			productionstackv1alpha1.ConditionConfigResolved,
+			productionstackv1alpha1.ConditionMonitoringCRDsMissing,
+			productionstackv1alpha1.ConditionSpecInvalid,
+		} {
+			meta.RemoveStatusCondition(&latestCS.Status.Conditions, conditionType)
+		}
+		meta.SetStatusCondition(&latestCS.Status.Conditions, metav1.Condition{
+			Type:               productionstackv1alpha1.ConditionAvailable,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ExternalBackend",
+			Message:            fmt.Sprintf("Using the external backend at %s", endpoint),
+			ObservedGeneration: cacheServer.Generation,
+		})
+		return r.Status().Update(ctx, latestCS)
+	})
+}
+
+// deleteService removes the Service of the CacheServer with the given name,
+// e.g. the headless Service after switching to the Deployment workloadType
+// or the ClusterIP Service on the host network
+func (r *CacheServerReconciler) deleteService(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer, name string) error {
+	svc := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cacheServer.Namespace}, svc)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(svc, cacheServer) {
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, svc))
+}
+
+// networkPolicyForCacheServer returns the NetworkPolicy admitting traffic to
+// the cache server port only from the pods of the VLLMRuntimes using the
+// CacheServer. The metrics port stays open to the scrapers.
+func (r *CacheServerReconciler) networkPolicyForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *networkingv1.NetworkPolicy {
+	httpPort := intstr.FromString("http")
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &httpPort}},
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					// Runtimes may reference the CacheServer from other namespaces
+					NamespaceSelector: &metav1.LabelSelector{},
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							productionstackv1alpha1.CacheServerLabel:          cacheServer.Name,
+							productionstackv1alpha1.CacheServerNamespaceLabel: cacheServer.Namespace,
+						},
+					},
+				},
+			},
+		},
+	}
+	if cacheServer.Spec.Monitoring.Enabled {
+		metricsPort := intstr.FromString("metrics")
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &metricsPort}},
+		})
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServer.Name,
+			Namespace: cacheServer.Namespace,
+			Labels:    map[string]string{"app": cacheServer.Name},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": cacheServer.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     ingress,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, policy, r.Scheme)
+	return policy
+}
+
+// deleteNetworkPolicy deletes the NetworkPolicy of the CacheServer, if it
+// owns it
+func (r *CacheServerReconciler) deleteNetworkPolicy(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer) error {
+	policy := &networkingv1.NetworkPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, policy)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(policy, cacheServer) {
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting NetworkPolicy", "NetworkPolicy.Namespace", policy.Namespace, "NetworkPolicy.Name", policy.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, policy))
+}
+
+// cacheServerConfigMapName returns the name of the ConfigMap rendered from an
+// inline or raw config
+func cacheServerConfigMapName(cacheServer *productionstackv1alpha1.CacheServer) string {
+	return cacheServer.Name + "-config"
+}
+
+// cacheServerConfigMapKey returns the ConfigMap and key holding the config
+// file of the CacheServer, or empty strings without config
+func cacheServerConfigMapKey(cacheServer *productionstackv1alpha1.CacheServer) (string, string) {
+	config := cacheServer.Spec.Config
+	switch {
+	case config == nil:
+		return "", ""
+	case config.ExistingConfigMap != nil:
+		return config.ExistingConfigMap.Name, config.ExistingConfigMap.Key
+	default:
+		return cacheServerConfigMapName(cacheServer), cacheServerConfigKey
+	}
+}
+
+// renderCacheServerConfig returns the config file of an inline or raw config.
+// Inline options are written one per line in key order.
+func renderCacheServerConfig(config *productionstackv1alpha1.CacheServerConfigSpec) string {
+	if config.Inline == nil {
+		return config.Raw
+	}
+	keys := make([]string, 0, len(config.Inline))
+	for k := range config.Inline {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, config.Inline[k])
+	}
+	return b.String()
+}
+
+// validateCacheServerConfig checks that content is a YAML mapping, with one
+// entry per inline option if the config is inline
+func validateCacheServerConfig(content string, inline map[string]string) error {
+	options := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(content), &options); err != nil {
+		return err
+	}
+	if inline != nil && len(options) != len(inline) {
+		return fmt.Errorf("the inline values render %d options instead of %d", len(options), len(inline))
+	}
+	return nil
+}
+
+// reconcileConfig renders the ConfigMap of an inline or raw config, or
+// resolves the existing ConfigMap, and returns the hash of the config file
+// along with a ConfigResolved condition. The rendered ConfigMap is deleted
+// when the config no longer uses it.
+func (r *CacheServerReconciler) reconcileConfig(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer) (string, *metav1.Condition, error) {
+	config := cacheServer.Spec.Config
+	if config == nil || config.ExistingConfigMap != nil {
+		if err := r.deleteConfigMap(ctx, cacheServer); err != nil {
+			return "", nil, err
+		}
+	}
+	if config == nil {
+		return "", nil, nil
+	}
+
+	var content string
+	if ref := config.ExistingConfigMap; ref != nil {
+		configMap := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: cacheServer.Namespace}, configMap)
+		if err != nil && errors.IsNotFound(err) {
+			return "", &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionConfigResolved,
+				Status:  metav1.ConditionFalse,
+				Reason:  "ConfigMapNotFound",
+				Message: fmt.Sprintf("ConfigMap %s not found", ref.Name),
+			}, nil
+		} else if err != nil {
+			return "", nil, err
+		}
+		var ok bool
+		if content, ok = configMap.Data[ref.Key]; !ok {
+			return "", &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionConfigResolved,
+				Status:  metav1.ConditionFalse,
+				Reason:  "KeyNotFound",
+				Message: fmt.Sprintf("ConfigMap %s has no key %s", ref.Name, ref.Key),
+			}, nil
+		}
+	} else {
+		content = renderCacheServerConfig(config)
+	}
+
+	if err := validateCacheServerConfig(content, config.Inline); err != nil {
+		return "", &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionConfigResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidConfig",
+			Message: fmt.Sprintf("The config is not a YAML mapping: %v", err),
+		}, nil
+	}
+
+	name, key := cacheServerConfigMapKey(cacheServer)
+	if config.ExistingConfigMap == nil {
+		configMap := r.configMapForCacheServer(cacheServer, content)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, cacheServer, configMap, cacheServerFieldOwner); err != nil {
+			return "", nil, err
+		}
+	}
+	return hashString(content), &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionConfigResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ConfigFound",
+		Message: fmt.Sprintf("Using config %s/%s", name, key),
+	}, nil
+}
+
+// configMapForCacheServer returns the ConfigMap holding the rendered config file
+func (r *CacheServerReconciler) configMapForCacheServer(cacheServer *productionstackv1alpha1.CacheServer, content string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServerConfigMapName(cacheServer),
+			Namespace: cacheServer.Namespace,
+			Labels:    map[string]string{"app": cacheServer.Name},
+		},
+		Data: map[string]string{cacheServerConfigKey: content},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, configMap, r.Scheme)
+	return configMap
+}
+
+// deleteConfigMap removes the rendered config ConfigMap after switching to an
+// existing ConfigMap or removing the config
+func (r *CacheServerReconciler) deleteConfigMap(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer) error {
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: cacheServerConfigMapName(cacheServer), Namespace: cacheServer.Namespace}, configMap)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(configMap, cacheServer) {
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting config ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, configMap))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CacheServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&productionstackv1alpha1.CacheServer{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findCacheServersForConfigMap),
+		)
+
+	// ServiceMonitors can only be watched when the Prometheus Operator CRDs are installed
+	installed, err := prometheusOperatorInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Owns(newServiceMonitor())
+	}
+
+	return b.Complete(r)
+}
+
+// findCacheServersForConfigMap maps a ConfigMap to the CacheServers using it
+// as config file, including the ConfigMaps rendered by the operator
+func (r *CacheServerReconciler) findCacheServersForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	cacheServers := &productionstackv1alpha1.CacheServerList{}
+	if err := r.List(ctx, cacheServers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list CacheServers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cs := range cacheServers.Items {
+		if name, _ := cacheServerConfigMapKey(&cs); name != obj.GetName() && !metav1.IsControlledBy(obj, &cs) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: cs.Name, Namespace: cs.Namespace},
+		})
+	}
+	return requests
+}
+
+// cacheServerExternal reports whether the CacheServer points at an external backend
+func cacheServerExternal(cacheServer *productionstackv1alpha1.CacheServer) bool {
+	return cacheServer.Spec.Mode == "external" && cacheServer.Spec.External != nil
+}
+
+// cacheServerStatefulSet reports whether the CacheServer runs as a StatefulSet
+func cacheServerStatefulSet(cacheServer *productionstackv1alpha1.CacheServer) bool {
+	return cacheServer.Spec.WorkloadType == "StatefulSet"
+}
+
+// cacheServerWorkloadKind returns the kind of the workload running the CacheServer
+func cacheServerWorkloadKind(cacheServer *productionstackv1alpha1.CacheServer) string {
+	if cacheServerStatefulSet(cacheServer) {
+		return "StatefulSet"
+	}
+	return "Deployment"
+}
+
+// cacheServerReplicas returns the replicas of a new workload. An autoscaled
+// cache server starts from its minimum.
+func cacheServerReplicas(cacheServer *productionstackv1alpha1.CacheServer) int32 {
+	if !cacheServer.Spec.Autoscaling.Enabled {
+		return cacheServer.Spec.Replicas
+	}
+	if cacheServer.Spec.Autoscaling.MinReplicas != nil {
+		return *cacheServer.Spec.Autoscaling.MinReplicas
+	}
+	return 1
+}
+
+// cacheServerAutoscalingSpec returns the HorizontalPodAutoscaler settings of
+// the CacheServer, scaling on CPU utilization only
+func cacheServerAutoscalingSpec(cacheServer *productionstackv1alpha1.CacheServer) productionstackv1alpha1.AutoscalingSpec {
+	autoscaling := cacheServer.Spec.Autoscaling
+	return productionstackv1alpha1.AutoscalingSpec{
+		Enabled:              autoscaling.Enabled,
+		MinReplicas:          autoscaling.MinReplicas,
+		MaxReplicas:          autoscaling.MaxReplicas,
+		TargetCPUUtilization: autoscaling.TargetCPUUtilization,
+	}
+}
+
+// cacheServerDNSPolicy returns the DNS policy of the CacheServer pods. Host
+// network pods resolve cluster names with ClusterFirstWithHostNet.
+func cacheServerDNSPolicy(cacheServer *productionstackv1alpha1.CacheServer) corev1.DNSPolicy {
+	switch {
+	case cacheServer.Spec.Network.DNSPolicy != "":
+		return cacheServer.Spec.Network.DNSPolicy
+	case cacheServer.Spec.Network.HostNetwork:
+		return corev1.DNSClusterFirstWithHostNet
+	default:
+		return corev1.DNSClusterFirst
+	}
+}
+
+// cacheServerServicePort returns the port exposed by the CacheServer Service
+func cacheServerServicePort(cacheServer *productionstackv1alpha1.CacheServer) int32 {
+	if cacheServer.Spec.ServicePort != 0 {
+		return cacheServer.Spec.ServicePort
+	}
+	return cacheServer.Spec.Port
+}
+
+// cacheServerStoragePVCName returns the PersistentVolumeClaim holding the
+// cache of a Deployment
+func cacheServerStoragePVCName(cacheServer *productionstackv1alpha1.CacheServer) string {
+	return cacheServer.Name + "-storage"
+}
+
+// cacheServerStoragePVCNames returns the PersistentVolumeClaims holding the
+// cache, one per replica of a StatefulSet
+func cacheServerStoragePVCNames(cacheServer *productionstackv1alpha1.CacheServer, replicas int32) []string {
+	if !cacheServerStatefulSet(cacheServer) {
+		return []string{cacheServerStoragePVCName(cacheServer)}
+	}
+	names := make([]string, 0, replicas)
+	for i := int32(0); i < replicas; i++ {
+		names = append(names, fmt.Sprintf("storage-%s-%d", cacheServer.Name, i))
+	}
+	return names
+}
+
+// cacheServerStorageMountPath returns where the cache is stored in the cache
+// server container
+func cacheServerStorageMountPath(cacheServer *productionstackv1alpha1.CacheServer) string {
+	if cacheServer.Spec.Storage.MountPath != "" {
+		return cacheServer.Spec.Storage.MountPath
+	}
+	return defaultCacheServerStorageMountPath
+}
+
+// cacheServerStorageSize returns the requested size of the PersistentVolumeClaim
+func cacheServerStorageSize(cacheServer *productionstackv1alpha1.CacheServer) resource.Quantity {
+	if cacheServer.Spec.Storage.Size != nil {
+		return *cacheServer.Spec.Storage.Size
+	}
+	return defaultCacheServerStorageSize
+}
+
+// reconcileStorage creates the PersistentVolumeClaim of a Deployment,
+// expands the claims when storage.size grows and reports the first claim
+// that is not ready in the StorageReady condition. The claims of a
+// StatefulSet are created with its pods. Claims are kept when storage is
+// disabled.
+func (r *CacheServerReconciler) reconcileStorage(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer) error {
+	if !cacheServer.Spec.Storage.Enabled {
+		return r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionStorageReady, nil)
+	}
+
+	// The replicas of an autoscaled StatefulSet are set by the
+	// HorizontalPodAutoscaler
+	replicas := cacheServerReplicas(cacheServer)
+	if cacheServerStatefulSet(cacheServer) && cacheServer.Spec.Autoscaling.Enabled {
+		sts := &appsv1.StatefulSet{}
+		err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, sts)
+		if err == nil && sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		} else if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	size := cacheServerStorageSize(cacheServer)
+	var condition *metav1.Condition
+	for _, name := range cacheServerStoragePVCNames(cacheServer, replicas) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cacheServer.Namespace}, pvc)
+		if err != nil && errors.IsNotFound(err) && cacheServerStatefulSet(cacheServer) {
+			if condition == nil || condition.Status == metav1.ConditionTrue {
+				condition = &metav1.Condition{
+					Type:    productionstackv1alpha1.ConditionStorageReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  "Pending",
+					Message: fmt.Sprintf("PersistentVolumeClaim %s is not created yet", name),
+				}
+			}
+			continue
+		} else if err != nil && errors.IsNotFound(err) {
+			pvc = r.storagePVCForCacheServer(cacheServer)
+			log.FromContext(ctx).Info("Creating a new PersistentVolumeClaim", "PersistentVolumeClaim.Namespace", pvc.Namespace, "PersistentVolumeClaim.Name", pvc.Name)
+			if err := createAndRecord(ctx, r.Client, r.Recorder, cacheServer, pvc); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		var resizeErr error
+		if size.Cmp(requested) > 0 {
+			log.FromContext(ctx).Info("Expanding PersistentVolumeClaim", "PersistentVolumeClaim.Namespace", pvc.Namespace, "PersistentVolumeClaim.Name", pvc.Name, "Size", size.String())
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			// The API server rejects the expansion when the storage class
+			// does not allow it, retrying would not help
+			if resizeErr = r.Patch(ctx, pvc, patch); resizeErr != nil && !errors.IsForbidden(resizeErr) && !errors.IsInvalid(resizeErr) {
+				return resizeErr
+			}
+		}
+
+		if condition == nil || condition.Status == metav1.ConditionTrue {
+			pvcCondition := storageReadyCondition(pvc, size, resizeErr)
+			condition = &pvcCondition
+		}
+	}
+	return r.reconcileCondition(ctx, cacheServer, productionstackv1alpha1.ConditionStorageReady, condition)
+}
+
+// storageReadyCondition returns the StorageReady condition of a
+// PersistentVolumeClaim requested with size. resizeErr is the error of a
+// rejected expansion.
+func storageReadyCondition(pvc *corev1.PersistentVolumeClaim, size resource.Quantity, resizeErr error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionStorageReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("PersistentVolumeClaim %s is not bound", pvc.Name),
+	}
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch {
+	case resizeErr != nil:
+		condition.Reason = "ResizeRejected"
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s cannot be expanded to %s: %v", pvc.Name, size.String(), resizeErr)
+	case size.Cmp(requested) < 0:
+		condition.Reason = "ResizeRejected"
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s cannot shrink from %s to %s", pvc.Name, requested.String(), size.String())
+	case pvc.Status.Phase == corev1.ClaimLost:
+		condition.Reason = "Lost"
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s lost its PersistentVolume", pvc.Name)
+	case pvc.Status.Phase != corev1.ClaimBound:
+		// Waiting for a PersistentVolume
+	case capacity.Cmp(requested) < 0:
+		condition.Reason = "Resizing"
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s is being expanded from %s to %s", pvc.Name, capacity.String(), requested.String())
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Bound"
+		condition.Message = fmt.Sprintf("PersistentVolumeClaim %s is bound with %s", pvc.Name, capacity.String())
+	}
+	return condition
+}
+
+// cacheServerStoragePVCSpec returns the spec of the PersistentVolumeClaims
+// holding the cache
+func cacheServerStoragePVCSpec(cacheServer *productionstackv1alpha1.CacheServer) corev1.PersistentVolumeClaimSpec {
+	spec := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: cacheServerStorageSize(cacheServer)},
+		},
+	}
+	if cacheServer.Spec.Storage.StorageClassName != "" {
+		spec.StorageClassName = &cacheServer.Spec.Storage.StorageClassName
+	}
+	return spec
+}
+
+// storagePVCForCacheServer returns the PersistentVolumeClaim created for the
+// cache of a Deployment
+func (r *CacheServerReconciler) storagePVCForCacheServer(cacheServer *productionstackv1alpha1.CacheServer) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cacheServerStoragePVCName(cacheServer),
+			Namespace: cacheServer.Namespace,
+			Labels:    map[string]string{"app": cacheServer.Name},
+		},
+		Spec: cacheServerStoragePVCSpec(cacheServer),
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(cacheServer, pvc, r.Scheme)
+	return pvc
+}
+
+// reconcileCondition records condition on the CacheServer status if it
+// changed. A nil condition removes any stale condition of that type.
+func (r *CacheServerReconciler) reconcileCondition(ctx context.Context, cacheServer *productionstackv1alpha1.CacheServer, conditionType string, condition *metav1.Condition) error {
+	if condition == nil && meta.FindStatusCondition(cacheServer.Status.Conditions, conditionType) == nil {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the CacheServer
+		latestCS := &productionstackv1alpha1.CacheServer{}
+		if err := r.Get(ctx, types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}, latestCS); err != nil {
+			return err
+		}
+
+		if condition == nil {
+			if !meta.RemoveStatusCondition(&latestCS.Status.Conditions, conditionType) {
+				return nil
+			}
+		} else {
+			condition.ObservedGeneration = latestCS.Generation
+			if !meta.SetStatusCondition(&latestCS.Status.Conditions, *condition) {
+				return nil
+			}
+		}
+		latestCS.Status.LastUpdated = metav1.Now()
+
+		return r.Status().Update(ctx, latestCS)
+	})
 }
@@ -21,8 +21,17 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -69,8 +78,9 @@ var _ = Describe("CacheServer Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &CacheServerReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -81,4 +91,1659 @@ var _ = Describe("CacheServer Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	It("should converge the Service and Deployment when the port changes", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "port-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(metav1.IsControlledBy(svc, cacheServer)).To(BeTrue())
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8000))
+		Expect(svc.Labels).To(HaveKeyWithValue("app", cacheServer.Name))
+		clusterIP := svc.Spec.ClusterIP
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+
+		By("changing the port")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Port = 8100
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(8100)))
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8100))
+		Expect(svc.Spec.ClusterIP).To(Equal(clusterIP))
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Command).To(ContainElement("8100"))
+		Expect(dep.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort).To(Equal(int32(8100)))
+
+		By("changing the service port")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.ServicePort = 80
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(80)))
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8100))
+		Expect(svc.Spec.ClusterIP).To(Equal(clusterIP))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("port-cache.default.svc:80"))
+	})
+
+	It("should roll out the deployment strategy", func() {
+		ctx := context.Background()
+		maxUnavailable := intstr.FromInt(0)
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "strategy-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				RollingUpdate:      &productionstackv1alpha1.RollingUpdateSpec{MaxUnavailable: &maxUnavailable},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		Expect(dep.Spec.Strategy.RollingUpdate.MaxSurge.String()).To(Equal("25%"))
+		Expect(dep.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue()).To(Equal(0))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+
+		By("switching to Recreate")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.DeploymentStrategy = "Recreate"
+		cacheServer.Spec.RollingUpdate = nil
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeTrue())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+		Expect(dep.Spec.Strategy.RollingUpdate).To(BeNil())
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+	})
+
+	It("should create, mount and expand the storage", func() {
+		ctx := context.Background()
+		size := resource.MustParse("20Gi")
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "storage-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Storage: productionstackv1alpha1.CacheServerStorageSpec{
+					Enabled:          true,
+					Size:             &size,
+					StorageClassName: "fast",
+					MountPath:        "/cache",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		pvcKey := types.NamespacedName{Name: "storage-cache-storage", Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, pvc)).To(Succeed())
+		})
+		Expect(metav1.IsControlledBy(pvc, cacheServer)).To(BeTrue())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		Expect(*pvc.Spec.StorageClassName).To(Equal("fast"))
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(Equal([]string{"lmcache_experimental_server", "0.0.0.0", "8000", "/cache"}))
+		Expect(container.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "storage", MountPath: "/cache"}))
+		Expect(dep.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		Expect(dep.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("storage-cache-storage"))
+
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionStorageReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("Pending"))
+
+		By("binding the PersistentVolumeClaim")
+		pvc.Status.Phase = corev1.ClaimBound
+		pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: size}
+		Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition = meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionStorageReady)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("Bound"))
+
+		By("growing the storage")
+		size = resource.MustParse("50Gi")
+		cacheServer.Spec.Storage.Size = &size
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition = meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionStorageReady)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("Resizing"))
+
+		By("disabling the storage")
+		cacheServer.Spec.Storage.Enabled = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionStorageReady)).To(BeNil())
+		Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Command).To(HaveLen(3))
+		Expect(dep.Spec.Template.Spec.Volumes).To(BeEmpty())
+	})
+
+	It("should run a StatefulSet claiming storage per replica", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sts-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           2,
+				DeploymentStrategy: "RollingUpdate",
+				WorkloadType:       "StatefulSet",
+				Storage: productionstackv1alpha1.CacheServerStorageSpec{
+					Enabled:   true,
+					MountPath: "/cache",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		headlessSvc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "sts-cache-pods", Namespace: "default"}, headlessSvc)).To(Succeed())
+		Expect(headlessSvc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(headlessSvc.Spec.Ports[0].Port).To(Equal(int32(8000)))
+
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		Expect(sts.Spec.ServiceName).To(Equal("sts-cache-pods"))
+		Expect(*sts.Spec.Replicas).To(Equal(int32(2)))
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Name).To(Equal("storage"))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+		Expect(sts.Spec.Template.Spec.Volumes).To(BeEmpty())
+		Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "storage", MountPath: "/cache"}))
+		Expect(controllerReconciler.statefulSetNeedsUpdate(sts, cacheServer, nil)).To(BeFalse())
+
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: "sts-cache-storage", Namespace: "default"}, &corev1.PersistentVolumeClaim{}))).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionStorageReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("Pending"))
+		Expect(condition.Message).To(ContainSubstring("storage-sts-cache-0"))
+	})
+
+	It("should leave the replicas to the HorizontalPodAutoscaler while autoscaling is enabled", func() {
+		ctx := context.Background()
+		minReplicas := int32(2)
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "autoscaled-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Autoscaling: productionstackv1alpha1.CacheServerAutoscalingSpec{
+					Enabled:     true,
+					MinReplicas: &minReplicas,
+					MaxReplicas: 4,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(k8sClient.Get(ctx, key, hpa)).To(Succeed())
+		Expect(hpa.Spec.ScaleTargetRef).To(Equal(autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "autoscaled-cache",
+		}))
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+		Expect(hpa.Spec.MaxReplicas).To(Equal(int32(4)))
+		Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(80)))
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+
+		By("scaling the Deployment as the HorizontalPodAutoscaler would")
+		dep.Spec.Replicas = &[]int32{3}[0]
+		Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.PodAnnotations = map[string]string{"example.com/team": "cache"}
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(dep.Spec.Template.Annotations).To(HaveKeyWithValue("example.com/team", "cache"))
+
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Selector).To(Equal("app=autoscaled-cache"))
+		Expect(cacheServer.Status.Ready).To(Equal("0/3"))
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionAutoscalingActive)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+
+		By("disabling autoscaling")
+		cacheServer.Spec.Autoscaling.Enabled = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(1)))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionAutoscalingActive)).To(BeNil())
+		Expect(cacheServer.Status.DesiredReplicas).To(BeZero())
+
+		By("enabling autoscaling again on a StatefulSet")
+		cacheServer.Spec.Autoscaling.Enabled = true
+		cacheServer.Spec.WorkloadType = "StatefulSet"
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		for range 3 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, key, hpa)).To(Succeed())
+		Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("StatefulSet"))
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		Expect(*sts.Spec.Replicas).To(Equal(int32(2)))
+		sts.Spec.Replicas = &[]int32{4}[0]
+		Expect(k8sClient.Update(ctx, sts)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		Expect(*sts.Spec.Replicas).To(Equal(int32(4)))
+	})
+
+	It("should manage the PodDisruptionBudget of the cache server", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pdb-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:               productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:                8000,
+				Replicas:            2,
+				DeploymentStrategy:  "RollingUpdate",
+				PodDisruptionBudget: productionstackv1alpha1.PodDisruptionBudgetSpec{Enabled: true},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(20)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+		Expect(metav1.IsControlledBy(pdb, cacheServer)).To(BeTrue())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": cacheServer.Name}))
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(drainEvents(recorder)).NotTo(ContainElement(ContainSubstring("PodDisruptionBudgetSingleReplica")))
+
+		By("switching to minAvailable on a single replica")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		minAvailable := intstr.FromInt32(1)
+		cacheServer.Spec.PodDisruptionBudget.MinAvailable = &minAvailable
+		cacheServer.Spec.Replicas = 1
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(1))
+		Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+		Expect(drainEvents(recorder)).To(ContainElement(HavePrefix("Warning PodDisruptionBudgetSingleReplica")))
+
+		By("disabling the PodDisruptionBudget")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.PodDisruptionBudget.Enabled = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, pdb))).To(BeTrue())
+		Expect(drainEvents(recorder)).NotTo(ContainElement(ContainSubstring("PodDisruptionBudgetSingleReplica")))
+	})
+
+	It("should admit only the runtimes of the cache server with the NetworkPolicy", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "policy-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				NetworkPolicy:      productionstackv1alpha1.CacheServerNetworkPolicySpec{Enabled: true},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(20),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		policy := &networkingv1.NetworkPolicy{}
+		Expect(k8sClient.Get(ctx, key, policy)).To(Succeed())
+		Expect(metav1.IsControlledBy(policy, cacheServer)).To(BeTrue())
+		Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app": cacheServer.Name}))
+		Expect(policy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+		Expect(policy.Spec.Ingress).To(HaveLen(1))
+		Expect(policy.Spec.Ingress[0].Ports).To(HaveLen(1))
+		Expect(*policy.Spec.Ingress[0].Ports[0].Port).To(Equal(intstr.FromString("http")))
+		Expect(policy.Spec.Ingress[0].From).To(HaveLen(1))
+		Expect(policy.Spec.Ingress[0].From[0].NamespaceSelector).To(Equal(&metav1.LabelSelector{}))
+		Expect(policy.Spec.Ingress[0].From[0].PodSelector.MatchLabels).To(Equal(map[string]string{
+			productionstackv1alpha1.CacheServerLabel:          cacheServer.Name,
+			productionstackv1alpha1.CacheServerNamespaceLabel: cacheServer.Namespace,
+		}))
+
+		By("opening the metrics port to the scrapers")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Monitoring = productionstackv1alpha1.CacheServerMonitoringSpec{
+			MonitoringSpec: productionstackv1alpha1.MonitoringSpec{Enabled: true},
+			MetricsPort:    9100,
+		}
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, policy)).To(Succeed())
+		Expect(policy.Spec.Ingress).To(HaveLen(2))
+		Expect(policy.Spec.Ingress[1].Ports).To(HaveLen(1))
+		Expect(*policy.Spec.Ingress[1].Ports[0].Port).To(Equal(intstr.FromString("metrics")))
+		Expect(policy.Spec.Ingress[1].From).To(BeEmpty())
+
+		By("disabling the NetworkPolicy")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.NetworkPolicy.Enabled = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, policy))).To(BeTrue())
+	})
+
+	It("should replace the workload when the workloadType changes", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "switch-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				WorkloadType:       "Deployment",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		headlessKey := types.NamespacedName{Name: "switch-cache-pods", Namespace: "default"}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+		By("switching to a StatefulSet")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.WorkloadType = "StatefulSet"
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.StatefulSet{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionWorkloadReplaced)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("DeletingDeployment"))
+
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &appsv1.StatefulSet{})).To(Succeed())
+		Expect(k8sClient.Get(ctx, headlessKey, &corev1.Service{})).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionWorkloadReplaced)).To(BeNil())
+
+		By("switching back to a Deployment")
+		cacheServer.Spec.WorkloadType = "Deployment"
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.StatefulSet{}))).To(BeTrue())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, headlessKey, &corev1.Service{}))).To(BeTrue())
+
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should publish the endpoint of an external backend and clean up the lmcache server", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "external-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(20)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		drainEvents(recorder)
+
+		By("switching to an external backend behind an ExternalName Service")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Mode = "external"
+		cacheServer.Spec.External = &productionstackv1alpha1.CacheServerExternalSpec{URL: "redis://valkey.cache.svc.cluster.local", Service: true}
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(drainEvents(recorder)).To(ContainElement("Normal Deleted Deleted Deployment external-cache, the CacheServer uses an external backend"))
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+		Expect(svc.Spec.ExternalName).To(Equal("valkey.cache.svc.cluster.local"))
+		Expect(svc.Spec.ClusterIP).To(BeEmpty())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Status).To(Equal("Ready"))
+		Expect(cacheServer.Status.Endpoint).To(Equal("external-cache.default.svc:6379"))
+		Expect(cacheServerRemoteURL(cacheServer)).To(Equal("redis://external-cache.default.svc:6379"))
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionAvailable)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("ExternalBackend"))
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionProgressing)).To(BeNil())
+
+		By("connecting to the backend directly")
+		cacheServer.Spec.External.Service = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("valkey.cache.svc.cluster.local"))
+		Expect(cacheServerRemoteURL(cacheServer)).To(Equal("redis://valkey.cache.svc.cluster.local"))
+
+		By("switching back to the lmcache server")
+		cacheServer.Spec.Mode = "lmcache"
+		cacheServer.Spec.External = nil
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		for range 2 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("external-cache.default.svc:8000"))
+	})
+
+	It("should report the endpoint, ready replicas and conditions", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "status-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           2,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("status-cache.default.svc:8000"))
+		Expect(cacheServer.Status.Ready).To(Equal("0/2"))
+		Expect(cacheServer.Status.ObservedGeneration).To(Equal(cacheServer.Generation))
+		Expect(meta.IsStatusConditionFalse(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionAvailable)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionDegraded)).To(BeTrue())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionProgressing)).NotTo(BeNil())
+	})
+
+	It("should record events for the created and updated resources", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "events-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:v0.3.0"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainEvents(recorder)).To(ConsistOf(
+			"Normal Created Created Service events-cache",
+			"Normal Created Created Deployment events-cache",
+		))
+
+		// An unchanged spec records nothing
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainEvents(recorder)).To(BeEmpty())
+
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Image.Name = "lmcache/vllm-openai:v0.3.1"
+		cacheServer.Spec.Replicas = 2
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainEvents(recorder)).To(ConsistOf(
+			"Normal Updated Updated Deployment events-cache: replicas changed: 1→2; " +
+				"image changed: docker.io/lmcache/vllm-openai:v0.3.0→docker.io/lmcache/vllm-openai:v0.3.1",
+		))
+
+		By("failing to update a stale Deployment")
+		stale := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, stale)).To(Succeed())
+		stale.ResourceVersion = "1"
+		Expect(updateAndRecord(ctx, k8sClient, recorder, cacheServer, stale.DeepCopy(), stale)).NotTo(Succeed())
+		Expect(drainEvents(recorder)).To(ConsistOf(HavePrefix("Warning UpdateFailed Failed to update Deployment events-cache")))
+	})
+
+	It("should use the pull secret and report image pull failures", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "private-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image: productionstackv1alpha1.ImageSpec{
+					Registry:       "registry.example.com",
+					Name:           "lmcache/vllm-openai:latest",
+					PullSecretName: "registry-credentials",
+				},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "registry-credentials"}}))
+
+		By("failing to pull the image")
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "private-cache-0",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "private-cache"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "cache-server", Image: "registry.example.com/lmcache/vllm-openai:latest"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+		})
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "cache-server",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason:  "ImagePullBackOff",
+				Message: "Back-off pulling image",
+			}},
+		}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(degradedResyncPeriod))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionDegraded)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("ImagePullBackOff"))
+		Expect(condition.Message).To(ContainSubstring("private-cache-0"))
+		Expect(cacheServer.Status.Status).To(Equal("NotReady"))
+		Expect(drainEvents(recorder)).To(ContainElement(HavePrefix("Warning ImagePullBackOff")))
+
+		// The failure is reported once
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainEvents(recorder)).To(BeEmpty())
+	})
+
+	DescribeTable("When mapping the StatefulSet status to conditions",
+		func(status appsv1.StatefulSetStatus, expected map[string]metav1.ConditionStatus) {
+			sts := &appsv1.StatefulSet{}
+			sts.Generation = 2
+			sts.Status = status
+			statuses := map[string]metav1.ConditionStatus{}
+			for _, condition := range statefulSetWorkloadStatus(sts).conditions {
+				statuses[condition.Type] = condition.Status
+			}
+			Expect(statuses).To(Equal(expected))
+		},
+		Entry("a StatefulSet not observed yet", appsv1.StatefulSetStatus{ObservedGeneration: 1}, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionTrue, "Degraded": metav1.ConditionFalse,
+		}),
+		Entry("a rolling update", appsv1.StatefulSetStatus{
+			ObservedGeneration: 2, Replicas: 2, AvailableReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "b",
+		}, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionTrue, "Progressing": metav1.ConditionTrue, "Degraded": metav1.ConditionFalse,
+		}),
+		Entry("a completed rollout", appsv1.StatefulSetStatus{
+			ObservedGeneration: 2, Replicas: 2, AvailableReplicas: 2, UpdatedReplicas: 2, CurrentRevision: "b", UpdateRevision: "b",
+		}, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionTrue, "Progressing": metav1.ConditionFalse, "Degraded": metav1.ConditionFalse,
+		}),
+	)
+
+	It("should render the inline config and roll the pods when it changes", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "inline-config-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Config: &productionstackv1alpha1.CacheServerConfigSpec{
+					Inline: map[string]string{"max_local_cpu_size": "20", "local_cpu": "true"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		configKey := types.NamespacedName{Name: "inline-config-cache-config", Namespace: "default"}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, configKey, configMap)).To(Succeed())
+		Expect(configMap.Data).To(Equal(map[string]string{"lmcache.yaml": "local_cpu: true\nmax_local_cpu_size: 20\n"}))
+		Expect(controllerReconciler.findCacheServersForConfigMap(ctx, configMap)).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		Expect(dep.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal(configKey.Name))
+		Expect(dep.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+			Name: "config", MountPath: "/etc/lmcache", ReadOnly: true,
+		}))
+		Expect(dep.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: "LMCACHE_CONFIG_FILE", Value: "/etc/lmcache/lmcache.yaml",
+		}))
+		firstHash := dep.Spec.Template.Annotations[cacheServerConfigHashAnnotation]
+		Expect(firstHash).NotTo(BeEmpty())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionConfigResolved)).To(BeTrue())
+
+		By("changing an option")
+		cacheServer.Spec.Config.Inline["max_local_cpu_size"] = "40"
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, configKey, configMap)).To(Succeed())
+		Expect(configMap.Data["lmcache.yaml"]).To(ContainSubstring("max_local_cpu_size: 40"))
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Annotations[cacheServerConfigHashAnnotation]).NotTo(Equal(firstHash))
+
+		By("removing the config")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Config = nil
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, configKey, configMap))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Volumes).To(BeEmpty())
+		Expect(dep.Spec.Template.Annotations).NotTo(HaveKey(cacheServerConfigHashAnnotation))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionConfigResolved)).To(BeNil())
+	})
+
+	It("should wait for the existing config and roll the pods when it is edited", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "existing-config-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Config: &productionstackv1alpha1.CacheServerConfigSpec{
+					ExistingConfigMap: &productionstackv1alpha1.ConfigMapKeyReference{Name: "cache-config", Key: "server.yaml"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionConfigResolved)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ConfigMapNotFound"))
+		Expect(controllerReconciler.findCacheServersForConfigMap(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache-config", Namespace: "default"},
+		})).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+
+		By("creating an invalid ConfigMap")
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache-config", Namespace: "default"},
+			Data:       map[string]string{"server.yaml": "- local_cpu\n"},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+		})
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition = meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionConfigResolved)
+		Expect(condition.Reason).To(Equal("InvalidConfig"))
+
+		By("fixing the ConfigMap")
+		configMap.Data["server.yaml"] = "local_cpu: true\n"
+		Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal("cache-config"))
+		Expect(dep.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: "LMCACHE_CONFIG_FILE", Value: "/etc/lmcache/server.yaml",
+		}))
+		firstHash := dep.Spec.Template.Annotations[cacheServerConfigHashAnnotation]
+		Expect(firstHash).NotTo(BeEmpty())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionConfigResolved)).To(BeTrue())
+
+		By("editing the ConfigMap")
+		configMap.Data["server.yaml"] = "local_cpu: false\n"
+		Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Annotations[cacheServerConfigHashAnnotation]).NotTo(Equal(firstHash))
+	})
+
+	DescribeTable("validating the rendered config",
+		func(config productionstackv1alpha1.CacheServerConfigSpec, valid bool) {
+			err := validateCacheServerConfig(renderCacheServerConfig(&config), config.Inline)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("inline options", productionstackv1alpha1.CacheServerConfigSpec{
+			Inline: map[string]string{"chunk_size": "256", "remote_url": "lm://cache:8000"},
+		}, true),
+		Entry("an inline value adding an option", productionstackv1alpha1.CacheServerConfigSpec{
+			Inline: map[string]string{"chunk_size": "256\nlocal_cpu: true"},
+		}, false),
+		Entry("a raw mapping", productionstackv1alpha1.CacheServerConfigSpec{
+			Raw: "chunk_size: 256\nextra_config:\n  save_decode_cache: true\n",
+		}, true),
+		Entry("a raw list", productionstackv1alpha1.CacheServerConfigSpec{Raw: "- chunk_size\n"}, false),
+		Entry("raw invalid YAML", productionstackv1alpha1.CacheServerConfigSpec{Raw: "chunk_size: [256\n"}, false),
+	)
+
+	It("should pass the server options to the container", func() {
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "options-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:    productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:     8000,
+				Replicas: 1,
+				Resources: productionstackv1alpha1.CacheServerResources{
+					ResourceRequirements: productionstackv1alpha1.ResourceRequirements{GPU: "1"},
+				},
+				Serde:     "cachegen",
+				Device:    "cuda",
+				ChunkSize: 512,
+				ExtraArgs: []string{"--verbose"},
+			},
+		}
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		container := controllerReconciler.deploymentForCacheServer(cacheServer, nil).Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(Equal([]string{"lmcache_experimental_server", "0.0.0.0", "8000", "cuda", "--verbose"}))
+		Expect(container.Env).To(ConsistOf(
+			corev1.EnvVar{Name: "LMCACHE_REMOTE_SERDE", Value: "cachegen"},
+			corev1.EnvVar{Name: "LMCACHE_CHUNK_SIZE", Value: "512"},
+		))
+		Expect(container.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceName("nvidia.com/gpu"), resource.MustParse("1")))
+		Expect(container.Resources.Limits).To(HaveKeyWithValue(corev1.ResourceName("nvidia.com/gpu"), resource.MustParse("1")))
+	})
+
+	It("should render the host network and the extra resources on the pod spec", func() {
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rdma-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8100,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Resources: productionstackv1alpha1.CacheServerResources{
+					ResourceRequirements: productionstackv1alpha1.ResourceRequirements{CPU: "4"},
+					Extra:                map[string]string{"rdma/hca": "1"},
+				},
+				Network: productionstackv1alpha1.CacheServerNetworkSpec{HostNetwork: true},
+			},
+		}
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		dep := controllerReconciler.deploymentForCacheServer(cacheServer, nil)
+		podSpec := dep.Spec.Template.Spec
+		Expect(podSpec.HostNetwork).To(BeTrue())
+		Expect(podSpec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+		Expect(podSpec.Containers[0].Ports).To(ConsistOf(corev1.ContainerPort{
+			Name: "http", ContainerPort: 8100, HostPort: 8100, Protocol: corev1.ProtocolTCP,
+		}))
+		Expect(podSpec.Containers[0].Resources.Requests).To(HaveKeyWithValue(corev1.ResourceName("rdma/hca"), resource.MustParse("1")))
+		Expect(podSpec.Containers[0].Resources.Limits).To(HaveKeyWithValue(corev1.ResourceName("rdma/hca"), resource.MustParse("1")))
+		Expect(cacheServerEndpoint(cacheServer)).To(Equal("rdma-cache-pods.default.svc:8100"))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+
+		By("overriding the DNS policy")
+		cacheServer.Spec.Network.DNSPolicy = corev1.DNSDefault
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeTrue())
+		Expect(controllerReconciler.deploymentForCacheServer(cacheServer, nil).Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSDefault))
+
+		By("leaving the host network")
+		cacheServer.Spec.Network = productionstackv1alpha1.CacheServerNetworkSpec{}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeTrue())
+		podSpec = controllerReconciler.deploymentForCacheServer(cacheServer, nil).Spec.Template.Spec
+		Expect(podSpec.HostNetwork).To(BeFalse())
+		Expect(podSpec.DNSPolicy).To(Equal(corev1.DNSClusterFirst))
+		Expect(podSpec.Containers[0].Ports[0].HostPort).To(BeZero())
+
+		By("changing the extra resources")
+		cacheServer.Spec.Network = productionstackv1alpha1.CacheServerNetworkSpec{HostNetwork: true}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+		cacheServer.Spec.Resources.Extra["rdma/hca"] = "2"
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeTrue())
+	})
+
+	It("should serve the host network pods through the headless Service", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "host-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8100,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		headlessKey := types.NamespacedName{Name: "host-cache-pods", Namespace: "default"}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, &corev1.Service{})).To(Succeed())
+
+		By("moving to the host network")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Network.HostNetwork = true
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		for range 2 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+		headlessSvc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, headlessKey, headlessSvc)).To(Succeed())
+		Expect(headlessSvc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(headlessSvc.Spec.Ports[0].Port).To(Equal(int32(8100)))
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.HostNetwork).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("host-cache-pods.default.svc:8100"))
+
+		By("leaving the host network")
+		cacheServer.Spec.Network.HostNetwork = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		for range 2 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, key, &corev1.Service{})).To(Succeed())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, headlessKey, &corev1.Service{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(cacheServer.Status.Endpoint).To(Equal("host-cache.default.svc:8100"))
+	})
+
+	It("should expose the metrics and report the missing monitoring CRDs", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "monitored-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Monitoring: productionstackv1alpha1.CacheServerMonitoringSpec{
+					MonitoringSpec: productionstackv1alpha1.MonitoringSpec{Enabled: true},
+					MetricsPort:    9100,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports).To(HaveLen(2))
+		Expect(svc.Spec.Ports[1].Name).To(Equal("metrics"))
+		Expect(svc.Spec.Ports[1].Port).To(Equal(int32(9100)))
+		Expect(svc.Spec.Ports[1].TargetPort.IntValue()).To(Equal(9100))
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.Ports).To(ContainElement(corev1.ContainerPort{Name: "metrics", ContainerPort: 9100, Protocol: corev1.ProtocolTCP}))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionMonitoringCRDsMissing)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+		By("disabling monitoring")
+		cacheServer.Spec.Monitoring.Enabled = false
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Ports).To(HaveLen(1))
+		Expect(dep.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionMonitoringCRDsMissing)).To(BeNil())
+	})
+
+	It("should pass env and envFrom to the container, keeping the operator variables", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "env-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				ChunkSize:          256,
+				Env: []productionstackv1alpha1.EnvVar{
+					{Name: "LMCACHE_LOG_LEVEL", Value: "DEBUG"},
+					{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{{
+					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "lmcache-env"}},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(Equal([]corev1.EnvVar{
+			{Name: "LMCACHE_CHUNK_SIZE", Value: "256"},
+			{Name: "LMCACHE_LOG_LEVEL", Value: "DEBUG"},
+			{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.name"}}},
+		}))
+		Expect(container.EnvFrom).To(Equal(cacheServer.Spec.EnvFrom))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+		Expect(drainEvents(recorder)).NotTo(ContainElement(ContainSubstring("EnvOverridden")))
+
+		By("overriding a variable set by the operator")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Env[0] = productionstackv1alpha1.EnvVar{Name: "LMCACHE_CHUNK_SIZE", Value: "1024"}
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		env := dep.Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(HaveLen(2))
+		Expect(env[0]).To(Equal(corev1.EnvVar{Name: "LMCACHE_CHUNK_SIZE", Value: "256"}))
+		Expect(env[1].Name).To(Equal("POD_NAME"))
+		Expect(drainEvents(recorder)).To(ContainElement(ContainSubstring("Warning EnvOverridden spec.env defines LMCACHE_CHUNK_SIZE")))
+	})
+
+	It("should merge pod labels and annotations into the pod template", func() {
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "labeled-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:          productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:           8000,
+				Replicas:       1,
+				PodLabels:      map[string]string{"team": "inference"},
+				PodAnnotations: map[string]string{"sidecar.istio.io/inject": "false", cacheServerConfigHashAnnotation: "ignored"},
+			},
+		}
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		inputs := &cacheServerInputs{annotations: map[string]string{cacheServerConfigHashAnnotation: "abc"}}
+		dep := controllerReconciler.deploymentForCacheServer(cacheServer, inputs)
+		Expect(dep.Spec.Template.Labels).To(Equal(map[string]string{"app": "labeled-cache", "team": "inference"}))
+		Expect(dep.Spec.Template.Annotations).To(Equal(map[string]string{"sidecar.istio.io/inject": "false", cacheServerConfigHashAnnotation: "abc"}))
+		Expect(dep.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "labeled-cache"}))
+		Expect(controllerReconciler.serviceForCacheServer(cacheServer).Spec.Selector).To(Equal(dep.Spec.Selector.MatchLabels))
+
+		By("comparing against the live Deployment")
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, inputs)).To(BeFalse())
+		cacheServer.Spec.PodLabels["team"] = "platform"
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, inputs)).To(BeTrue())
+		cacheServer.Spec.PodLabels["team"] = "inference"
+		cacheServer.Spec.PodAnnotations = nil
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, inputs)).To(BeTrue())
+	})
+
+	It("should render the security contexts on the pod spec", func() {
+		ctx := context.Background()
+		runAsNonRoot := true
+		runAsUser := int64(1000)
+		fsGroup := int64(1000)
+		allowPrivilegeEscalation := false
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restricted-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot:             &runAsNonRoot,
+					RunAsUser:                &runAsUser,
+					AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+					SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				},
+				PodSecurityContext: &corev1.PodSecurityContext{FSGroup: &fsGroup},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.SecurityContext).To(Equal(cacheServer.Spec.PodSecurityContext))
+		Expect(dep.Spec.Template.Spec.Containers[0].SecurityContext).To(Equal(cacheServer.Spec.SecurityContext))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+
+		By("removing the security contexts")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.SecurityContext = nil
+		cacheServer.Spec.PodSecurityContext = nil
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.SecurityContext).To(Equal(&corev1.PodSecurityContext{}))
+		Expect(dep.Spec.Template.Spec.Containers[0].SecurityContext).To(BeNil())
+	})
+
+	It("should render the termination on the pod spec and report its changes", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "termination-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Termination: productionstackv1alpha1.CacheServerTerminationSpec{
+					GracePeriodSeconds: 300,
+					PreStopCommand:     []string{"sleep", "30"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(20)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(300)))
+		Expect(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "30"}))
+		drainEvents(recorder)
+
+		By("calling a flush endpoint instead")
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		cacheServer.Spec.Termination = productionstackv1alpha1.CacheServerTerminationSpec{
+			GracePeriodSeconds: 600,
+			PreStopHTTPGet:     &productionstackv1alpha1.CacheServerPreStopHTTPGet{Path: "/flush"},
+		}
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(600)))
+		Expect(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.HTTPGet).To(Equal(&corev1.HTTPGetAction{
+			Path:   "/flush",
+			Port:   intstr.FromInt32(8000),
+			Scheme: corev1.URISchemeHTTP,
+		}))
+		Expect(drainEvents(recorder)).To(ContainElement(
+			"Normal Updated Updated Deployment termination-cache: terminationGracePeriodSeconds changed: 300→600; lifecycle changed"))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(BeFalse())
+	})
+
+	It("should not roll out unparsable resource quantities", func() {
+		ctx := context.Background()
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid-quantity-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:               8000,
+				Replicas:           1,
+				DeploymentStrategy: "RollingUpdate",
+				Resources: productionstackv1alpha1.CacheServerResources{
+					ResourceRequirements: productionstackv1alpha1.ResourceRequirements{CPU: "two", Memory: "8Gi"},
+					Extra:                map[string]string{"rdma/hca": "one"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cacheServer)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cacheServer)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: cacheServer.Name, Namespace: cacheServer.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		condition := meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionSpecInvalid)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("InvalidQuantity"))
+		Expect(condition.Message).To(HavePrefix(`resources.cpu "two"`))
+		Expect(condition.Message).To(ContainSubstring(`resources.extra[rdma/hca] "one"`))
+		Expect(drainEvents(recorder)).To(HaveLen(1))
+
+		By("fixing the quantities")
+		cacheServer.Spec.Resources.CPU = "2"
+		cacheServer.Spec.Resources.Extra["rdma/hca"] = "1"
+		Expect(k8sClient.Update(ctx, cacheServer)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Resources.Limits).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("2")))
+		Expect(k8sClient.Get(ctx, key, cacheServer)).To(Succeed())
+		Expect(meta.FindStatusCondition(cacheServer.Status.Conditions, productionstackv1alpha1.ConditionSpecInvalid)).To(BeNil())
+	})
+
+	It("should place the pods on the selected nodes and near the runtime", func() {
+		nodeAffinity := &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "node.kubernetes.io/instance-type",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"g5.xlarge"},
+					}},
+				}},
+			},
+		}
+		cacheServer := &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "placed-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:        productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:         8000,
+				Replicas:     1,
+				NodeSelector: map[string]string{"nvidia.com/gpu.present": "true"},
+				Tolerations: []corev1.Toleration{{
+					Key:      "nvidia.com/gpu",
+					Operator: corev1.TolerationOpExists,
+					Effect:   corev1.TaintEffectNoSchedule,
+				}},
+				Affinity: &corev1.Affinity{NodeAffinity: nodeAffinity},
+			},
+		}
+		controllerReconciler := &CacheServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		podSpec := controllerReconciler.deploymentForCacheServer(cacheServer, nil).Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(cacheServer.Spec.NodeSelector))
+		Expect(podSpec.Tolerations).To(Equal(cacheServer.Spec.Tolerations))
+		Expect(podSpec.Affinity).To(Equal(&corev1.Affinity{NodeAffinity: nodeAffinity}))
+
+		By("colocating with a runtime")
+		cacheServer.Spec.ColocateWithRuntime = &productionstackv1alpha1.VLLMRuntimeReference{Name: "llama"}
+		podSpec = controllerReconciler.deploymentForCacheServer(cacheServer, nil).Spec.Template.Spec
+		Expect(podSpec.Affinity.NodeAffinity).To(Equal(nodeAffinity))
+		Expect(podSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(Equal([]corev1.WeightedPodAffinityTerm{{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "llama"}},
+				TopologyKey:   corev1.LabelHostname,
+			},
+		}}))
+		Expect(cacheServer.Spec.Affinity.PodAffinity).To(BeNil())
+	})
+
+	DescribeTable("When the live Deployment drifts",
+		func(mutate func(*appsv1.Deployment), expected bool) {
+			cacheServer := &productionstackv1alpha1.CacheServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "drift-cache",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.CacheServerSpec{
+					Image: productionstackv1alpha1.ImageSpec{
+						Registry:   "docker.io",
+						Name:       "lmcache/vllm-openai:latest",
+						PullPolicy: "Always",
+					},
+					Port:     8000,
+					Replicas: 1,
+				},
+			}
+			controllerReconciler := &CacheServerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			dep := controllerReconciler.deploymentForCacheServer(cacheServer, nil)
+			mutate(dep)
+			Expect(controllerReconciler.deploymentNeedsUpdate(dep, cacheServer, nil)).To(Equal(expected))
+		},
+		Entry("nothing", func(dep *appsv1.Deployment) {}, false),
+		Entry("image", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Image = "docker.io/lmcache/vllm-openai:v0.1.0"
+		}, true),
+		Entry("imagePullPolicy", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+		}, true),
+		Entry("imagePullSecrets", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+		}, true),
+		Entry("securityContext", func(dep *appsv1.Deployment) {
+			runAsNonRoot := true
+			dep.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}
+		}, true),
+		Entry("nodeSelector", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.NodeSelector = map[string]string{"nvidia.com/gpu.present": "true"}
+		}, true),
+		Entry("tolerations", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}}
+		}, true),
+		Entry("affinity", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+		}, true),
+		Entry("containerPort", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort = 8100
+		}, true),
+		Entry("command", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Command[2] = "8100"
+		}, true),
+		Entry("GPU request", func(dep *appsv1.Deployment) {
+			gpu := corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+			dep.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{Requests: gpu, Limits: gpu}
+		}, true),
+		Entry("envFrom", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "lmcache-env"}},
+			}}
+		}, true),
+		Entry("env", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "LMCACHE_CHUNK_SIZE", Value: "256"}}
+		}, true),
+		Entry("terminationGracePeriodSeconds", func(dep *appsv1.Deployment) {
+			gracePeriod := int64(30)
+			dep.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		}, true),
+		Entry("preStop hook", func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"sleep", "5"}}},
+			}
+		}, true),
+		Entry("fields defaulted by the API server", func(dep *appsv1.Deployment) {
+			container := &dep.Spec.Template.Spec.Containers[0]
+			container.Ports[0].Protocol = corev1.ProtocolTCP
+			container.Resources = corev1.ResourceRequirements{}
+			container.TerminationMessagePath = corev1.TerminationMessagePathDefault
+			container.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+			dep.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+			dep.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+		}, false),
+	)
 })
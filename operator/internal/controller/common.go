@@ -0,0 +1,294 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// annotationPrefix is the prefix of annotations managed by the operator
+const annotationPrefix = "production-stack.vllm.ai/"
+
+// hashString returns the hex encoded SHA-256 of s
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// deploymentStrategy returns the Deployment strategy of the given type,
+// RollingUpdate or Recreate. The surge parameters default to 25% like in the
+// API server, so that the live Deployment compares equal.
+func deploymentStrategy(strategyType string, rollingUpdate *productionstackv1alpha1.RollingUpdateSpec) appsv1.DeploymentStrategy {
+	if strategyType == string(appsv1.RecreateDeploymentStrategyType) {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	maxSurge := intstr.FromString("25%")
+	maxUnavailable := intstr.FromString("25%")
+	if rollingUpdate != nil {
+		if rollingUpdate.MaxSurge != nil {
+			maxSurge = *rollingUpdate.MaxSurge
+		}
+		if rollingUpdate.MaxUnavailable != nil {
+			maxUnavailable = *rollingUpdate.MaxUnavailable
+		}
+	}
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// deploymentStatusConditions maps the Deployment conditions to the
+// Available, Progressing and Degraded conditions of the component running in
+// it. A Deployment not created yet has no conditions.
+func deploymentStatusConditions(dep *appsv1.Deployment, component string) []metav1.Condition {
+	available := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionAvailable,
+		Status:  metav1.ConditionFalse,
+		Reason:  "DeploymentNotReady",
+		Message: fmt.Sprintf("The %s Deployment has not reported availability yet", component),
+	}
+	progressing := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionProgressing,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "DeploymentNotReady",
+		Message: fmt.Sprintf("The %s Deployment has not reported progress yet", component),
+	}
+	degraded := metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: fmt.Sprintf("The %s Deployment reports no failure", component),
+	}
+
+	for _, c := range dep.Status.Conditions {
+		switch {
+		case c.Type == appsv1.DeploymentAvailable:
+			available.Status = metav1.ConditionStatus(c.Status)
+			available.Reason = c.Reason
+			available.Message = c.Message
+		case c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse:
+			// The rollout exceeded its progress deadline
+			progressing.Status = metav1.ConditionFalse
+			progressing.Reason = c.Reason
+			progressing.Message = c.Message
+			degraded.Status = metav1.ConditionTrue
+			degraded.Reason = c.Reason
+			degraded.Message = c.Message
+		case c.Type == appsv1.DeploymentProgressing:
+			// NewReplicaSetAvailable means the last rollout completed
+			progressing.Status = metav1.ConditionTrue
+			if c.Reason == "NewReplicaSetAvailable" {
+				progressing.Status = metav1.ConditionFalse
+			}
+			progressing.Reason = c.Reason
+			progressing.Message = c.Message
+		case c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue:
+			degraded.Status = metav1.ConditionTrue
+			degraded.Reason = c.Reason
+			degraded.Message = c.Message
+		}
+	}
+	// The Deployment controller has not seen the latest spec yet
+	if dep.Generation > dep.Status.ObservedGeneration {
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "DeploymentUpdating"
+		progressing.Message = fmt.Sprintf("The %s Deployment spec has not been observed yet", component)
+	}
+
+	return []metav1.Condition{available, progressing, degraded}
+}
+
+// imageReference returns the image reference registry/name[:tag][@digest]
+func imageReference(image productionstackv1alpha1.ImageSpec) string {
+	ref := image.Registry + "/" + image.Name
+	if image.Tag != "" {
+		ref += ":" + image.Tag
+	}
+	if image.Digest != "" {
+		ref += "@" + image.Digest
+	}
+	return ref
+}
+
+// resolveSecretKey returns the value of a Secret key, or a SecretMissing
+// condition if the Secret or key does not exist
+func resolveSecretKey(ctx context.Context, c client.Client, namespace, name, key string) ([]byte, *metav1.Condition, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		return nil, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionSecretMissing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SecretNotFound",
+			Message: fmt.Sprintf("Secret %s not found", name),
+		}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionSecretMissing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "KeyNotFound",
+			Message: fmt.Sprintf("Secret %s has no key %s", name, key),
+		}, nil
+	}
+	return value, nil, nil
+}
+
+// degradedWaitingReasons are the container waiting reasons that mark a pod as degraded
+var degradedWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// podsDegradedCondition inspects the container statuses of the pods matching
+// labels and returns the Degraded condition, nil when no container is failing
+func podsDegradedCondition(ctx context.Context, c client.Reader, namespace string, labels map[string]string) (*metav1.Condition, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	var condition *metav1.Condition
+	degraded := 0
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			waiting := status.State.Waiting
+			if waiting == nil || !degradedWaitingReasons[waiting.Reason] {
+				continue
+			}
+			degraded++
+			if condition == nil {
+				message := fmt.Sprintf("Container %s of pod %s is in %s (restarts: %d)", status.Name, pod.Name, waiting.Reason, status.RestartCount)
+				if terminated := status.LastTerminationState.Terminated; terminated != nil {
+					message += fmt.Sprintf(", last terminated with %s (exit code %d)", terminated.Reason, terminated.ExitCode)
+				}
+				if waiting.Message != "" {
+					message += ": " + waiting.Message
+				}
+				condition = &metav1.Condition{
+					Type:    productionstackv1alpha1.ConditionDegraded,
+					Status:  metav1.ConditionTrue,
+					Reason:  waiting.Reason,
+					Message: message,
+				}
+			}
+			break
+		}
+	}
+	if condition != nil && degraded > 1 {
+		condition.Message = fmt.Sprintf("%d pods degraded. %s", degraded, condition.Message)
+	}
+	return condition, nil
+}
+
+// invalidQuantityCondition returns a SpecInvalid condition listing the
+// quantities that cannot be parsed, keyed by their spec path, or nil if all
+// can. Empty quantities are unset.
+func invalidQuantityCondition(quantities map[string]string) *metav1.Condition {
+	paths := make([]string, 0, len(quantities))
+	for path := range quantities {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var invalid []string
+	for _, path := range paths {
+		if value := quantities[path]; value != "" {
+			if _, err := resource.ParseQuantity(value); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s %q: %v", path, value, err))
+			}
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionSpecInvalid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "InvalidQuantity",
+		Message: strings.Join(invalid, "; "),
+	}
+}
+
+// setResourceQuantity requests and limits the quantity of the resource. An
+// empty or unparsable quantity, which Reconcile reports first, is left out.
+func setResourceQuantity(resources *corev1.ResourceRequirements, name corev1.ResourceName, value string) {
+	if quantity, err := resource.ParseQuantity(value); value != "" && err == nil {
+		resources.Requests[name] = quantity
+		resources.Limits[name] = quantity
+	}
+}
+
+// containerEnvVar converts a user-defined environment variable to the
+// container one
+func containerEnvVar(e productionstackv1alpha1.EnvVar) corev1.EnvVar {
+	valueFrom := e.ValueFrom
+	if valueFrom != nil && valueFrom.FieldRef != nil && valueFrom.FieldRef.APIVersion == "" {
+		// Match the API server default so the deployment diff stays stable
+		valueFrom = valueFrom.DeepCopy()
+		valueFrom.FieldRef.APIVersion = "v1"
+	}
+	return corev1.EnvVar{
+		Name:      e.Name,
+		Value:     e.Value,
+		ValueFrom: valueFrom,
+	}
+}
+
+// envDefined reports whether env sets the variable name
+func envDefined(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// applyAndRecord applies obj with server-side apply and records an event on
+// owner when obj is created, changed or fails to apply. obj must have its
+// TypeMeta set.
+func applyAndRecord(ctx context.Context, c client.Client, recorder record.EventRecorder, owner, obj client.Object, fieldOwner client.FieldOwner) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	newObj, err := c.Scheme().New(gvk)
+	if err != nil {
+		return err
+	}
+	existing := newObj.(client.Object)
+	err = c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err := c.Patch(ctx, obj, client.Apply, fieldOwner, client.ForceOwnership); err != nil {
+		recorder.Eventf(owner, corev1.EventTypeWarning, "ApplyFailed", "Failed to apply %s %s: %v", gvk.Kind, obj.GetName(), err)
+		return err
+	}
+
+	switch {
+	case !found:
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created %s %s", gvk.Kind, obj.GetName())
+	case existing.GetResourceVersion() != obj.GetResourceVersion():
+		message := fmt.Sprintf("Updated %s %s", gvk.Kind, obj.GetName())
+		if changes := changeSummary(existing, obj); len(changes) > 0 {
+			message += ": " + strings.Join(changes, "; ")
+		}
+		recorder.Event(owner, corev1.EventTypeNormal, "Updated", message)
+	}
+	return nil
+}
+
+// createAndRecord creates obj and records an event on owner when it is
+// created or fails to create
+func createAndRecord(ctx context.Context, c client.Client, recorder record.EventRecorder, owner, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if err := c.Create(ctx, obj); err != nil {
+		recorder.Eventf(owner, corev1.EventTypeWarning, "CreateFailed", "Failed to create %s %s: %v", gvk.Kind, obj.GetName(), err)
+		return err
+	}
+	recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created %s %s", gvk.Kind, obj.GetName())
+	return nil
+}
+
+// updateAndRecord replaces existing with obj and records an event on owner
+// describing the changes, or the failure to update
+func updateAndRecord(ctx context.Context, c client.Client, recorder record.EventRecorder, owner, existing, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if err := c.Update(ctx, obj); err != nil {
+		recorder.Eventf(owner, corev1.EventTypeWarning, "UpdateFailed", "Failed to update %s %s: %v", gvk.Kind, obj.GetName(), err)
+		return err
+	}
+	message := fmt.Sprintf("Updated %s %s", gvk.Kind, obj.GetName())
+	if changes := changeSummary(existing, obj); len(changes) > 0 {
+		message += ": " + strings.Join(changes, "; ")
+	}
+	recorder.Event(owner, corev1.EventTypeNormal, "Updated", message)
+	return nil
+}
+
+// changeSummary describes the changes between two versions of an object
+func changeSummary(oldObj, newObj client.Object) []string {
+	switch newObj := newObj.(type) {
+	case *appsv1.Deployment:
+		return deploymentChanges(oldObj.(*appsv1.Deployment), newObj)
+	case *appsv1.StatefulSet:
+		return statefulSetChanges(oldObj.(*appsv1.StatefulSet), newObj)
+	case *corev1.Service:
+		return serviceChanges(oldObj.(*corev1.Service), newObj)
+	}
+	return nil
+}
+
+// deploymentChanges describes the changes between two versions of a Deployment
+func deploymentChanges(oldDep, newDep *appsv1.Deployment) []string {
+	var changes []string
+	if oldReplicas, newReplicas := replicasValue(oldDep.Spec.Replicas), replicasValue(newDep.Spec.Replicas); oldReplicas != newReplicas {
+		changes = append(changes, fmt.Sprintf("replicas changed: %d→%d", oldReplicas, newReplicas))
+	}
+	if oldDep.Spec.Strategy.Type != newDep.Spec.Strategy.Type {
+		changes = append(changes, fmt.Sprintf("strategy changed: %s→%s", oldDep.Spec.Strategy.Type, newDep.Spec.Strategy.Type))
+	}
+	return append(changes, podTemplateChanges(&oldDep.Spec.Template, &newDep.Spec.Template)...)
+}
+
+// statefulSetChanges describes the changes between two versions of a StatefulSet
+func statefulSetChanges(oldSts, newSts *appsv1.StatefulSet) []string {
+	var changes []string
+	if oldReplicas, newReplicas := replicasValue(oldSts.Spec.Replicas), replicasValue(newSts.Spec.Replicas); oldReplicas != newReplicas {
+		changes = append(changes, fmt.Sprintf("replicas changed: %d→%d", oldReplicas, newReplicas))
+	}
+	return append(changes, podTemplateChanges(&oldSts.Spec.Template, &newSts.Spec.Template)...)
+}
+
+// podTemplateChanges describes the changes between two versions of the pod
+// template of a workload
+func podTemplateChanges(oldTemplate, newTemplate *corev1.PodTemplateSpec) []string {
+	var changes []string
+	if keys := changedKeys(oldTemplate.Annotations, newTemplate.Annotations); len(keys) > 0 {
+		changes = append(changes, "pod annotations changed: "+strings.Join(keys, ", "))
+	}
+	if names := changedNames(volumeNames(oldTemplate.Spec.Volumes), volumeNames(newTemplate.Spec.Volumes)); len(names) > 0 {
+		changes = append(changes, "volumes changed: "+strings.Join(names, ", "))
+	}
+	if oldGrace, newGrace := oldTemplate.Spec.TerminationGracePeriodSeconds, newTemplate.Spec.TerminationGracePeriodSeconds; oldGrace != nil && newGrace != nil && *oldGrace != *newGrace {
+		changes = append(changes, fmt.Sprintf("terminationGracePeriodSeconds changed: %d→%d", *oldGrace, *newGrace))
+	}
+
+	oldContainers := make(map[string]corev1.Container, len(oldTemplate.Spec.Containers))
+	for _, container := range oldTemplate.Spec.Containers {
+		oldContainers[container.Name] = container
+	}
+	for _, newContainer := range newTemplate.Spec.Containers {
+		oldContainer, ok := oldContainers[newContainer.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("container %s added", newContainer.Name))
+			continue
+		}
+		if oldContainer.Image != newContainer.Image {
+			changes = append(changes, fmt.Sprintf("image changed: %s→%s", oldContainer.Image, newContainer.Image))
+		}
+		if args := argsChanges(oldContainer.Args, newContainer.Args); len(args) > 0 {
+			changes = append(changes, "args changed: "+strings.Join(args, ", "))
+		}
+		// Only the names are reported, env values may hold secrets
+		if names := changedEnvNames(oldContainer.Env, newContainer.Env); len(names) > 0 {
+			changes = append(changes, "env changed: "+strings.Join(names, ", "))
+		}
+		if !equality.Semantic.DeepEqual(oldContainer.Resources, newContainer.Resources) {
+			changes = append(changes, "resources changed")
+		}
+		if !equality.Semantic.DeepEqual(oldContainer.Lifecycle, newContainer.Lifecycle) {
+			changes = append(changes, "lifecycle changed")
+		}
+	}
+	return changes
+}
+
+// serviceChanges describes the changes between two versions of a Service
+func serviceChanges(oldSvc, newSvc *corev1.Service) []string {
+	var changes []string
+	if oldSvc.Spec.Type != newSvc.Spec.Type {
+		changes = append(changes, fmt.Sprintf("type changed: %s→%s", oldSvc.Spec.Type, newSvc.Spec.Type))
+	}
+	if !equality.Semantic.DeepEqual(oldSvc.Spec.Ports, newSvc.Spec.Ports) {
+		changes = append(changes, fmt.Sprintf("ports changed: %s→%s", servicePortsString(oldSvc.Spec.Ports), servicePortsString(newSvc.Spec.Ports)))
+	}
+	return changes
+}
+
+// argsChanges describes the flags added, removed or changed between two argument lists
+func argsChanges(oldArgs, newArgs []string) []string {
+	oldFlags, oldOrder := parseArgs(oldArgs)
+	newFlags, newOrder := parseArgs(newArgs)
+
+	var changes []string
+	for _, flag := range newOrder {
+		oldValue, ok := oldFlags[flag]
+		switch {
+		case !ok:
+			changes = append(changes, strings.TrimSpace(flag+" "+newFlags[flag])+" added")
+		case oldValue != newFlags[flag]:
+			changes = append(changes, fmt.Sprintf("%s %s→%s", flag, oldValue, newFlags[flag]))
+		}
+	}
+	for _, flag := range oldOrder {
+		if _, ok := newFlags[flag]; !ok {
+			changes = append(changes, flag+" removed")
+		}
+	}
+	return changes
+}
+
+// parseArgs maps the flags of an argument list to their values. Positional
+// arguments are keyed by themselves.
+func parseArgs(args []string) (map[string]string, []string) {
+	flags := make(map[string]string, len(args))
+	var order []string
+	for i := 0; i < len(args); i++ {
+		key, value := args[i], ""
+		if strings.HasPrefix(key, "-") {
+			if k, v, ok := strings.Cut(key, "="); ok {
+				key, value = k, v
+			} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				value = args[i+1]
+				i++
+			}
+		}
+		if _, ok := flags[key]; !ok {
+			order = append(order, key)
+		}
+		flags[key] = value
+	}
+	return flags, order
+}
+
+// changedKeys returns the sorted keys whose values differ between two maps
+func changedKeys(oldMap, newMap map[string]string) []string {
+	var keys []string
+	for k, v := range newMap {
+		if oldValue, ok := oldMap[k]; !ok || oldValue != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range oldMap {
+		if _, ok := newMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// changedEnvNames returns the sorted names of the environment variables added,
+// removed or changed
+func changedEnvNames(oldEnv, newEnv []corev1.EnvVar) []string {
+	oldVars := make(map[string]corev1.EnvVar, len(oldEnv))
+	for _, env := range oldEnv {
+		oldVars[env.Name] = env
+	}
+	newVars := make(map[string]corev1.EnvVar, len(newEnv))
+	for _, env := range newEnv {
+		newVars[env.Name] = env
+	}
+
+	var names []string
+	for name, env := range newVars {
+		if oldVar, ok := oldVars[name]; !ok || !equality.Semantic.DeepEqual(oldVar, env) {
+			names = append(names, name)
+		}
+	}
+	for name := range oldVars {
+		if _, ok := newVars[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// changedNames returns the sorted names present in only one of two lists
+func changedNames(oldNames, newNames []string) []string {
+	oldSet := make(map[string]string, len(oldNames))
+	for _, name := range oldNames {
+		oldSet[name] = name
+	}
+	newSet := make(map[string]string, len(newNames))
+	for _, name := range newNames {
+		newSet[name] = name
+	}
+	return changedKeys(oldSet, newSet)
+}
+
+func volumeNames(volumes []corev1.Volume) []string {
+	names := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		names = append(names, volume.Name)
+	}
+	return names
+}
+
+func servicePortsString(ports []corev1.ServicePort) string {
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		parts = append(parts, fmt.Sprintf("%s:%d/%s", port.Name, port.Port, port.TargetPort.String()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func replicasValue(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// httpRouteGVK is the Gateway API HTTPRoute kind. HTTPRoutes are handled as
+// unstructured objects so the operator runs on clusters without the Gateway
+// API CRDs.
+var httpRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1",
+	Kind:    "HTTPRoute",
+}
+
+// gatewayAPIInstalled reports whether the HTTPRoute CRD is served by the cluster
+func gatewayAPIInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(httpRouteGVK.GroupKind(), httpRouteGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newHTTPRoute returns an empty HTTPRoute object
+func newHTTPRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	return route
+}
+
+// httpRouteForService returns an HTTPRoute attaching the named Service to the
+// Gateways in spec.ParentRefs. Fields defaulted by the API server are set
+// explicitly so the route compares equal to the one read back.
+func httpRouteForService(owner metav1.Object, spec productionstackv1alpha1.HTTPRouteSpec, serviceName string, servicePort int32, scheme *runtime.Scheme) *unstructured.Unstructured {
+	pathPrefix := spec.PathPrefix
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+
+	parentRefs := make([]interface{}, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := map[string]interface{}{
+			"group": httpRouteGVK.Group,
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	routeSpec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": pathPrefix,
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"group":  "",
+						"kind":   "Service",
+						"name":   serviceName,
+						"port":   int64(servicePort),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+	if len(spec.Hostnames) > 0 {
+		hostnames := make([]interface{}, 0, len(spec.Hostnames))
+		for _, hostname := range spec.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		routeSpec["hostnames"] = hostnames
+	}
+
+	route := newHTTPRoute()
+	route.SetName(serviceName)
+	route.SetNamespace(owner.GetNamespace())
+	route.Object["spec"] = routeSpec
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, route, scheme)
+	return route
+}
+
+// reconcileHTTPRoute creates, updates or deletes the HTTPRoute owned by owner
+// so that it matches spec. It returns a GatewayAPIUnavailable condition when
+// the route is enabled but the Gateway API CRDs are not installed, and nil
+// otherwise.
+func reconcileHTTPRoute(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.HTTPRouteSpec, serviceName string, servicePort int32) (*metav1.Condition, error) {
+	log := log.FromContext(ctx)
+
+	installed, err := gatewayAPIInstalled(c.RESTMapper())
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		if !spec.Enabled {
+			return nil, nil
+		}
+		return &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionGatewayAPIUnavailable,
+			Status:  metav1.ConditionTrue,
+			Reason:  "CRDNotInstalled",
+			Message: "the gateway.networking.k8s.io HTTPRoute CRD is not installed",
+		}, nil
+	}
+
+	found := newHTTPRoute()
+	err = c.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	if !spec.Enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting HTTPRoute", "HTTPRoute.Namespace", found.GetNamespace(), "HTTPRoute.Name", found.GetName())
+			return nil, client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil, nil
+	}
+
+	expected := httpRouteForService(owner, spec, serviceName, servicePort, scheme)
+	if !exists {
+		log.Info("Creating a new HTTPRoute", "HTTPRoute.Namespace", expected.GetNamespace(), "HTTPRoute.Name", expected.GetName())
+		return nil, c.Create(ctx, expected)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Object["spec"], expected.Object["spec"]) {
+		log.Info("Updating HTTPRoute", "HTTPRoute.Namespace", found.GetNamespace(), "HTTPRoute.Name", found.GetName())
+		found.Object["spec"] = expected.Object["spec"]
+		return nil, c.Update(ctx, found)
+	}
+
+	return nil, nil
+}
+
+// httpRouteAcceptedCondition summarizes the Accepted and ResolvedRefs
+// conditions reported by the Gateways in the status of route into a condition
+// of type conditionType. It is Unknown until every parent reported a status.
+func httpRouteAcceptedCondition(route *unstructured.Unstructured, conditionType string, parentCount int) metav1.Condition {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) < parentCount || len(parents) == 0 {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "Pending",
+			Message: "waiting for the Gateways to report the HTTPRoute status",
+		}
+	}
+
+	for _, parent := range parents {
+		parent, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gateway, _, _ := unstructured.NestedString(parent, "parentRef", "name")
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, wanted := range []string{"Accepted", "ResolvedRefs"} {
+			status, reason, message := "", "", ""
+			for _, condition := range conditions {
+				condition, ok := condition.(map[string]interface{})
+				if !ok || condition["type"] != wanted {
+					continue
+				}
+				status, _, _ = unstructured.NestedString(condition, "status")
+				reason, _, _ = unstructured.NestedString(condition, "reason")
+				message, _, _ = unstructured.NestedString(condition, "message")
+			}
+			if status == string(metav1.ConditionTrue) {
+				continue
+			}
+			if reason == "" {
+				reason = "Pending"
+			}
+			conditionStatus := metav1.ConditionFalse
+			if status == "" || status == string(metav1.ConditionUnknown) {
+				conditionStatus = metav1.ConditionUnknown
+			}
+			return metav1.Condition{
+				Type:    conditionType,
+				Status:  conditionStatus,
+				Reason:  reason,
+				Message: fmt.Sprintf("Gateway %s: %s not true: %s", gateway, wanted, message),
+			}
+		}
+	}
+
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Accepted",
+		Message: "the HTTPRoute is accepted by all its Gateways",
+	}
+}
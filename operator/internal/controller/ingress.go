@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// ingressForService returns an Ingress routing spec.Host and spec.Path to the
+// named port of the named Service
+func ingressForService(owner metav1.Object, spec productionstackv1alpha1.IngressSpec, serviceName, portName string, scheme *runtime.Scheme) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	path := spec.Path
+	if path == "" {
+		path = "/"
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serviceName,
+			Namespace:   owner.GetNamespace(),
+			Annotations: spec.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     path,
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{Name: portName},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if spec.ClassName != "" {
+		ingress.Spec.IngressClassName = &spec.ClassName
+	}
+
+	if spec.TLS != nil {
+		tls := networkingv1.IngressTLS{SecretName: spec.TLS.SecretName}
+		if spec.Host != "" {
+			tls.Hosts = []string{spec.Host}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, ingress, scheme)
+	return ingress
+}
+
+// ingressNeedsUpdate checks if the Ingress differs from the expected one
+func ingressNeedsUpdate(ingress, expected *networkingv1.Ingress) bool {
+	if len(ingress.Annotations) != 0 || len(expected.Annotations) != 0 {
+		if !reflect.DeepEqual(ingress.Annotations, expected.Annotations) {
+			return true
+		}
+	}
+	return !equality.Semantic.DeepEqual(ingress.Spec, expected.Spec)
+}
+
+// ingressURL returns the external URL served by the Ingress
+func ingressURL(spec productionstackv1alpha1.IngressSpec) string {
+	if !spec.Enabled || spec.Host == "" {
+		return ""
+	}
+	scheme := "http"
+	if spec.TLS != nil {
+		scheme = "https"
+	}
+	path := spec.Path
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + spec.Host + path
+}
+
+// ingressLoadBalancerURL returns the external URL served by the Ingress once
+// the ingress controller reported a load balancer address, and an empty string
+// before. Without spec.Host the URL uses the load balancer address.
+func ingressLoadBalancerURL(ctx context.Context, c client.Client, namespace string, spec productionstackv1alpha1.IngressSpec, name string) (string, error) {
+	if !spec.Enabled {
+		return "", nil
+	}
+	ingress := &networkingv1.Ingress{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, ingress); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+		return "", nil
+	}
+	if spec.Host != "" {
+		return ingressURL(spec), nil
+	}
+
+	address := ingress.Status.LoadBalancer.Ingress[0].Hostname
+	if address == "" {
+		address = ingress.Status.LoadBalancer.Ingress[0].IP
+	}
+	if address == "" {
+		return "", nil
+	}
+	spec.Host = address
+	return ingressURL(spec), nil
+}
+
+// reconcileIngress creates, updates or deletes the Ingress owned by owner so
+// that it matches spec. A cluster without an ingress controller simply leaves
+// the Ingress unprogrammed.
+func reconcileIngress(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.IngressSpec, serviceName, portName string) error {
+	log := log.FromContext(ctx)
+
+	found := &networkingv1.Ingress{}
+	err := c.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !spec.Enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting Ingress", "Ingress.Namespace", found.Namespace, "Ingress.Name", found.Name)
+			return client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil
+	}
+
+	expected := ingressForService(owner, spec, serviceName, portName, scheme)
+	if !exists {
+		log.Info("Creating a new Ingress", "Ingress.Namespace", expected.Namespace, "Ingress.Name", expected.Name)
+		return c.Create(ctx, expected)
+	}
+
+	if ingressNeedsUpdate(found, expected) {
+		log.Info("Updating Ingress", "Ingress.Namespace", found.Namespace, "Ingress.Name", found.Name)
+		found.Annotations = expected.Annotations
+		found.Spec = expected.Spec
+		return c.Update(ctx, found)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// podDisruptionBudgetFor returns a PodDisruptionBudget named name protecting
+// the pods matching selector
+func podDisruptionBudgetFor(owner metav1.Object, spec productionstackv1alpha1.PodDisruptionBudgetSpec, name string, selector map[string]string, scheme *runtime.Scheme) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels:    selector,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			MinAvailable:   spec.MinAvailable,
+			MaxUnavailable: spec.MaxUnavailable,
+		},
+	}
+
+	if spec.MinAvailable == nil && spec.MaxUnavailable == nil {
+		maxUnavailable := intstr.FromInt32(1)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, pdb, scheme)
+	return pdb
+}
+
+// reconcilePodDisruptionBudget creates, updates or deletes the
+// PodDisruptionBudget owned by owner so that it matches spec. It is deleted
+// when enabled is false.
+func reconcilePodDisruptionBudget(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.PodDisruptionBudgetSpec, enabled bool, name string, selector map[string]string) error {
+	log := log.FromContext(ctx)
+
+	found := &policyv1.PodDisruptionBudget{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting PodDisruptionBudget", "PodDisruptionBudget.Namespace", found.Namespace, "PodDisruptionBudget.Name", found.Name)
+			return client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil
+	}
+
+	expected := podDisruptionBudgetFor(owner, spec, name, selector, scheme)
+	if !exists {
+		log.Info("Creating a new PodDisruptionBudget", "PodDisruptionBudget.Namespace", expected.Namespace, "PodDisruptionBudget.Name", expected.Name)
+		return c.Create(ctx, expected)
+	}
+
+	if !equality.Semantic.DeepEqual(found.Spec, expected.Spec) {
+		log.Info("Updating PodDisruptionBudget", "PodDisruptionBudget.Namespace", found.Namespace, "PodDisruptionBudget.Name", found.Name)
+		found.Spec = expected.Spec
+		return c.Update(ctx, found)
+	}
+
+	return nil
+}
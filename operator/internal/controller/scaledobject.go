@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// scaledObjectGVK is the KEDA ScaledObject kind. ScaledObjects are handled as
+// unstructured objects so the operator runs on clusters without KEDA.
+var scaledObjectGVK = schema.GroupVersionKind{
+	Group:   "keda.sh",
+	Version: "v1alpha1",
+	Kind:    "ScaledObject",
+}
+
+// kedaInstalled reports whether the ScaledObject CRD is served by the cluster
+func kedaInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(scaledObjectGVK.GroupKind(), scaledObjectGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newScaledObject returns an empty ScaledObject object
+func newScaledObject() *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{}
+	scaledObject.SetGroupVersionKind(scaledObjectGVK)
+	return scaledObject
+}
+
+// scaledObjectFor returns a ScaledObject named name scaling the Deployment of
+// the same name. Integers are int64 like in the objects read from the server.
+func scaledObjectFor(owner metav1.Object, spec productionstackv1alpha1.AutoscalingSpec, name string, labels map[string]string, scheme *runtime.Scheme) *unstructured.Unstructured {
+	minReplicas := int64(1)
+	if spec.MinReplicas != nil {
+		minReplicas = int64(*spec.MinReplicas)
+	}
+
+	triggers := make([]interface{}, 0, len(spec.ScaledObject.Triggers))
+	for _, t := range spec.ScaledObject.Triggers {
+		metadata := make(map[string]interface{}, len(t.Metadata))
+		for k, v := range t.Metadata {
+			metadata[k] = v
+		}
+		trigger := map[string]interface{}{
+			"type":     t.Type,
+			"metadata": metadata,
+		}
+		if t.Name != "" {
+			trigger["name"] = t.Name
+		}
+		if t.MetricType != "" {
+			trigger["metricType"] = t.MetricType
+		}
+		if t.AuthenticationRef != nil {
+			authenticationRef := map[string]interface{}{"name": t.AuthenticationRef.Name}
+			if t.AuthenticationRef.Kind != "" {
+				authenticationRef["kind"] = t.AuthenticationRef.Kind
+			}
+			trigger["authenticationRef"] = authenticationRef
+		}
+		triggers = append(triggers, trigger)
+	}
+
+	scaledObjectSpec := map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       name,
+		},
+		"minReplicaCount": minReplicas,
+		"maxReplicaCount": int64(spec.MaxReplicas),
+		"triggers":        triggers,
+	}
+	if spec.ScaledObject.PollingInterval != nil {
+		scaledObjectSpec["pollingInterval"] = int64(*spec.ScaledObject.PollingInterval)
+	}
+	if spec.ScaledObject.CooldownPeriod != nil {
+		scaledObjectSpec["cooldownPeriod"] = int64(*spec.ScaledObject.CooldownPeriod)
+	}
+
+	scaledObject := newScaledObject()
+	scaledObject.SetName(name)
+	scaledObject.SetNamespace(owner.GetNamespace())
+	scaledObject.SetLabels(labels)
+	scaledObject.Object["spec"] = scaledObjectSpec
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, scaledObject, scheme)
+	return scaledObject
+}
+
+// reconcileScaledObject creates, updates or deletes the ScaledObject owned by
+// owner so that it matches spec. It returns the live ScaledObject, or nil
+// when it is disabled, and a KEDAUnavailable condition when it is enabled but
+// the KEDA CRDs are not installed.
+func reconcileScaledObject(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.AutoscalingSpec, name string, labels map[string]string) (*unstructured.Unstructured, *metav1.Condition, error) {
+	log := log.FromContext(ctx)
+	enabled := spec.Enabled && spec.ScaledObject.Enabled
+
+	installed, err := kedaInstalled(c.RESTMapper())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !installed {
+		if !enabled {
+			return nil, nil, nil
+		}
+		return nil, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionKEDAUnavailable,
+			Status:  metav1.ConditionTrue,
+			Reason:  "CRDNotInstalled",
+			Message: "the keda.sh ScaledObject CRD is not installed",
+		}, nil
+	}
+
+	found := newScaledObject()
+	err = c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, nil, err
+	}
+	exists := err == nil
+
+	if !enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting ScaledObject", "ScaledObject.Namespace", found.GetNamespace(), "ScaledObject.Name", found.GetName())
+			return nil, nil, client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil, nil, nil
+	}
+
+	expected := scaledObjectFor(owner, spec, name, labels, scheme)
+	if !exists {
+		log.Info("Creating a new ScaledObject", "ScaledObject.Namespace", expected.GetNamespace(), "ScaledObject.Name", expected.GetName())
+		return expected, nil, c.Create(ctx, expected)
+	}
+
+	if !reflect.DeepEqual(found.GetLabels(), expected.GetLabels()) || !equality.Semantic.DeepEqual(found.Object["spec"], expected.Object["spec"]) {
+		log.Info("Updating ScaledObject", "ScaledObject.Namespace", found.GetNamespace(), "ScaledObject.Name", found.GetName())
+		found.SetLabels(expected.GetLabels())
+		found.Object["spec"] = expected.Object["spec"]
+		return found, nil, c.Update(ctx, found)
+	}
+
+	return found, nil, nil
+}
+
+// scaledObjectHPAName returns the name of the HorizontalPodAutoscaler created
+// by KEDA for a ScaledObject, empty until KEDA reports it
+func scaledObjectHPAName(scaledObject *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(scaledObject.Object, "status", "hpaName")
+	return name
+}
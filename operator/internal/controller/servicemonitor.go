@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// serviceMonitorGVK is the Prometheus Operator ServiceMonitor kind.
+// ServiceMonitors are handled as unstructured objects so the operator runs on
+// clusters without the Prometheus Operator CRDs.
+var serviceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// prometheusOperatorInstalled reports whether the ServiceMonitor CRD is served by the cluster
+func prometheusOperatorInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(serviceMonitorGVK.GroupKind(), serviceMonitorGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newServiceMonitor returns an empty ServiceMonitor object
+func newServiceMonitor() *unstructured.Unstructured {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(serviceMonitorGVK)
+	return monitor
+}
+
+// serviceMonitorForService returns a ServiceMonitor scraping /metrics on the
+// named port of the Services matching selector
+func serviceMonitorForService(owner metav1.Object, spec productionstackv1alpha1.MonitoringSpec, serviceName string, selector map[string]string, portName string, scheme *runtime.Scheme) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": portName,
+		"path": "/metrics",
+	}
+	if spec.Interval != "" {
+		endpoint["interval"] = spec.Interval
+	}
+	if portName == "https" {
+		endpoint["scheme"] = "https"
+	}
+
+	matchLabels := make(map[string]interface{}, len(selector))
+	for k, v := range selector {
+		matchLabels[k] = v
+	}
+
+	monitor := newServiceMonitor()
+	monitor.SetName(serviceName)
+	monitor.SetNamespace(owner.GetNamespace())
+	monitor.SetLabels(spec.ExtraLabels)
+	monitor.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": matchLabels,
+		},
+		"endpoints": []interface{}{endpoint},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(owner, monitor, scheme)
+	return monitor
+}
+
+// reconcileServiceMonitor creates, updates or deletes the ServiceMonitor owned
+// by owner so that it matches spec. It returns a MonitoringCRDsMissing
+// condition when monitoring is enabled but the Prometheus Operator CRDs are
+// not installed, and nil otherwise.
+func reconcileServiceMonitor(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, spec productionstackv1alpha1.MonitoringSpec, serviceName string, selector map[string]string, portName string) (*metav1.Condition, error) {
+	log := log.FromContext(ctx)
+
+	installed, err := prometheusOperatorInstalled(c.RESTMapper())
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		if !spec.Enabled {
+			return nil, nil
+		}
+		return &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionMonitoringCRDsMissing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "CRDNotInstalled",
+			Message: "the monitoring.coreos.com ServiceMonitor CRD is not installed",
+		}, nil
+	}
+
+	found := newServiceMonitor()
+	err = c.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: owner.GetNamespace()}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	if !spec.Enabled {
+		if exists && metav1.IsControlledBy(found, owner) {
+			log.Info("Deleting ServiceMonitor", "ServiceMonitor.Namespace", found.GetNamespace(), "ServiceMonitor.Name", found.GetName())
+			return nil, client.IgnoreNotFound(c.Delete(ctx, found))
+		}
+		return nil, nil
+	}
+
+	expected := serviceMonitorForService(owner, spec, serviceName, selector, portName, scheme)
+	if !exists {
+		log.Info("Creating a new ServiceMonitor", "ServiceMonitor.Namespace", expected.GetNamespace(), "ServiceMonitor.Name", expected.GetName())
+		return nil, c.Create(ctx, expected)
+	}
+
+	labelsChanged := (len(found.GetLabels()) != 0 || len(expected.GetLabels()) != 0) && !reflect.DeepEqual(found.GetLabels(), expected.GetLabels())
+	if labelsChanged || !equality.Semantic.DeepEqual(found.Object["spec"], expected.Object["spec"]) {
+		log.Info("Updating ServiceMonitor", "ServiceMonitor.Namespace", found.GetNamespace(), "ServiceMonitor.Name", found.GetName())
+		found.SetLabels(expected.GetLabels())
+		found.Object["spec"] = expected.Object["spec"]
+		return nil, c.Update(ctx, found)
+	}
+
+	return nil, nil
+}
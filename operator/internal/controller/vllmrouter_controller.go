@@ -18,29 +18,171 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	servingv1alpha1 "production-stack/api/v1alpha1"
 )
 
+// vllmRouterFieldOwner is the server-side apply field manager of the
+// VLLMRouter controller
+const vllmRouterFieldOwner = client.FieldOwner("vllmrouter-controller")
+
+var (
+	// defaultRouterLivenessProbe are the liveness probe settings used when
+	// spec.probes.liveness leaves them unset
+	defaultRouterLivenessProbe = corev1.Probe{
+		InitialDelaySeconds: 30,
+		PeriodSeconds:       5,
+		TimeoutSeconds:      1,
+		FailureThreshold:    3,
+	}
+
+	// defaultRouterReadinessProbe are the readiness probe settings used when
+	// spec.probes.readiness leaves them unset
+	defaultRouterReadinessProbe = corev1.Probe{
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       5,
+		TimeoutSeconds:      1,
+		FailureThreshold:    3,
+	}
+)
+
+// defaultRouterServicePort is the port exposed by the VLLMRouter Service
+// when spec.service.port is not set
+const defaultRouterServicePort = 80
+
+const (
+	// apiKeyHashAnnotation records the API key hash on the pod template
+	apiKeyHashAnnotation = annotationPrefix + "api-key-hash"
+	// tlsHashAnnotation records the hash of the served certificate on the pod template
+	tlsHashAnnotation = annotationPrefix + "tls-hash"
+	// sidecarsHashAnnotation records the hash of the sidecars on the pod
+	// template, whose containers are defaulted by the API server
+	sidecarsHashAnnotation = annotationPrefix + "sidecars-hash"
+)
+
+// routerClusterRBACFinalizer deletes the ClusterRole and ClusterRoleBinding of
+// a router, which it cannot own, when the router is deleted
+const routerClusterRBACFinalizer = annotationPrefix + "cluster-rbac"
+
+const (
+	// dynamicConfigVolumeName is the name of the volume holding the dynamic config
+	dynamicConfigVolumeName = "dynamic-config"
+	// dynamicConfigMountPath is where the dynamic config ConfigMap is mounted
+	dynamicConfigMountPath = "/etc/vllm-router/dynamic-config"
+	// tlsVolumeName is the name of the volume holding the router certificate
+	tlsVolumeName = "tls"
+	// tlsMountPath is where the TLS Secret is mounted
+	tlsMountPath = "/etc/vllm-router/tls"
+	// tmpVolumeName is the name of the writable volume of a read-only root filesystem
+	tmpVolumeName = "tmp"
+	// tmpMountPath is where the router writes, e.g. batch API files
+	tmpMountPath = "/tmp"
+	// filesVolumeName is the name of the volume holding the batch API files
+	filesVolumeName = "files"
+)
+
+// defaultRouterFileStorageSize is the size of the PersistentVolumeClaim
+// created for the batch API when fileStorage.size is not set
+var defaultRouterFileStorageSize = resource.MustParse("10Gi")
+
+// defaultLMCacheControllerPort is the LMCache controller port of kvaware routing
+const defaultLMCacheControllerPort = 9000
+
+// routerDrainScript is run by the preStop hook with the /drain URL and the
+// drain sleep as arguments. A failed call, e.g. on a router release without
+// /drain, still sleeps. Certificates are not verified since the call is local.
+const routerDrainScript = `import ssl, sys, time, urllib.request
+try:
+    urllib.request.urlopen(urllib.request.Request(sys.argv[1], method="POST"), timeout=5, context=ssl._create_unverified_context())
+except Exception:
+    pass
+time.sleep(int(sys.argv[2]))
+`
+
+// loadBalancerRequeuePeriod is how often a router waits for the address of
+// its LoadBalancer Service
+const loadBalancerRequeuePeriod = 10 * time.Second
+
+// routerVersionedFlagsMinVersion is the first router release accepting
+// --request-timeout, --workers, --backend-connect-timeout,
+// --backend-read-timeout, --max-retries, --retry-on-5xx and
+// --max-concurrent-requests
+var routerVersionedFlagsMinVersion = version.MustParseGeneric("0.1.3")
+
+// activeRuntimesResyncPeriod is how often the backends discovered by a router are recounted
+const activeRuntimesResyncPeriod = time.Minute
+
+// routerBackends are the backends generated from the VLLMRuntimes selected by
+// runtimeSelector
+type routerBackends struct {
+	// backends and models are the --static-backends and --static-models of the router
+	backends string
+	models   string
+	// runtimes are the names of the selected VLLMRuntimes
+	runtimes []string
+}
+
+// routerInputs are the inputs of the router Deployment resolved from other objects
+type routerInputs struct {
+	// backends is nil without runtimeSelector
+	backends *routerBackends
+	// annotations are added to the pod template, e.g. the API key hash
+	annotations map[string]string
+}
+
+// routerObservedState is the state of the router's children reported in its status
+type routerObservedState struct {
+	deployment *appsv1.Deployment
+	// hpa is nil when autoscaling is disabled
+	hpa            *autoscalingv2.HorizontalPodAutoscaler
+	activeRuntimes int32
+	// runtimes are the VLLMRuntimes selected by runtimeSelector
+	runtimes []string
+	// url is the external URL served by the Ingress
+	url string
+	// externalEndpoint is the URL of the Service load balancer
+	externalEndpoint string
+}
+
 // VLLMRouterReconciler reconciles a VLLMRouter object
 type VLLMRouterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=vllmrouters,verbs=get;list;watch;create;update;patch;delete
@@ -51,6 +193,19 @@ type VLLMRouterReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,31 +227,248 @@ func (r *VLLMRouterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Check if the service already exists, if not create a new one
-	foundService := &corev1.Service{}
-	err = r.Get(ctx, types.NamespacedName{Name: router.Name, Namespace: router.Namespace}, foundService)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new service
-		svc := r.serviceForVLLMRouter(router)
-		log.Info("Creating a new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
-		err = r.Create(ctx, svc)
-		if err != nil {
-			log.Error(err, "Failed to create new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+	// The ClusterRole and ClusterRoleBinding are not garbage collected with
+	// the router
+	if !router.DeletionTimestamp.IsZero() {
+		if err := r.removeClusterRBAC(ctx, router); err != nil {
+			log.Error(err, "Failed to remove cluster-wide RBAC resources")
 			return ctrl.Result{}, err
 		}
-		// Service created successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Service")
+		return ctrl.Result{}, nil
+	}
+
+	// A disabled router only keeps its status up to date
+	if !routerEnabled(router) {
+		if err := r.reconcileDisabled(ctx, router); err != nil {
+			log.Error(err, "Failed to disable VLLMRouter")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionDisabled, nil); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+
+	// Reject specs the Deployment could not be generated from, e.g. created
+	// while the validating webhook was unavailable
+	if invalidCondition := routerSpecInvalidCondition(router); invalidCondition != nil {
+		if previous := meta.FindStatusCondition(router.Status.Conditions, invalidCondition.Type); previous == nil || previous.Reason != invalidCondition.Reason {
+			r.Recorder.Event(router, corev1.EventTypeWarning, invalidCondition.Reason, invalidCondition.Message)
+		}
+		if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionSpecInvalid, invalidCondition); err != nil {
+			log.Error(err, "Failed to update VLLMRouter status")
+			return ctrl.Result{}, err
+		}
+		log.Info("VLLMRouter spec is invalid", "Message", invalidCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionSpecInvalid, nil); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+
+	// Report the flags the router release does not accept
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionRouterArgsOmitted, routerArgsOmittedCondition(router)); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+
+	// Without affinity, the replicas of a session router make independent
+	// routing decisions for the requests of a client
+	if router.Spec.RoutingLogic == "session" && router.Spec.Replicas > 1 && routerSessionAffinity(router) == corev1.ServiceAffinityNone {
+		r.Recorder.Event(router, corev1.EventTypeWarning, "SessionAffinityDisabled",
+			"session routing with multiple replicas and service.sessionAffinity None does not keep sessions on one router replica")
+	}
+
+	// Apply the service. Server-side apply keeps the allocated clusterIP and
+	// fixes drift in ports, selector, labels and session affinity.
+	svc := r.serviceForVLLMRouter(router)
+	if err := r.Patch(ctx, svc, client.Apply, vllmRouterFieldOwner, client.ForceOwnership); err != nil {
+		log.Error(err, "Failed to apply Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		return ctrl.Result{}, err
+	}
+	externalEndpoint := routerExternalEndpoint(router, svc)
+
+	// Reconcile the Ingress exposing the service
+	if err := reconcileIngress(ctx, r.Client, r.Scheme, router, router.Spec.Ingress, router.Name, routerPortName(router)); err != nil {
+		log.Error(err, "Failed to reconcile Ingress")
+		return ctrl.Result{}, err
+	}
+	url, err := ingressLoadBalancerURL(ctx, r.Client, router.Namespace, router.Spec.Ingress, router.Name)
+	if err != nil {
+		log.Error(err, "Failed to get Ingress")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the HTTPRoute exposing the service through a Gateway
+	httpRouteCondition, err := reconcileHTTPRoute(ctx, r.Client, r.Scheme, router, router.Spec.HTTPRoute, router.Name, routerServicePort(router))
+	if err != nil {
+		log.Error(err, "Failed to reconcile HTTPRoute")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionGatewayAPIUnavailable, httpRouteCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+	acceptedCondition, err := r.httpRouteAcceptedCondition(ctx, router, httpRouteCondition == nil)
+	if err != nil {
+		log.Error(err, "Failed to get HTTPRoute")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionHTTPRouteAccepted, acceptedCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the ServiceMonitor scraping the router metrics
+	monitoringCondition, err := reconcileServiceMonitor(ctx, r.Client, r.Scheme, router, router.Spec.Monitoring, router.Name, map[string]string{"app": router.Name}, routerPortName(router))
+	if err != nil {
+		log.Error(err, "Failed to reconcile ServiceMonitor")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionMonitoringCRDsMissing, monitoringCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+
+	// Apply the ServiceAccount, Role and RoleBinding used for service discovery
+	if rbacEnabled(router) {
+		if err := r.applyRBAC(ctx, router); err != nil {
+			log.Error(err, "Failed to apply RBAC resources")
+			return ctrl.Result{}, err
+		}
+	} else if err := r.removeClusterRBAC(ctx, router); err != nil {
+		log.Error(err, "Failed to remove cluster-wide RBAC resources")
+		return ctrl.Result{}, err
+	}
+
+	// Provision the volume of the batch API files
+	if err := r.reconcileFileStorage(ctx, router); err != nil {
+		log.Error(err, "Failed to reconcile the file storage PersistentVolumeClaim")
+		return ctrl.Result{}, err
+	}
+
+	// Protect multi-replica routers from voluntary disruptions such as node drains
+	pdbEnabled := router.Spec.PodDisruptionBudget.Enabled && router.Spec.Replicas > 1
+	if router.Spec.PodDisruptionBudget.Enabled && !pdbEnabled {
+		r.Recorder.Eventf(router, corev1.EventTypeWarning, "PodDisruptionBudgetSkipped",
+			"podDisruptionBudget requires more than one replica, the router has %d", router.Spec.Replicas)
+	}
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, router, router.Spec.PodDisruptionBudget, pdbEnabled, router.Name, map[string]string{"app": router.Name}); err != nil {
+		log.Error(err, "Failed to reconcile PodDisruptionBudget")
+		return ctrl.Result{}, err
+	}
+
+	// Scale the router with a HorizontalPodAutoscaler, or a KEDA ScaledObject.
+	// The HorizontalPodAutoscaler is removed first since KEDA creates its own.
+	hpaSpec := router.Spec.Autoscaling
+	hpaSpec.Enabled = hpaSpec.Enabled && !hpaSpec.ScaledObject.Enabled
+	hpa, err := reconcileHorizontalPodAutoscaler(ctx, r.Client, r.Scheme, router, hpaSpec, "Deployment", router.Name, map[string]string{"app": router.Name})
+	if err != nil {
+		log.Error(err, "Failed to reconcile HorizontalPodAutoscaler")
+		return ctrl.Result{}, err
+	}
+	scaledObject, kedaCondition, err := reconcileScaledObject(ctx, r.Client, r.Scheme, router, router.Spec.Autoscaling, router.Name, map[string]string{"app": router.Name})
+	if err != nil {
+		log.Error(err, "Failed to reconcile ScaledObject")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionKEDAUnavailable, kedaCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+	// Report the HorizontalPodAutoscaler created by KEDA
+	if scaledObject != nil {
+		if hpaName := scaledObjectHPAName(scaledObject); hpaName != "" {
+			kedaHPA := &autoscalingv2.HorizontalPodAutoscaler{}
+			err := r.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: router.Namespace}, kedaHPA)
+			if err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to get the HorizontalPodAutoscaler of the ScaledObject")
+				return ctrl.Result{}, err
+			}
+			if err == nil {
+				hpa = kedaHPA
+			}
+		}
+	}
+
+	// Generate the backends from the selected VLLMRuntimes
+	backends, err := r.resolveBackends(ctx, router)
+	if err != nil {
+		log.Error(err, "Failed to resolve the backends from runtimeSelector")
+		return ctrl.Result{}, err
+	}
+	if backends != nil && len(backends.runtimes) == 0 {
+		// The router cannot start without backends, wait for a runtime to match
+		log.Info("No VLLMRuntime matches runtimeSelector")
+		if err := r.updateStatus(ctx, router, routerObservedState{deployment: &appsv1.Deployment{}, hpa: hpa, url: url, externalEndpoint: externalEndpoint}); err != nil {
+			log.Error(err, "Failed to update VLLMRouter status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Resolve the dynamic config ConfigMap
+	dynamicConfigCondition, err := r.resolveDynamicConfig(ctx, router)
+	if err != nil {
+		log.Error(err, "Failed to resolve dynamic config ConfigMap")
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionDynamicConfigResolved, dynamicConfigCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
+		return ctrl.Result{}, err
+	}
+	if dynamicConfigCondition != nil && dynamicConfigCondition.Status != metav1.ConditionTrue {
+		// The ConfigMap watch requeues the router once the config exists
+		log.Info("Waiting for dynamic config", "Message", dynamicConfigCondition.Message)
+		return ctrl.Result{}, nil
+	}
+
+	// Resolve the API key Secret
+	apiKeyHash, secretCondition, err := r.resolveAPIKeySecret(ctx, router)
+	if err != nil {
+		log.Error(err, "Failed to resolve API key Secret")
+		return ctrl.Result{}, err
+	}
+
+	// Resolve the TLS Secret
+	tlsHash, tlsCondition, err := r.resolveTLSSecret(ctx, router)
+	if err != nil {
+		log.Error(err, "Failed to resolve TLS Secret")
+		return ctrl.Result{}, err
+	}
+	if secretCondition == nil {
+		secretCondition = tlsCondition
+	}
+	if secretCondition != nil {
+		if previous := meta.FindStatusCondition(router.Status.Conditions, secretCondition.Type); previous == nil || previous.Reason != secretCondition.Reason {
+			r.Recorder.Event(router, corev1.EventTypeWarning, secretCondition.Reason, secretCondition.Message)
+		}
+	}
+	if err := r.reconcileCondition(ctx, router, servingv1alpha1.ConditionSecretMissing, secretCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRouter status")
 		return ctrl.Result{}, err
 	}
+	if secretCondition != nil {
+		// The Secret watch requeues the router once the key exists
+		log.Info("Waiting for Secret", "Message", secretCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	inputs := &routerInputs{backends: backends, annotations: map[string]string{}}
+	if router.Spec.RestartOnSecretChange && apiKeyHash != "" {
+		inputs.annotations[apiKeyHashAnnotation] = apiKeyHash
+	}
+	if tlsHash != "" {
+		inputs.annotations[tlsHashAnnotation] = tlsHash
+	}
 
 	// Check if the deployment already exists, if not create a new one
 	found := &appsv1.Deployment{}
 	err = r.Get(ctx, types.NamespacedName{Name: router.Name, Namespace: router.Namespace}, found)
 	if err != nil && errors.IsNotFound(err) {
 		// Define a new deployment
-		dep := r.deploymentForVLLMRouter(router)
+		dep := r.deploymentForVLLMRouter(router, inputs)
 		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
 		err = r.Create(ctx, dep)
 		if err != nil {
@@ -111,10 +483,13 @@ func (r *VLLMRouterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Update the deployment if needed
-	if r.deploymentNeedsUpdate(found, router) {
+	if r.deploymentNeedsUpdate(found, router, inputs) {
 		log.Info("Updating Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
 		// Create new deployment spec
-		newDep := r.deploymentForVLLMRouter(router)
+		newDep := r.deploymentForVLLMRouter(router, inputs)
+		if replicasManagedExternally(router) {
+			newDep.Spec.Replicas = found.Spec.Replicas
+		}
 
 		err = r.Update(ctx, newDep)
 		if err != nil {
@@ -125,29 +500,372 @@ func (r *VLLMRouterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Count the backends available to the router
+	activeRuntimes, err := r.countActiveRuntimes(ctx, router, backends)
+	if err != nil {
+		log.Error(err, "Failed to count active runtimes")
+		return ctrl.Result{}, err
+	}
+
 	// Update the status
-	if err := r.updateStatus(ctx, router, found); err != nil {
+	observed := routerObservedState{
+		deployment:       found,
+		hpa:              hpa,
+		activeRuntimes:   activeRuntimes,
+		url:              url,
+		externalEndpoint: externalEndpoint,
+	}
+	if backends != nil {
+		observed.runtimes = backends.runtimes
+	}
+	if err := r.updateStatus(ctx, router, observed); err != nil {
 		log.Error(err, "Failed to update VLLMRouter status")
 		return ctrl.Result{}, err
 	}
 
+	// Wait for the cloud provider to assign the load balancer address
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && externalEndpoint == "" {
+		return ctrl.Result{RequeueAfter: loadBalancerRequeuePeriod}, nil
+	}
+
+	// Pods are not watched, recount the discovered runtimes periodically
+	if router.Spec.ServiceDiscovery == "k8s" {
+		return ctrl.Result{RequeueAfter: activeRuntimesResyncPeriod}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
-// deploymentForVLLMRouter returns a VLLMRouter Deployment object
-func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.VLLMRouter) *appsv1.Deployment {
+// routerEnabled reports whether the router Deployment and Service are deployed
+func routerEnabled(router *servingv1alpha1.VLLMRouter) bool {
+	return router.Spec.EnableRouter == nil || *router.Spec.EnableRouter
+}
+
+// reconcileDisabled deletes the Deployment, Service, autoscalers, disruption
+// budget, routes and ServiceMonitor of a disabled router and reports it in
+// the status
+func (r *VLLMRouterReconciler) reconcileDisabled(ctx context.Context, router *servingv1alpha1.VLLMRouter) error {
+	log := log.FromContext(ctx)
+
+	// The helpers delete the children of disabled features, so that a
+	// HorizontalPodAutoscaler does not target the deleted Deployment and an
+	// Ingress does not publish the deleted Service
+	selector := map[string]string{"app": router.Name}
+	if _, err := reconcileHorizontalPodAutoscaler(ctx, r.Client, r.Scheme, router, servingv1alpha1.AutoscalingSpec{}, "Deployment", router.Name, selector); err != nil {
+		return err
+	}
+	if _, _, err := reconcileScaledObject(ctx, r.Client, r.Scheme, router, servingv1alpha1.AutoscalingSpec{}, router.Name, selector); err != nil {
+		return err
+	}
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, router, servingv1alpha1.PodDisruptionBudgetSpec{}, false, router.Name, selector); err != nil {
+		return err
+	}
+	if err := reconcileIngress(ctx, r.Client, r.Scheme, router, servingv1alpha1.IngressSpec{}, router.Name, routerPortName(router)); err != nil {
+		return err
+	}
+	if _, err := reconcileHTTPRoute(ctx, r.Client, r.Scheme, router, servingv1alpha1.HTTPRouteSpec{}, router.Name, routerServicePort(router)); err != nil {
+		return err
+	}
+	if _, err := reconcileServiceMonitor(ctx, r.Client, r.Scheme, router, servingv1alpha1.MonitoringSpec{}, router.Name, selector, routerPortName(router)); err != nil {
+		return err
+	}
+
+	key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+	children := []struct {
+		kind string
+		obj  client.Object
+	}{
+		{"Deployment", &appsv1.Deployment{}},
+		{"Service", &corev1.Service{}},
+	}
+	for _, child := range children {
+		if err := r.Get(ctx, key, child.obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(child.obj, router) {
+			continue
+		}
+		log.Info("Deleting "+child.kind+" of the disabled VLLMRouter", child.kind+".Namespace", router.Namespace, child.kind+".Name", router.Name)
+		if err := r.Delete(ctx, child.obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the VLLMRouter
+		latestRouter := &servingv1alpha1.VLLMRouter{}
+		if err := r.Get(ctx, key, latestRouter); err != nil {
+			return err
+		}
+
+		latestRouter.Status.Status = "Disabled"
+		latestRouter.Status.LastUpdated = metav1.Now()
+		latestRouter.Status.ObservedGeneration = router.Generation
+		latestRouter.Status.ServiceEndpoint = ""
+		latestRouter.Status.ExternalEndpoint = ""
+		latestRouter.Status.Replicas = 0
+		latestRouter.Status.ReadyReplicas = 0
+		meta.SetStatusCondition(&latestRouter.Status.Conditions, metav1.Condition{
+			Type:               servingv1alpha1.ConditionDisabled,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RouterDisabled",
+			Message:            "enableRouter is false, the router Deployment and Service are removed",
+			ObservedGeneration: router.Generation,
+		})
+		meta.SetStatusCondition(&latestRouter.Status.Conditions, metav1.Condition{
+			Type:               servingv1alpha1.ConditionAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             "RouterDisabled",
+			Message:            "The router is disabled",
+			ObservedGeneration: router.Generation,
+		})
+		meta.RemoveStatusCondition(&latestRouter.Status.Conditions, servingv1alpha1.ConditionProgressing)
+		return r.Status().Update(ctx, latestRouter)
+	})
+}
+
+// resolveAPIKeySecret returns the hash of the API key of the router and a
+// SecretMissing condition if the Secret or key does not exist
+func (r *VLLMRouterReconciler) resolveAPIKeySecret(ctx context.Context, router *servingv1alpha1.VLLMRouter) (string, *metav1.Condition, error) {
+	if router.Spec.VLLMApiKeySecret.Name == "" || router.Spec.VLLMApiKeyName == "" {
+		return "", nil, nil
+	}
+
+	apiKey, condition, err := resolveSecretKey(ctx, r.Client, router.Namespace, router.Spec.VLLMApiKeySecret.Name, router.Spec.VLLMApiKeyName)
+	if err != nil || condition != nil {
+		return "", condition, err
+	}
+	return hashString(string(apiKey)), nil, nil
+}
+
+// resolveTLSSecret returns the hash of the certificate and key served by the
+// router and a SecretMissing condition if the Secret or a key does not exist
+func (r *VLLMRouterReconciler) resolveTLSSecret(ctx context.Context, router *servingv1alpha1.VLLMRouter) (string, *metav1.Condition, error) {
+	tls := router.Spec.TLS
+	if !tls.Enabled {
+		return "", nil, nil
+	}
+
+	cert, condition, err := resolveSecretKey(ctx, r.Client, router.Namespace, tls.SecretName, tls.CertKey)
+	if err != nil || condition != nil {
+		return "", condition, err
+	}
+	key, condition, err := resolveSecretKey(ctx, r.Client, router.Namespace, tls.SecretName, tls.KeyKey)
+	if err != nil || condition != nil {
+		return "", condition, err
+	}
+	return hashString(string(cert) + string(key)), nil, nil
+}
+
+// resolveDynamicConfig returns a DynamicConfigResolved condition for the
+// ConfigMap referenced by dynamicConfig, or nil when it is disabled.
+func (r *VLLMRouterReconciler) resolveDynamicConfig(ctx context.Context, router *servingv1alpha1.VLLMRouter) (*metav1.Condition, error) {
+	ref := router.Spec.DynamicConfig
+	if !ref.Enabled {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: ref.ConfigMapName, Namespace: router.Namespace}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		return &metav1.Condition{
+			Type:    servingv1alpha1.ConditionDynamicConfigResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ConfigMapNotFound",
+			Message: fmt.Sprintf("ConfigMap %s not found", ref.ConfigMapName),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if _, ok := configMap.Data[ref.Key]; !ok {
+		return &metav1.Condition{
+			Type:    servingv1alpha1.ConditionDynamicConfigResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "KeyNotFound",
+			Message: fmt.Sprintf("ConfigMap %s has no key %s", ref.ConfigMapName, ref.Key),
+		}, nil
+	}
+
+	return &metav1.Condition{
+		Type:    servingv1alpha1.ConditionDynamicConfigResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "DynamicConfigFound",
+		Message: fmt.Sprintf("Using dynamic config %s/%s", ref.ConfigMapName, ref.Key),
+	}, nil
+}
+
+// routerSpecInvalidCondition returns a SpecInvalid condition if a resource
+// quantity cannot be parsed, static service discovery is selected without
+// backends, a user volume uses the reserved name or a volume mount references
+// an unknown volume, or nil otherwise.
+func routerSpecInvalidCondition(router *servingv1alpha1.VLLMRouter) *metav1.Condition {
+	if condition := invalidQuantityCondition(map[string]string{
+		"resources.cpu":    router.Spec.Resources.CPU,
+		"resources.memory": router.Spec.Resources.Memory,
+	}); condition != nil {
+		return condition
+	}
+
+	if router.Spec.ServiceDiscovery == "static" && router.Spec.RuntimeSelector == nil &&
+		(router.Spec.StaticBackends == "" || router.Spec.StaticModels == "") {
+		return &metav1.Condition{
+			Type:    servingv1alpha1.ConditionSpecInvalid,
+			Status:  metav1.ConditionTrue,
+			Reason:  "StaticBackendsMissing",
+			Message: "static service discovery requires both staticBackends and staticModels",
+		}
+	}
+
+	if router.Spec.ServiceDiscovery == "k8s" {
+		if _, err := labels.Parse(router.Spec.K8sLabelSelector); err != nil {
+			return &metav1.Condition{
+				Type:    servingv1alpha1.ConditionSpecInvalid,
+				Status:  metav1.ConditionTrue,
+				Reason:  "InvalidLabelSelector",
+				Message: fmt.Sprintf("k8sLabelSelector %q does not parse: %v", router.Spec.K8sLabelSelector, err),
+			}
+		}
+	}
+
+	declared := make(map[string]bool, len(router.Spec.Volumes))
+	for _, v := range router.Spec.Volumes {
+		if v.Name == dynamicConfigVolumeName || v.Name == tmpVolumeName || v.Name == tlsVolumeName || v.Name == filesVolumeName {
+			return &metav1.Condition{
+				Type:    servingv1alpha1.ConditionSpecInvalid,
+				Status:  metav1.ConditionTrue,
+				Reason:  "ReservedVolumeName",
+				Message: fmt.Sprintf("volume name %s is reserved for the operator", v.Name),
+			}
+		}
+		declared[v.Name] = true
+	}
+	for _, m := range router.Spec.VolumeMounts {
+		if !declared[m.Name] {
+			return &metav1.Condition{
+				Type:    servingv1alpha1.ConditionSpecInvalid,
+				Status:  metav1.ConditionTrue,
+				Reason:  "UnknownVolume",
+				Message: fmt.Sprintf("volume mount %s references undeclared volume %s", m.MountPath, m.Name),
+			}
+		}
+	}
+
+	if routerWatchesOtherNamespaces(router) && rbacEnabled(router) && !router.Spec.RBAC.ClusterWide {
+		return &metav1.Condition{
+			Type:    servingv1alpha1.ConditionSpecInvalid,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ClusterWideRBACRequired",
+			Message: "watchNamespaces beyond the router namespace requires rbac.clusterWide",
+		}
+	}
+	return nil
+}
+
+// resolveBackends generates the backends of the router from the VLLMRuntimes
+// selected by runtimeSelector. It returns nil when runtimeSelector is not set.
+func (r *VLLMRouterReconciler) resolveBackends(ctx context.Context, router *servingv1alpha1.VLLMRouter) (*routerBackends, error) {
+	if router.Spec.RuntimeSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(router.Spec.RuntimeSelector)
+	if err != nil {
+		return nil, err
+	}
+	vllmRuntimes := &servingv1alpha1.VLLMRuntimeList{}
+	if err := r.List(ctx, vllmRuntimes, client.InNamespace(router.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	sort.Slice(vllmRuntimes.Items, func(i, j int) bool {
+		return vllmRuntimes.Items[i].Name < vllmRuntimes.Items[j].Name
+	})
+
+	var endpoints, models []string
+	backends := &routerBackends{}
+	for i := range vllmRuntimes.Items {
+		vr := &vllmRuntimes.Items[i]
+		if vr.DeletionTimestamp != nil {
+			continue
+		}
+		endpoints = append(endpoints, runtimeServiceEndpoint(vr))
+		models = append(models, servedModelName(vr.Spec.Model))
+		backends.runtimes = append(backends.runtimes, vr.Name)
+	}
+	backends.backends = strings.Join(endpoints, ",")
+	backends.models = strings.Join(models, ",")
+	return backends, nil
+}
+
+// countActiveRuntimes returns the number of backends available to the router:
+// the ready pods matching k8sLabelSelector for k8s service discovery, or the
+// configured backends for static service discovery
+func (r *VLLMRouterReconciler) countActiveRuntimes(ctx context.Context, router *servingv1alpha1.VLLMRouter, backends *routerBackends) (int32, error) {
+	switch router.Spec.ServiceDiscovery {
+	case "static":
+		if backends != nil {
+			return int32(len(backends.runtimes)), nil
+		}
+		var count int32
+		for _, backend := range strings.Split(router.Spec.StaticBackends, ",") {
+			if strings.TrimSpace(backend) != "" {
+				count++
+			}
+		}
+		return count, nil
+	case "k8s":
+		selector, err := labels.Parse(router.Spec.K8sLabelSelector)
+		if err != nil {
+			return 0, err
+		}
+		var count int32
+		for _, namespace := range routerWatchNamespaces(router) {
+			if namespace == "*" {
+				namespace = metav1.NamespaceAll
+			}
+			pods := &corev1.PodList{}
+			if err := r.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+				return 0, err
+			}
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp == nil && podReady(&pod) {
+					count++
+				}
+			}
+		}
+		return count, nil
+	}
+	return 0, nil
+}
+
+// podReady reports whether the pod has the Ready condition
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// deploymentForVLLMRouter returns a VLLMRouter Deployment object. backends
+// replace the static backends when runtimeSelector is set.
+func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.VLLMRouter, inputs *routerInputs) *appsv1.Deployment {
 	labels := map[string]string{
 		"app": router.Name,
 	}
+	if inputs == nil {
+		inputs = &routerInputs{}
+	}
+	backends := inputs.backends
 
 	// Add user-defined environment variables
 	env := []corev1.EnvVar{}
 	if router.Spec.Env != nil {
 		for _, e := range router.Spec.Env {
-			env = append(env, corev1.EnvVar{
-				Name:  e.Name,
-				Value: e.Value,
-			})
+			env = append(env, containerEnvVar(e))
 		}
 	}
 
@@ -170,18 +888,12 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 		Limits:   corev1.ResourceList{},
 	}
 
-	if router.Spec.Resources.CPU != "" {
-		resources.Requests[corev1.ResourceCPU] = resource.MustParse(router.Spec.Resources.CPU)
-		resources.Limits[corev1.ResourceCPU] = resource.MustParse(router.Spec.Resources.CPU)
-	}
-
-	if router.Spec.Resources.Memory != "" {
-		resources.Requests[corev1.ResourceMemory] = resource.MustParse(router.Spec.Resources.Memory)
-		resources.Limits[corev1.ResourceMemory] = resource.MustParse(router.Spec.Resources.Memory)
-	}
+	// Reconcile stops at unparsable quantities, so none are left out here
+	setResourceQuantity(&resources, corev1.ResourceCPU, router.Spec.Resources.CPU)
+	setResourceQuantity(&resources, corev1.ResourceMemory, router.Spec.Resources.Memory)
 
 	// Get the image from Image spec or use default
-	image := router.Spec.Image.Registry + "/" + router.Spec.Image.Name
+	image := imageReference(router.Spec.Image)
 
 	// Get the image pull policy
 	imagePullPolicy := corev1.PullIfNotPresent
@@ -207,17 +919,17 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 	// Add service discovery specific args
 	if router.Spec.ServiceDiscovery == "k8s" {
 		args = append(args,
-			"--k8s-namespace", router.Namespace,
+			"--k8s-namespace", strings.Join(routerWatchNamespaces(router), ","),
 			"--k8s-label-selector", router.Spec.K8sLabelSelector,
 		)
 	} else if router.Spec.ServiceDiscovery == "static" {
-		if router.Spec.StaticBackends == "" || router.Spec.StaticModels == "" {
-			// This should be handled by validation webhook
-			panic("static service discovery requires both staticBackends and staticModels")
+		staticBackends, staticModels := router.Spec.StaticBackends, router.Spec.StaticModels
+		if backends != nil {
+			staticBackends, staticModels = backends.backends, backends.models
 		}
 		args = append(args,
-			"--static-backends", router.Spec.StaticBackends,
-			"--static-models", router.Spec.StaticModels,
+			"--static-backends", staticBackends,
+			"--static-models", staticModels,
 		)
 	}
 
@@ -228,33 +940,152 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 	if router.Spec.SessionKey != "" {
 		args = append(args, "--session-key", router.Spec.SessionKey)
 	}
+	switch router.Spec.RoutingLogic {
+	case "kvaware":
+		args = append(args, "--lmcache-controller-port", fmt.Sprintf("%d", lmcacheControllerPort(router)))
+	case "disaggregated_prefill":
+		args = append(args,
+			"--prefill-model-labels", strings.Join(router.Spec.PrefillModelLabels, ","),
+			"--decode-model-labels", strings.Join(router.Spec.DecodeModelLabels, ","),
+		)
+	}
 	if router.Spec.EngineScrapeInterval != 0 {
 		args = append(args, "--engine-stats-interval", fmt.Sprintf("%d", router.Spec.EngineScrapeInterval))
 	}
 	if router.Spec.RequestStatsWindow != 0 {
 		args = append(args, "--request-stats-window", fmt.Sprintf("%d", router.Spec.RequestStatsWindow))
 	}
+	if router.Spec.LogLevel != "" {
+		args = append(args, "--log-level", router.Spec.LogLevel)
+	}
+	versionedArgs, _ := routerVersionedArgs(router)
+	args = append(args, versionedArgs...)
+	if router.Spec.DynamicConfig.Enabled {
+		args = append(args, "--dynamic-config-json", path.Join(dynamicConfigMountPath, router.Spec.DynamicConfig.Key))
+	}
+	if router.Spec.TLS.Enabled {
+		args = append(args,
+			"--ssl-certfile", path.Join(tlsMountPath, router.Spec.TLS.CertKey),
+			"--ssl-keyfile", path.Join(tlsMountPath, router.Spec.TLS.KeyKey),
+		)
+	}
+	if router.Spec.FeatureGates.EnableBatchAPI {
+		args = append(args, "--enable-batch-api", "--file-storage-path", router.Spec.FeatureGates.FileStorePath)
+	}
 	if router.Spec.ExtraArgs != nil {
 		args = append(args, router.Spec.ExtraArgs...)
 	}
 
-	dep := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      router.Name,
-			Namespace: router.Namespace,
+	ports := []corev1.ContainerPort{
+		{
+			Name:          routerPortName(router),
+			ContainerPort: router.Spec.Port,
+		},
+	}
+	// The engines connect to the LMCache controller of a kvaware router
+	if router.Spec.RoutingLogic == "kvaware" {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "lmcache",
+			ContainerPort: lmcacheControllerPort(router),
+		})
+	}
+
+	// Copied, the Deployment is overwritten with the server response. An
+	// autoscaled router starts from its minimum.
+	replicas := router.Spec.Replicas
+	if router.Spec.Autoscaling.Enabled {
+		replicas = 1
+		if router.Spec.Autoscaling.MinReplicas != nil {
+			replicas = *router.Spec.Autoscaling.MinReplicas
+		}
+	}
+
+	// Merge the user pod labels and annotations, the operator keys take precedence
+	podLabels := make(map[string]string, len(router.Spec.PodLabels)+len(labels))
+	for k, v := range router.Spec.PodLabels {
+		podLabels[k] = v
+	}
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+	var podAnnotations map[string]string
+	if len(router.Spec.PodAnnotations) > 0 || len(inputs.annotations) > 0 || len(router.Spec.Sidecars) > 0 {
+		podAnnotations = make(map[string]string, len(router.Spec.PodAnnotations)+len(inputs.annotations)+1)
+		for k, v := range router.Spec.PodAnnotations {
+			podAnnotations[k] = v
+		}
+		for k, v := range inputs.annotations {
+			podAnnotations[k] = v
+		}
+		if len(router.Spec.Sidecars) > 0 {
+			podAnnotations[sidecarsHashAnnotation] = sidecarsHash(router.Spec.Sidecars)
+		}
+	}
+
+	// Copy the user volumes with the defaults of the API server, so that the
+	// live Deployment compares equal
+	var volumes []corev1.Volume
+	for i := range router.Spec.Volumes {
+		volumes = append(volumes, *router.Spec.Volumes[i].DeepCopy())
+	}
+	defaultVolumeSources(volumes)
+	var volumeMounts []corev1.VolumeMount
+	for i := range router.Spec.VolumeMounts {
+		volumeMounts = append(volumeMounts, *router.Spec.VolumeMounts[i].DeepCopy())
+	}
+
+	// Let in-flight streamed responses finish: the preStop delay keeps the pod
+	// serving until it is removed from the Service endpoints
+	var lifecycle *corev1.Lifecycle
+	drainSleep := fmt.Sprintf("%d", router.Spec.Termination.DrainSleepSeconds)
+	if router.Spec.Termination.DrainEndpoint {
+		drainURL := fmt.Sprintf("%s://127.0.0.1:%d/drain", routerPortName(router), router.Spec.Port)
+		lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"python3", "-c", routerDrainScript, drainURL, drainSleep},
+				},
+			},
+		}
+	} else if router.Spec.Termination.DrainSleepSeconds > 0 {
+		lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sleep", drainSleep},
+				},
+			},
+		}
+	}
+	terminationGracePeriodSeconds := router.Spec.Termination.GracePeriodSeconds
+
+	// The API server defaults the pod security context to an empty one
+	podSecurityContext := &corev1.PodSecurityContext{}
+	if router.Spec.PodSecurityContext != nil {
+		podSecurityContext = router.Spec.PodSecurityContext.DeepCopy()
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      router.Name,
+			Namespace: router.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &router.Spec.Replicas,
+			Replicas: &replicas,
+			Strategy: deploymentStrategy(router.Spec.DeployStrategy, router.Spec.RollingUpdate),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      podLabels,
+					Annotations: podAnnotations,
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: router.Spec.ServiceAccountName,
-					ImagePullSecrets:   imagePullSecrets,
+					ServiceAccountName:            routerServiceAccountName(router),
+					ImagePullSecrets:              imagePullSecrets,
+					Volumes:                       volumes,
+					SecurityContext:               podSecurityContext,
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
 					Containers: []corev1.Container{
 						{
 							Name:            "router",
@@ -262,24 +1093,13 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 							ImagePullPolicy: imagePullPolicy,
 							Args:            args,
 							Env:             env,
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: router.Spec.Port,
-								},
-							},
-							Resources: resources,
-							LivenessProbe: &corev1.Probe{
-								InitialDelaySeconds: 30,
-								PeriodSeconds:       5,
-								FailureThreshold:    3,
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/health",
-										Port: intstr.FromInt(int(router.Spec.Port)),
-									},
-								},
-							},
+							Ports:           ports,
+							Resources:       resources,
+							VolumeMounts:    volumeMounts,
+							SecurityContext: router.Spec.SecurityContext.DeepCopy(),
+							Lifecycle:       lifecycle,
+							LivenessProbe:   routerProbe(router, router.Spec.Probes.Liveness, defaultRouterLivenessProbe),
+							ReadinessProbe:  routerProbe(router, router.Spec.Probes.Readiness, defaultRouterReadinessProbe),
 						},
 					},
 				},
@@ -287,6 +1107,80 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 		},
 	}
 
+	// Mount the dynamic config ConfigMap. No subPath is used so ConfigMap
+	// updates reach the file watched by the router.
+	if router.Spec.DynamicConfig.Enabled {
+		defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: dynamicConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: router.Spec.DynamicConfig.ConfigMapName,
+					},
+					DefaultMode: &defaultMode,
+				},
+			},
+		})
+		dep.Spec.Template.Spec.Containers[0].VolumeMounts = append(dep.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      dynamicConfigVolumeName,
+			MountPath: dynamicConfigMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// Mount the certificate served by the router
+	if router.Spec.TLS.Enabled {
+		defaultMode := corev1.SecretVolumeSourceDefaultMode
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: tlsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  router.Spec.TLS.SecretName,
+					DefaultMode: &defaultMode,
+				},
+			},
+		})
+		dep.Spec.Template.Spec.Containers[0].VolumeMounts = append(dep.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// Mount the files of the batch API
+	if router.Spec.FeatureGates.EnableBatchAPI {
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: filesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: routerFilesPVCName(router),
+				},
+			},
+		})
+		dep.Spec.Template.Spec.Containers[0].VolumeMounts = append(dep.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      filesVolumeName,
+			MountPath: router.Spec.FeatureGates.FileStorePath,
+		})
+	}
+
+	// A read-only root filesystem needs a writable /tmp
+	if sc := router.Spec.SecurityContext; sc != nil && sc.ReadOnlyRootFilesystem != nil && *sc.ReadOnlyRootFilesystem {
+		dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         tmpVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		dep.Spec.Template.Spec.Containers[0].VolumeMounts = append(dep.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      tmpVolumeName,
+			MountPath: tmpMountPath,
+		})
+	}
+
+	// The sidecars follow the router container
+	for i := range router.Spec.Sidecars {
+		dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, *router.Spec.Sidecars[i].DeepCopy())
+	}
+
 	// Add node affinity if specified
 	if router.Spec.NodeSelectorTerms != nil {
 		dep.Spec.Template.Spec.Affinity = &corev1.Affinity{
@@ -303,28 +1197,245 @@ func (r *VLLMRouterReconciler) deploymentForVLLMRouter(router *servingv1alpha1.V
 	return dep
 }
 
+// routerVersionedArgs returns the args of the flags only accepted by recent
+// router releases, and the flags left out because routerVersion is older than
+// routerVersionedFlagsMinVersion
+func routerVersionedArgs(router *servingv1alpha1.VLLMRouter) ([]string, []string) {
+	var flags [][]string
+	if router.Spec.RequestTimeoutSeconds != 0 {
+		flags = append(flags, []string{"--request-timeout", fmt.Sprintf("%d", router.Spec.RequestTimeoutSeconds)})
+	}
+	if router.Spec.UvicornWorkers != 0 {
+		flags = append(flags, []string{"--workers", fmt.Sprintf("%d", router.Spec.UvicornWorkers)})
+	}
+	if router.Spec.BackendTimeouts.ConnectSeconds != 0 {
+		flags = append(flags, []string{"--backend-connect-timeout", fmt.Sprintf("%d", router.Spec.BackendTimeouts.ConnectSeconds)})
+	}
+	if router.Spec.BackendTimeouts.ReadSeconds != 0 {
+		flags = append(flags, []string{"--backend-read-timeout", fmt.Sprintf("%d", router.Spec.BackendTimeouts.ReadSeconds)})
+	}
+	if router.Spec.RetryPolicy.MaxRetries != 0 {
+		flags = append(flags, []string{"--max-retries", fmt.Sprintf("%d", router.Spec.RetryPolicy.MaxRetries)})
+	}
+	if router.Spec.RetryPolicy.RetryOn5xx {
+		flags = append(flags, []string{"--retry-on-5xx"})
+	}
+	if router.Spec.MaxConcurrentRequests != 0 {
+		flags = append(flags, []string{"--max-concurrent-requests", fmt.Sprintf("%d", router.Spec.MaxConcurrentRequests)})
+	}
+
+	// The CRD pattern only admits versions ParseGeneric accepts
+	routerVersion, _ := version.ParseGeneric(router.Spec.RouterVersion)
+	var args, omitted []string
+	for _, flag := range flags {
+		if routerVersion != nil && routerVersion.LessThan(routerVersionedFlagsMinVersion) {
+			omitted = append(omitted, flag[0])
+			continue
+		}
+		args = append(args, flag...)
+	}
+	return args, omitted
+}
+
+// routerArgsOmittedCondition returns a RouterArgsOmitted condition listing
+// the flags left out of the router args, or nil if there are none
+func routerArgsOmittedCondition(router *servingv1alpha1.VLLMRouter) *metav1.Condition {
+	_, omitted := routerVersionedArgs(router)
+	if len(omitted) == 0 {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:   servingv1alpha1.ConditionRouterArgsOmitted,
+		Status: metav1.ConditionTrue,
+		Reason: "RouterVersionTooOld",
+		Message: fmt.Sprintf("routerVersion %s does not accept %s, router releases from %s do",
+			router.Spec.RouterVersion, strings.Join(omitted, ", "), routerVersionedFlagsMinVersion),
+	}
+}
+
+// lmcacheControllerPort returns the port of the LMCache controller of a kvaware router
+func lmcacheControllerPort(router *servingv1alpha1.VLLMRouter) int32 {
+	if router.Spec.LMCacheControllerPort != 0 {
+		return router.Spec.LMCacheControllerPort
+	}
+	return defaultLMCacheControllerPort
+}
+
+// routerProbe returns a probe against the /health endpoint of the router,
+// with the settings not set taken from defaults
+func routerProbe(router *servingv1alpha1.VLLMRouter, settings servingv1alpha1.ProbeSettings, defaults corev1.Probe) *corev1.Probe {
+	probe := defaults
+	if settings.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *settings.InitialDelaySeconds
+	}
+	if settings.PeriodSeconds != nil {
+		probe.PeriodSeconds = *settings.PeriodSeconds
+	}
+	if settings.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *settings.TimeoutSeconds
+	}
+	if settings.FailureThreshold != nil {
+		probe.FailureThreshold = *settings.FailureThreshold
+	}
+	// Fields defaulted by the API server are set so the deployment diff is stable
+	probe.SuccessThreshold = 1
+
+	scheme := corev1.URISchemeHTTP
+	if router.Spec.TLS.Enabled {
+		scheme = corev1.URISchemeHTTPS
+	}
+	probe.ProbeHandler = corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   "/health",
+			Port:   intstr.FromInt(int(router.Spec.Port)),
+			Scheme: scheme,
+		},
+	}
+	return &probe
+}
+
 // deploymentNeedsUpdate checks if the deployment needs to be updated
-func (r *VLLMRouterReconciler) deploymentNeedsUpdate(dep *appsv1.Deployment, router *servingv1alpha1.VLLMRouter) bool {
+func (r *VLLMRouterReconciler) deploymentNeedsUpdate(dep *appsv1.Deployment, router *servingv1alpha1.VLLMRouter, inputs *routerInputs) bool {
 	// Generate the expected deployment
-	expectedDep := r.deploymentForVLLMRouter(router)
+	expectedDep := r.deploymentForVLLMRouter(router, inputs)
+
+	// Compare replicas unless they are managed externally
+	if !replicasManagedExternally(router) && (dep.Spec.Replicas == nil || *dep.Spec.Replicas != router.Spec.Replicas) {
+		return true
+	}
+
+	// Compare the rollout and shutdown settings
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Strategy, dep.Spec.Strategy) ||
+		!equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.TerminationGracePeriodSeconds, dep.Spec.Template.Spec.TerminationGracePeriodSeconds) ||
+		!equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].Lifecycle, dep.Spec.Template.Spec.Containers[0].Lifecycle) {
+		return true
+	}
 
 	// Compare image
 	if expectedDep.Spec.Template.Spec.Containers[0].Image != dep.Spec.Template.Spec.Containers[0].Image {
 		return true
 	}
 
+	// Compare pod identity and registry credentials
+	if expectedDep.Spec.Template.Spec.ServiceAccountName != dep.Spec.Template.Spec.ServiceAccountName {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.ImagePullSecrets, dep.Spec.Template.Spec.ImagePullSecrets) {
+		return true
+	}
+
 	// Compare resources
 	expectedResources := expectedDep.Spec.Template.Spec.Containers[0].Resources
 	actualResources := dep.Spec.Template.Spec.Containers[0].Resources
-	if !reflect.DeepEqual(expectedResources, actualResources) {
+	if !equality.Semantic.DeepEqual(expectedResources, actualResources) {
+		return true
+	}
+
+	// Compare args, which carry the routing and service discovery settings
+	if !reflect.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].Args, dep.Spec.Template.Spec.Containers[0].Args) {
+		return true
+	}
+
+	// Compare env
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].Env, dep.Spec.Template.Spec.Containers[0].Env) {
+		return true
+	}
+
+	// Compare the containers. Sidecar changes are caught by the hash
+	// annotation since the API server defaults their fields.
+	if len(expectedDep.Spec.Template.Spec.Containers) != len(dep.Spec.Template.Spec.Containers) {
 		return true
 	}
 
+	// Compare pod metadata. The restartedAt annotation of kubectl rollout
+	// restart is left alone.
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Labels, dep.Spec.Template.Labels) {
+		return true
+	}
+	if podAnnotationsDiffer(expectedDep.Spec.Template.Annotations, dep.Spec.Template.Annotations) {
+		return true
+	}
+
+	// Compare volumes
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Volumes, dep.Spec.Template.Spec.Volumes) ||
+		!equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].VolumeMounts, dep.Spec.Template.Spec.Containers[0].VolumeMounts) {
+		return true
+	}
+
+	// Compare security contexts
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.SecurityContext, dep.Spec.Template.Spec.SecurityContext) ||
+		!equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].SecurityContext, dep.Spec.Template.Spec.Containers[0].SecurityContext) {
+		return true
+	}
+
+	// Compare probes
+	if !equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].LivenessProbe, dep.Spec.Template.Spec.Containers[0].LivenessProbe) ||
+		!equality.Semantic.DeepEqual(expectedDep.Spec.Template.Spec.Containers[0].ReadinessProbe, dep.Spec.Template.Spec.Containers[0].ReadinessProbe) {
+		return true
+	}
+
+	return false
+}
+
+// sidecarsHash returns the hash of the sidecar containers of a router
+func sidecarsHash(sidecars []corev1.Container) string {
+	data, _ := json.Marshal(sidecars)
+	return hashString(string(data))
+}
+
+// restartedAtAnnotation is set on the pod template by kubectl rollout restart
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// podAnnotationsDiffer reports whether the pod annotations differ between
+// expected and actual, ignoring restartedAtAnnotation
+func podAnnotationsDiffer(expected, actual map[string]string) bool {
+	for k, v := range expected {
+		if actual[k] != v {
+			return true
+		}
+	}
+	for k := range actual {
+		if _, ok := expected[k]; !ok && k != restartedAtAnnotation {
+			return true
+		}
+	}
 	return false
 }
 
+// defaultVolumeSources sets the fields of the volume sources defaulted by the
+// API server
+func defaultVolumeSources(volumes []corev1.Volume) {
+	for i := range volumes {
+		source := &volumes[i].VolumeSource
+		switch {
+		case source.ConfigMap != nil && source.ConfigMap.DefaultMode == nil:
+			mode := corev1.ConfigMapVolumeSourceDefaultMode
+			source.ConfigMap.DefaultMode = &mode
+		case source.Secret != nil && source.Secret.DefaultMode == nil:
+			mode := corev1.SecretVolumeSourceDefaultMode
+			source.Secret.DefaultMode = &mode
+		case source.Projected != nil && source.Projected.DefaultMode == nil:
+			mode := corev1.ProjectedVolumeSourceDefaultMode
+			source.Projected.DefaultMode = &mode
+		case source.DownwardAPI != nil && source.DownwardAPI.DefaultMode == nil:
+			mode := corev1.DownwardAPIVolumeSourceDefaultMode
+			source.DownwardAPI.DefaultMode = &mode
+		case source.HostPath != nil && source.HostPath.Type == nil:
+			hostPathType := corev1.HostPathUnset
+			source.HostPath.Type = &hostPathType
+		}
+	}
+}
+
+// replicasManagedExternally reports whether the replicas of an existing router
+// Deployment are left alone, e.g. to a HorizontalPodAutoscaler
+func replicasManagedExternally(router *servingv1alpha1.VLLMRouter) bool {
+	return router.Spec.IgnoreReplicaDrift || router.Spec.Autoscaling.Enabled
+}
+
 // updateStatus updates the status of the VLLMRouter
-func (r *VLLMRouterReconciler) updateStatus(ctx context.Context, router *servingv1alpha1.VLLMRouter, dep *appsv1.Deployment) error {
+func (r *VLLMRouterReconciler) updateStatus(ctx context.Context, router *servingv1alpha1.VLLMRouter, observed routerObservedState) error {
+	dep, hpa := observed.deployment, observed.hpa
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Get the latest version of the VLLMRouter
 		latestRouter := &servingv1alpha1.VLLMRouter{}
@@ -334,6 +1445,32 @@ func (r *VLLMRouterReconciler) updateStatus(ctx context.Context, router *serving
 
 		// Update the status fields
 		latestRouter.Status.LastUpdated = metav1.Now()
+		latestRouter.Status.ObservedGeneration = router.Generation
+		latestRouter.Status.ServiceEndpoint = routerServiceEndpoint(router)
+		latestRouter.Status.ExternalEndpoint = observed.externalEndpoint
+		latestRouter.Status.ActiveRuntimes = observed.activeRuntimes
+		latestRouter.Status.Runtimes = observed.runtimes
+		latestRouter.Status.URL = observed.url
+		latestRouter.Status.Replicas = dep.Status.Replicas
+		latestRouter.Status.ReadyReplicas = dep.Status.ReadyReplicas
+		latestRouter.Status.Selector = routerSelector(router)
+
+		// Report the HorizontalPodAutoscaler
+		if hpa != nil {
+			latestRouter.Status.DesiredReplicas = hpa.Status.DesiredReplicas
+			condition := autoscalingActiveCondition(hpa)
+			condition.ObservedGeneration = latestRouter.Generation
+			meta.SetStatusCondition(&latestRouter.Status.Conditions, condition)
+		} else {
+			latestRouter.Status.DesiredReplicas = 0
+			meta.RemoveStatusCondition(&latestRouter.Status.Conditions, servingv1alpha1.ConditionAutoscalingActive)
+		}
+
+		// Report the health of the Deployment and the backends
+		for _, condition := range routerStatusConditions(dep, observed.activeRuntimes) {
+			condition.ObservedGeneration = router.Generation
+			meta.SetStatusCondition(&latestRouter.Status.Conditions, condition)
+		}
 
 		// Update VLLMRouter status based on deployment status
 		if dep.Status.AvailableReplicas > 0 {
@@ -348,41 +1485,599 @@ func (r *VLLMRouterReconciler) updateStatus(ctx context.Context, router *serving
 	})
 }
 
+// routerStatusConditions maps the Deployment conditions to the Available,
+// Progressing and Degraded conditions of the router, and activeRuntimes to
+// BackendsDiscovered. A Deployment not created yet has no conditions.
+func routerStatusConditions(dep *appsv1.Deployment, activeRuntimes int32) []metav1.Condition {
+	backends := metav1.Condition{
+		Type:    servingv1alpha1.ConditionBackendsDiscovered,
+		Status:  metav1.ConditionTrue,
+		Reason:  "BackendsAvailable",
+		Message: fmt.Sprintf("%d backends available", activeRuntimes),
+	}
+	if activeRuntimes == 0 {
+		backends.Status = metav1.ConditionFalse
+		backends.Reason = "NoBackends"
+		backends.Message = "No backend is available to the router"
+	}
+
+	return append(deploymentStatusConditions(dep, "router"), backends)
+}
+
+// httpRouteAcceptedCondition summarizes the status of the router HTTPRoute.
+// It returns nil when the route is disabled or the Gateway API is unavailable.
+func (r *VLLMRouterReconciler) httpRouteAcceptedCondition(ctx context.Context, router *servingv1alpha1.VLLMRouter, gatewayAPIAvailable bool) (*metav1.Condition, error) {
+	if !router.Spec.HTTPRoute.Enabled || !gatewayAPIAvailable {
+		return nil, nil
+	}
+	route := newHTTPRoute()
+	if err := r.Get(ctx, types.NamespacedName{Name: router.Name, Namespace: router.Namespace}, route); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	condition := httpRouteAcceptedCondition(route, servingv1alpha1.ConditionHTTPRouteAccepted, len(router.Spec.HTTPRoute.ParentRefs))
+	return &condition, nil
+}
+
+// reconcileCondition records condition on the VLLMRouter status if it changed.
+// A nil condition removes any stale condition of that type.
+func (r *VLLMRouterReconciler) reconcileCondition(ctx context.Context, router *servingv1alpha1.VLLMRouter, conditionType string, condition *metav1.Condition) error {
+	if condition == nil && meta.FindStatusCondition(router.Status.Conditions, conditionType) == nil {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the VLLMRouter
+		latestRouter := &servingv1alpha1.VLLMRouter{}
+		if err := r.Get(ctx, types.NamespacedName{Name: router.Name, Namespace: router.Namespace}, latestRouter); err != nil {
+			return err
+		}
+
+		if condition == nil {
+			if !meta.RemoveStatusCondition(&latestRouter.Status.Conditions, conditionType) {
+				return nil
+			}
+		} else {
+			condition.ObservedGeneration = latestRouter.Generation
+			if !meta.SetStatusCondition(&latestRouter.Status.Conditions, *condition) {
+				return nil
+			}
+		}
+		latestRouter.Status.LastUpdated = metav1.Now()
+
+		return r.Status().Update(ctx, latestRouter)
+	})
+}
+
 // serviceForVLLMRouter returns a VLLMRouter Service object
 func (r *VLLMRouterReconciler) serviceForVLLMRouter(router *servingv1alpha1.VLLMRouter) *corev1.Service {
 	labels := map[string]string{
 		"app": router.Name,
 	}
+	serviceLabels := map[string]string{}
+	for k, v := range router.Spec.Service.Labels {
+		serviceLabels[k] = v
+	}
+	serviceLabels["app"] = router.Name
+
+	serviceType := corev1.ServiceTypeClusterIP
+	if router.Spec.Service.Type != "" {
+		serviceType = corev1.ServiceType(router.Spec.Service.Type)
+	}
 
 	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      router.Name,
-			Namespace: router.Namespace,
+			Name:        router.Name,
+			Namespace:   router.Namespace,
+			Labels:      serviceLabels,
+			Annotations: router.Spec.Service.Annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Type:     corev1.ServiceTypeClusterIP,
+			Type:     serviceType,
 			Selector: labels,
 			Ports: []corev1.ServicePort{
 				{
-					Name:       "http",
-					Port:       80,
-					TargetPort: intstr.FromInt(int(router.Spec.Port)),
+					Name:       routerPortName(router),
+					Port:       routerServicePort(router),
+					TargetPort: routerServiceTargetPort(router),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
+			SessionAffinity: routerSessionAffinity(router),
 		},
 	}
 
+	if router.Spec.RoutingLogic == "kvaware" {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+			Name:       "lmcache",
+			Port:       lmcacheControllerPort(router),
+			TargetPort: intstr.FromInt32(lmcacheControllerPort(router)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerIP = router.Spec.Service.LoadBalancerIP
+		if router.Spec.Service.LoadBalancerClass != "" {
+			svc.Spec.LoadBalancerClass = &router.Spec.Service.LoadBalancerClass
+		}
+	}
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicy(router.Spec.Service.ExternalTrafficPolicy)
+
+	if svc.Spec.SessionAffinity == corev1.ServiceAffinityClientIP {
+		timeoutSeconds := int32(corev1.DefaultClientIPServiceAffinitySeconds)
+		if router.Spec.Service.SessionAffinityTimeoutSeconds != nil {
+			timeoutSeconds = *router.Spec.Service.SessionAffinityTimeoutSeconds
+		}
+		svc.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeoutSeconds},
+		}
+	}
+
 	// Set the owner reference
 	ctrl.SetControllerReference(router, svc, r.Scheme)
 	return svc
 }
 
+// routerServicePort returns the port exposed by the router Service
+func routerServicePort(router *servingv1alpha1.VLLMRouter) int32 {
+	if router.Spec.Service.Port != 0 {
+		return router.Spec.Service.Port
+	}
+	return defaultRouterServicePort
+}
+
+// routerSelector returns the label selector of the router pods in its
+// string form
+func routerSelector(router *servingv1alpha1.VLLMRouter) string {
+	return labels.SelectorFromSet(labels.Set{"app": router.Name}).String()
+}
+
+// routerServiceEndpoint returns the in-cluster URL of the router Service
+func routerServiceEndpoint(router *servingv1alpha1.VLLMRouter) string {
+	return fmt.Sprintf("%s://%s.%s.svc:%d", routerPortName(router), router.Name, router.Namespace, routerServicePort(router))
+}
+
+// routerServiceTargetPort returns the container port targeted by the router
+// Service, the router port unless a sidecar port is named
+func routerServiceTargetPort(router *servingv1alpha1.VLLMRouter) intstr.IntOrString {
+	if router.Spec.Service.TargetPortName != "" {
+		return intstr.FromString(router.Spec.Service.TargetPortName)
+	}
+	return intstr.FromInt(int(router.Spec.Port))
+}
+
+// routerExternalEndpoint returns the URL of the load balancer of a
+// LoadBalancer router Service, or an empty string until it has an address
+func routerExternalEndpoint(router *servingv1alpha1.VLLMRouter, svc *corev1.Service) string {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		host := ingress.Hostname
+		if host == "" {
+			host = ingress.IP
+		}
+		if host != "" {
+			return fmt.Sprintf("%s://%s", routerPortName(router), net.JoinHostPort(host, strconv.Itoa(int(routerServicePort(router)))))
+		}
+	}
+	return ""
+}
+
+// routerPortName returns the name of the router port, which is also its URL
+// scheme: https when the router serves TLS, http otherwise
+func routerPortName(router *servingv1alpha1.VLLMRouter) string {
+	if router.Spec.TLS.Enabled {
+		return "https"
+	}
+	return "http"
+}
+
+// routerSessionAffinity returns the session affinity of the router Service:
+// spec.service.sessionAffinity, or ClientIP with session routing
+func routerSessionAffinity(router *servingv1alpha1.VLLMRouter) corev1.ServiceAffinity {
+	if router.Spec.Service.SessionAffinity != "" {
+		return corev1.ServiceAffinity(router.Spec.Service.SessionAffinity)
+	}
+	if router.Spec.RoutingLogic == "session" {
+		return corev1.ServiceAffinityClientIP
+	}
+	return corev1.ServiceAffinityNone
+}
+
+// rbacEnabled reports whether the controller manages the RBAC resources of
+// the router
+func rbacEnabled(router *servingv1alpha1.VLLMRouter) bool {
+	if router.Spec.RBAC.Create != nil {
+		return *router.Spec.RBAC.Create
+	}
+	return router.Spec.ServiceDiscovery == "k8s" && router.Spec.ServiceAccountName == ""
+}
+
+// routerFilesPVCName returns the PersistentVolumeClaim holding the files of
+// the batch API
+func routerFilesPVCName(router *servingv1alpha1.VLLMRouter) string {
+	if router.Spec.FileStorage.PVCName != "" {
+		return router.Spec.FileStorage.PVCName
+	}
+	return router.Name + "-files"
+}
+
+// reconcileFileStorage creates the PersistentVolumeClaim of the batch API
+// unless fileStorage.pvcName is set. When the batch API is turned off it is
+// deleted, unless retained. An existing claim is left as is since its storage
+// class cannot change.
+func (r *VLLMRouterReconciler) reconcileFileStorage(ctx context.Context, router *servingv1alpha1.VLLMRouter) error {
+	if router.Spec.FileStorage.PVCName != "" {
+		return nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: routerFilesPVCName(router), Namespace: router.Namespace}, pvc)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if !router.Spec.FeatureGates.EnableBatchAPI {
+		retain := router.Spec.FileStorage.RetainOnDisable == nil || *router.Spec.FileStorage.RetainOnDisable
+		if found && !retain && metav1.IsControlledBy(pvc, router) {
+			if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	if found {
+		return nil
+	}
+	return r.Create(ctx, r.filesPVCForVLLMRouter(router))
+}
+
+// filesPVCForVLLMRouter returns the PersistentVolumeClaim created for the
+// files of the batch API
+func (r *VLLMRouterReconciler) filesPVCForVLLMRouter(router *servingv1alpha1.VLLMRouter) *corev1.PersistentVolumeClaim {
+	size := defaultRouterFileStorageSize
+	if router.Spec.FileStorage.Size != nil {
+		size = *router.Spec.FileStorage.Size
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routerFilesPVCName(router),
+			Namespace: router.Namespace,
+			Labels:    map[string]string{"app": router.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if router.Spec.FileStorage.StorageClassName != "" {
+		pvc.Spec.StorageClassName = &router.Spec.FileStorage.StorageClassName
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(router, pvc, r.Scheme)
+	return pvc
+}
+
+// routerWatchNamespaces returns the namespaces discovered by a k8s router,
+// its own namespace unless watchNamespaces is set
+func routerWatchNamespaces(router *servingv1alpha1.VLLMRouter) []string {
+	if len(router.Spec.WatchNamespaces) == 0 {
+		return []string{router.Namespace}
+	}
+	return router.Spec.WatchNamespaces
+}
+
+// routerWatchesOtherNamespaces reports whether the router discovers pods
+// outside of its namespace
+func routerWatchesOtherNamespaces(router *servingv1alpha1.VLLMRouter) bool {
+	for _, namespace := range router.Spec.WatchNamespaces {
+		if namespace != router.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// routerClusterRBACName returns the name of the ClusterRole and
+// ClusterRoleBinding of a router, unique across namespaces
+func routerClusterRBACName(router *servingv1alpha1.VLLMRouter) string {
+	return router.Namespace + "-" + router.Name
+}
+
+// routerServiceAccountName returns the ServiceAccount the router pods run as
+func routerServiceAccountName(router *servingv1alpha1.VLLMRouter) string {
+	if router.Spec.ServiceAccountName == "" && rbacEnabled(router) {
+		return router.Name
+	}
+	return router.Spec.ServiceAccountName
+}
+
+// applyRBAC applies the ServiceAccount, Role and RoleBinding of the router,
+// or its ClusterRole and ClusterRoleBinding with rbac.clusterWide, and
+// deletes those of the other scope. Server-side apply reverts edits to the
+// rules.
+func (r *VLLMRouterReconciler) applyRBAC(ctx context.Context, router *servingv1alpha1.VLLMRouter) error {
+	// The finalizer is added first so the cluster-wide resources are never
+	// left behind
+	if router.Spec.RBAC.ClusterWide && controllerutil.AddFinalizer(router, routerClusterRBACFinalizer) {
+		if err := r.Update(ctx, router); err != nil {
+			return fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+	for _, obj := range r.rbacForVLLMRouter(router) {
+		if err := r.Patch(ctx, obj, client.Apply, vllmRouterFieldOwner, client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+	}
+
+	if !router.Spec.RBAC.ClusterWide {
+		return r.removeClusterRBAC(ctx, router)
+	}
+	objectMeta := metav1.ObjectMeta{Name: router.Name, Namespace: router.Namespace}
+	for _, obj := range []client.Object{&rbacv1.RoleBinding{ObjectMeta: objectMeta}, &rbacv1.Role{ObjectMeta: objectMeta}} {
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %T %s: %w", obj, obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// removeClusterRBAC deletes the ClusterRole and ClusterRoleBinding of the
+// router and removes its finalizer
+func (r *VLLMRouterReconciler) removeClusterRBAC(ctx context.Context, router *servingv1alpha1.VLLMRouter) error {
+	if !controllerutil.ContainsFinalizer(router, routerClusterRBACFinalizer) {
+		return nil
+	}
+	objectMeta := metav1.ObjectMeta{Name: routerClusterRBACName(router)}
+	for _, obj := range []client.Object{&rbacv1.ClusterRoleBinding{ObjectMeta: objectMeta}, &rbacv1.ClusterRole{ObjectMeta: objectMeta}} {
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %T %s: %w", obj, obj.GetName(), err)
+		}
+	}
+	controllerutil.RemoveFinalizer(router, routerClusterRBACFinalizer)
+	if err := r.Update(ctx, router); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return nil
+}
+
+// rbacForVLLMRouter returns the ServiceAccount, Role and RoleBinding that let
+// the router discover the runtimes in its namespace, or a ClusterRole and
+// ClusterRoleBinding with rbac.clusterWide
+func (r *VLLMRouterReconciler) rbacForVLLMRouter(router *servingv1alpha1.VLLMRouter) []client.Object {
+	labels := map[string]string{
+		"app": router.Name,
+	}
+	serviceAccountName := routerServiceAccountName(router)
+
+	sa := &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountName,
+			Namespace: router.Namespace,
+			Labels:    labels,
+		},
+	}
+
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods", "services"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{"discovery.k8s.io"},
+			Resources: []string{"endpointslices"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+	}
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccountName,
+			Namespace: router.Namespace,
+		},
+	}
+	ctrl.SetControllerReference(router, sa, r.Scheme)
+
+	// Cluster-scoped resources cannot be owned by the router, the finalizer
+	// deletes them
+	if router.Spec.RBAC.ClusterWide {
+		clusterRole := &rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRole",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   routerClusterRBACName(router),
+				Labels: labels,
+			},
+			Rules: rules,
+		}
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRoleBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   routerClusterRBACName(router),
+				Labels: labels,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterRole.Name,
+			},
+			Subjects: subjects,
+		}
+		return []client.Object{sa, clusterRole, clusterRoleBinding}
+	}
+
+	role := &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      router.Name,
+			Namespace: router.Namespace,
+			Labels:    labels,
+		},
+		Rules: rules,
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "RoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      router.Name,
+			Namespace: router.Namespace,
+			Labels:    labels,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     role.Name,
+		},
+		Subjects: subjects,
+	}
+
+	// Set the owner references
+	ctrl.SetControllerReference(router, role, r.Scheme)
+	ctrl.SetControllerReference(router, roleBinding, r.Scheme)
+	return []client.Object{sa, role, roleBinding}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *VLLMRouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&servingv1alpha1.VLLMRouter{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&networkingv1.Ingress{})
+
+	// HTTPRoutes can only be watched when the Gateway API CRDs are installed
+	installed, err := gatewayAPIInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Owns(newHTTPRoute())
+	}
+
+	// ServiceMonitors can only be watched when the Prometheus Operator CRDs are installed
+	installed, err = prometheusOperatorInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Owns(newServiceMonitor())
+	}
+
+	// ScaledObjects can only be watched when the KEDA CRDs are installed
+	installed, err = kedaInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Owns(newScaledObject())
+	}
+
+	return b.
+		Watches(
+			&servingv1alpha1.VLLMRuntime{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRoutersForVLLMRuntime),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRoutersForConfigMap),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRoutersForSecret),
+		).
 		Complete(r)
 }
+
+// findVLLMRoutersForConfigMap maps a ConfigMap to the VLLMRouters using it as dynamic config
+func (r *VLLMRouterReconciler) findVLLMRoutersForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	routers := &servingv1alpha1.VLLMRouterList{}
+	if err := r.List(ctx, routers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRouters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, router := range routers.Items {
+		ref := router.Spec.DynamicConfig
+		if !ref.Enabled || ref.ConfigMapName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: router.Name, Namespace: router.Namespace},
+		})
+	}
+	return requests
+}
+
+// findVLLMRoutersForSecret maps a Secret to the VLLMRouters using it as API key or TLS secret
+func (r *VLLMRouterReconciler) findVLLMRoutersForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	routers := &servingv1alpha1.VLLMRouterList{}
+	if err := r.List(ctx, routers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRouters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, router := range routers.Items {
+		usesSecret := router.Spec.VLLMApiKeySecret.Name == obj.GetName() ||
+			(router.Spec.TLS.Enabled && router.Spec.TLS.SecretName == obj.GetName())
+		if !usesSecret {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: router.Name, Namespace: router.Namespace},
+		})
+	}
+	return requests
+}
+
+// findVLLMRoutersForVLLMRuntime maps a VLLMRuntime to the VLLMRouters with a
+// runtimeSelector in its namespace. All of them are enqueued since a runtime
+// whose labels changed may no longer match a router using it.
+func (r *VLLMRouterReconciler) findVLLMRoutersForVLLMRuntime(ctx context.Context, obj client.Object) []reconcile.Request {
+	routers := &servingv1alpha1.VLLMRouterList{}
+	if err := r.List(ctx, routers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRouters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, router := range routers.Items {
+		if router.Spec.RuntimeSelector == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: router.Name, Namespace: router.Namespace},
+		})
+	}
+	return requests
+}
@@ -18,11 +18,27 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -81,4 +97,2359 @@ var _ = Describe("VLLMRouter Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	DescribeTable("When an args-affecting field changes",
+		func(mutate func(*productionstackv1alpha1.VLLMRouter)) {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "args-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:                 80,
+					ServiceDiscovery:     "static",
+					StaticBackends:       "http://runtime-a,http://runtime-b",
+					StaticModels:         "model-a,model-b",
+					RoutingLogic:         "roundrobin",
+					SessionKey:           "x-user-id",
+					EngineScrapeInterval: 30,
+					RequestStatsWindow:   60,
+					ExtraArgs:            []string{"--log-stats"},
+					Env:                  []productionstackv1alpha1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+					Image:                productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+			Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+
+			mutate(router)
+			Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		},
+		Entry("routingLogic", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "session"
+		}),
+		Entry("serviceDiscovery", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.K8sLabelSelector = "app=vllm"
+		}),
+		Entry("staticBackends", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = "http://runtime-a,http://runtime-c"
+		}),
+		Entry("staticModels", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticModels = "model-a,model-c"
+		}),
+		Entry("sessionKey", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.SessionKey = "x-session-id"
+		}),
+		Entry("logLevel", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.LogLevel = "debug"
+		}),
+		Entry("requestTimeoutSeconds", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RequestTimeoutSeconds = 300
+		}),
+		Entry("uvicornWorkers", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.UvicornWorkers = 4
+		}),
+		Entry("backendTimeouts", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.BackendTimeouts.ReadSeconds = 60
+		}),
+		Entry("retryPolicy", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RetryPolicy = productionstackv1alpha1.RouterRetryPolicy{MaxRetries: 2, RetryOn5xx: true}
+		}),
+		Entry("maxConcurrentRequests", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.MaxConcurrentRequests = 256
+		}),
+		Entry("dynamicConfig", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.DynamicConfig = productionstackv1alpha1.RouterDynamicConfigSpec{
+				Enabled:       true,
+				ConfigMapName: "router-config",
+				Key:           "dynamic_config.json",
+			}
+		}),
+		Entry("prefixaware routingLogic", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "prefixaware"
+		}),
+		Entry("kvaware routingLogic", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "kvaware"
+		}),
+		Entry("disaggregated_prefill routingLogic", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "disaggregated_prefill"
+			router.Spec.PrefillModelLabels = []string{"prefill"}
+			router.Spec.DecodeModelLabels = []string{"decode"}
+		}),
+		Entry("engineScrapeInterval", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.EngineScrapeInterval = 15
+		}),
+		Entry("requestStatsWindow", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RequestStatsWindow = 120
+		}),
+		Entry("extraArgs", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ExtraArgs = []string{"--log-stats", "--log-stats-interval", "10"}
+		}),
+		Entry("env", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Env[0].Value = "debug"
+		}),
+		Entry("vllmApiKeySecret", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.VLLMApiKeySecret.Name = "vllm-api-key"
+			router.Spec.VLLMApiKeyName = "key"
+		}),
+	)
+
+	DescribeTable("When the live Deployment drifts",
+		func(ignoreReplicaDrift bool, mutate func(*appsv1.Deployment), expected bool) {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "drift-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:               80,
+					Replicas:           2,
+					IgnoreReplicaDrift: ignoreReplicaDrift,
+					ServiceDiscovery:   "k8s",
+					K8sLabelSelector:   "app=vllm",
+					ServiceAccountName: "router",
+					Image: productionstackv1alpha1.ImageSpec{
+						Registry:       "docker.io",
+						Name:           "lmcache/lmstack-router:latest",
+						PullSecretName: "registry-credentials",
+					},
+				},
+			}
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+			mutate(dep)
+			Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(Equal(expected))
+		},
+		Entry("scaled replicas", false, func(dep *appsv1.Deployment) {
+			replicas := int32(5)
+			dep.Spec.Replicas = &replicas
+		}, true),
+		Entry("scaled replicas managed by an HPA", true, func(dep *appsv1.Deployment) {
+			replicas := int32(5)
+			dep.Spec.Replicas = &replicas
+		}, false),
+		Entry("serviceAccountName", false, func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.ServiceAccountName = "default"
+		}, true),
+		Entry("imagePullSecrets", false, func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.ImagePullSecrets = nil
+		}, true),
+		Entry("VLLM_API_KEY wiring", false, func(dep *appsv1.Deployment) {
+			dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+				Name: "VLLM_API_KEY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "old-api-key"},
+					Key:                  "key",
+				}},
+			})
+		}, true),
+	)
+
+	It("should not update the deployment for a fieldRef env without apiVersion", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fieldref-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Env: []productionstackv1alpha1.EnvVar{
+					{Name: "POD_IP", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"}}},
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+
+		// The API server defaults the apiVersion of the live deployment
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+			if e.ValueFrom != nil && e.ValueFrom.FieldRef != nil {
+				e.ValueFrom.FieldRef.APIVersion = "v1"
+			}
+		}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+		Expect(router.Spec.Env[0].ValueFrom.FieldRef.APIVersion).To(BeEmpty())
+	})
+
+	It("should probe /health and detect probe setting changes", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "probe-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.LivenessProbe.HTTPGet.Path).To(Equal("/health"))
+		Expect(container.LivenessProbe.HTTPGet.Port.IntValue()).To(Equal(8000))
+		Expect(container.LivenessProbe.InitialDelaySeconds).To(Equal(int32(30)))
+		Expect(container.LivenessProbe.PeriodSeconds).To(Equal(int32(5)))
+		Expect(container.LivenessProbe.FailureThreshold).To(Equal(int32(3)))
+		Expect(container.ReadinessProbe.HTTPGet.Path).To(Equal("/health"))
+		Expect(container.ReadinessProbe.InitialDelaySeconds).To(Equal(int32(5)))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+
+		failureThreshold := int32(10)
+		router.Spec.Probes.Readiness.FailureThreshold = &failureThreshold
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.Containers[0].ReadinessProbe.FailureThreshold).To(Equal(int32(10)))
+		Expect(dep.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold).To(Equal(int32(3)))
+	})
+
+	It("should reconcile the Service when the port or labels drift", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8000))
+
+		// Emulate the allocated clusterIP and a label removed by hand
+		svc.Spec.ClusterIP = "10.96.0.42"
+		delete(svc.Labels, "app")
+		Expect(k8sClient.Update(ctx, svc)).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Port = 8080
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(80)))
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{"app": router.Name}))
+		Expect(svc.Labels).To(HaveKeyWithValue("app", router.Name))
+		Expect(svc.Spec.ClusterIP).To(Equal("10.96.0.42"))
+	})
+
+	It("should converge the Service to the port, type and metadata of spec.service", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service-spec-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				Service: productionstackv1alpha1.RouterServiceSpec{
+					Type:        "LoadBalancer",
+					Port:        8080,
+					Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+					Labels:      map[string]string{"mesh": "inference", "app": "ignored"},
+
+					LoadBalancerIP:        "203.0.113.10",
+					LoadBalancerClass:     "service.k8s.aws/nlb",
+					ExternalTrafficPolicy: "Local",
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(8080)))
+		Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8000))
+		Expect(svc.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "true"))
+		Expect(svc.Labels).To(Equal(map[string]string{"app": router.Name, "mesh": "inference"}))
+		Expect(svc.Spec.LoadBalancerIP).To(Equal("203.0.113.10"))
+		Expect(*svc.Spec.LoadBalancerClass).To(Equal("service.k8s.aws/nlb"))
+		Expect(svc.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyLocal))
+
+		By("waiting for the load balancer address")
+		result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(loadBalancerRequeuePeriod))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.ExternalEndpoint).To(BeEmpty())
+
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+		Expect(k8sClient.Status().Update(ctx, svc)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.ExternalEndpoint).To(Equal("http://203.0.113.10:8080"))
+
+		By("switching back to the defaults")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Service = productionstackv1alpha1.RouterServiceSpec{}
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(80)))
+		Expect(svc.Annotations).NotTo(HaveKey("service.beta.kubernetes.io/aws-load-balancer-internal"))
+		Expect(svc.Labels).To(Equal(map[string]string{"app": router.Name}))
+		Expect(svc.Spec.LoadBalancerClass).To(BeNil())
+		Expect(svc.Spec.ExternalTrafficPolicy).To(BeEmpty())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.ExternalEndpoint).To(BeEmpty())
+	})
+
+	DescribeTable("When a spec field renders router args",
+		func(mutate func(*productionstackv1alpha1.VLLMRouter), expectedArgs []string) {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{Name: "routing-router", Namespace: "default"},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             8000,
+					ServiceDiscovery: "k8s",
+					K8sLabelSelector: "app=vllm",
+					Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			mutate(router)
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+			Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements(expectedArgs))
+		},
+		Entry("kvaware with the default controller port", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "kvaware"
+		}, []string{"--routing-logic", "kvaware", "--lmcache-controller-port", "9000"}),
+		Entry("kvaware with a controller port", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "kvaware"
+			router.Spec.LMCacheControllerPort = 9100
+		}, []string{"--routing-logic", "kvaware", "--lmcache-controller-port", "9100"}),
+		Entry("prefixaware", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "prefixaware"
+		}, []string{"--routing-logic", "prefixaware"}),
+		Entry("tuning flags", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.LogLevel = "warning"
+			router.Spec.RequestTimeoutSeconds = 120
+			router.Spec.UvicornWorkers = 2
+		}, []string{"--log-level", "warning", "--request-timeout", "120", "--workers", "2"}),
+		Entry("backend timeouts, retries and concurrency", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.BackendTimeouts = productionstackv1alpha1.RouterBackendTimeouts{ConnectSeconds: 5, ReadSeconds: 60}
+			router.Spec.RetryPolicy = productionstackv1alpha1.RouterRetryPolicy{MaxRetries: 2, RetryOn5xx: true}
+			router.Spec.MaxConcurrentRequests = 256
+		}, []string{"--backend-connect-timeout", "5", "--backend-read-timeout", "60", "--max-retries", "2", "--retry-on-5xx", "--max-concurrent-requests", "256"}),
+		Entry("a router release accepting the versioned flags", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RouterVersion = "v0.1.3"
+			router.Spec.MaxConcurrentRequests = 256
+		}, []string{"--max-concurrent-requests", "256"}),
+		Entry("disaggregated_prefill", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "disaggregated_prefill"
+			router.Spec.PrefillModelLabels = []string{"llama-prefill"}
+			router.Spec.DecodeModelLabels = []string{"llama-decode-a", "llama-decode-b"}
+		}, []string{"--routing-logic", "disaggregated_prefill", "--prefill-model-labels", "llama-prefill", "--decode-model-labels", "llama-decode-a,llama-decode-b"}),
+	)
+
+	It("should leave out the flags an older routerVersion does not accept", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{Name: "versioned-router", Namespace: "default"},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:                  8000,
+				ServiceDiscovery:      "k8s",
+				K8sLabelSelector:      "app=vllm",
+				LogLevel:              "info",
+				BackendTimeouts:       productionstackv1alpha1.RouterBackendTimeouts{ReadSeconds: 60},
+				MaxConcurrentRequests: 256,
+				RouterVersion:         "0.1.2",
+				Image:                 productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--log-level", "info"))
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("--backend-read-timeout"))
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("--max-concurrent-requests"))
+		condition := routerArgsOmittedCondition(router)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Message).To(ContainSubstring("--backend-read-timeout, --max-concurrent-requests"))
+
+		By("upgrading the router")
+		router.Spec.RouterVersion = "0.2.0"
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		Expect(routerArgsOmittedCondition(router)).To(BeNil())
+	})
+
+	It("should run the sidecars in the router pods and route the Service through them", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sidecar-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				Volumes: []corev1.Volume{{
+					Name:         "proxy-config",
+					VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "oauth2-proxy"}}},
+				}},
+				Sidecars: []corev1.Container{{
+					Name:         "oauth2-proxy",
+					Image:        "quay.io/oauth2-proxy/oauth2-proxy:v7.6.0",
+					Args:         []string{"--upstream=http://127.0.0.1:8000", "--http-address=0.0.0.0:4180"},
+					Ports:        []corev1.ContainerPort{{Name: "proxy", ContainerPort: 4180}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "proxy-config", MountPath: "/etc/oauth2-proxy"}},
+				}},
+				Service: productionstackv1alpha1.RouterServiceSpec{TargetPortName: "proxy"},
+				Image:   productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		containers := dep.Spec.Template.Spec.Containers
+		Expect(containers).To(HaveLen(2))
+		Expect(containers[0].Name).To(Equal("router"))
+		Expect(containers[1].Name).To(Equal("oauth2-proxy"))
+		Expect(containers[1].VolumeMounts).To(ConsistOf(HaveField("Name", "proxy-config")))
+		Expect(dep.Spec.Template.Annotations).To(HaveKey(sidecarsHashAnnotation))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports[0].TargetPort).To(Equal(intstr.FromString("proxy")))
+
+		By("changing the sidecar image")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Sidecars[0].Image = "quay.io/oauth2-proxy/oauth2-proxy:v7.7.0"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[1].Image).To(Equal("quay.io/oauth2-proxy/oauth2-proxy:v7.7.0"))
+
+		By("removing the sidecar")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Sidecars = nil
+		router.Spec.Service.TargetPortName = ""
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(dep.Spec.Template.Annotations).NotTo(HaveKey(sidecarsHashAnnotation))
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt(8000)))
+	})
+
+	It("should expose the LMCache controller of a kvaware router", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{Name: "kvaware-router", Namespace: "default"},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:                  8000,
+				RoutingLogic:          "kvaware",
+				LMCacheControllerPort: 9100,
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		svc := controllerReconciler.serviceForVLLMRouter(router)
+		Expect(svc.Spec.Ports).To(ContainElement(HaveField("Name", "lmcache")))
+		Expect(svc.Spec.Ports[1].Port).To(Equal(int32(9100)))
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.Containers[0].Ports).To(ContainElement(corev1.ContainerPort{Name: "lmcache", ContainerPort: 9100}))
+	})
+
+	It("should pin clients to a router replica with session routing", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "session-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				RoutingLogic:     "session",
+				SessionKey:       "x-user-id",
+				Replicas:         2,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+		Expect(*svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(Equal(int32(10800)))
+		Expect(recorder.Events).NotTo(Receive())
+
+		By("changing the timeout")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		timeoutSeconds := int32(600)
+		router.Spec.Service.SessionAffinityTimeoutSeconds = &timeoutSeconds
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(*svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(Equal(int32(600)))
+
+		By("disabling the affinity")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Service.SessionAffinity = "None"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityNone))
+		Expect(svc.Spec.SessionAffinityConfig).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("SessionAffinityDisabled")))
+	})
+
+	Context("When counting active runtimes", func() {
+		ctx := context.Background()
+
+		It("should count the ready pods matching the label selector", func() {
+			for i, ready := range []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse} {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("counted-runtime-%d", i),
+						Namespace: "default",
+						Labels:    map[string]string{"model": "counted"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "vllm", Image: "vllm/vllm-openai:latest"}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+				})
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+				Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			}
+
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "counting-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "k8s",
+					K8sLabelSelector: "model=counted",
+					Replicas:         1,
+					Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			})
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			var result reconcile.Result
+			for range 2 {
+				var err error
+				result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(result.RequeueAfter).To(Equal(activeRuntimesResyncPeriod))
+
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			Expect(router.Status.ActiveRuntimes).To(Equal(int32(2)))
+		})
+
+		It("should count the static backends", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{Name: "static-router", Namespace: "default"},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					ServiceDiscovery: "static",
+					StaticBackends:   "http://runtime-a, http://runtime-b,",
+					StaticModels:     "model-a,model-b",
+				},
+			}
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			Expect(controllerReconciler.countActiveRuntimes(ctx, router, nil)).To(Equal(int32(2)))
+		})
+	})
+
+	Context("When provisioning RBAC for service discovery", func() {
+		ctx := context.Background()
+
+		It("should create the ServiceAccount, Role and RoleBinding and revert Role edits", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rbac-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "k8s",
+					K8sLabelSelector: "app=vllm",
+					Replicas:         1,
+					Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			})
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			sa := &corev1.ServiceAccount{}
+			Expect(k8sClient.Get(ctx, key, sa)).To(Succeed())
+			Expect(metav1.IsControlledBy(sa, router)).To(BeTrue())
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, key, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Name).To(Equal(router.Name))
+			Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: router.Name, Namespace: router.Namespace}))
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+			Expect(dep.Spec.Template.Spec.ServiceAccountName).To(Equal(router.Name))
+
+			role := &rbacv1.Role{}
+			Expect(k8sClient.Get(ctx, key, role)).To(Succeed())
+			expectedRules := role.DeepCopy().Rules
+			role.Rules = role.Rules[:1]
+			role.Rules[0].Verbs = []string{"get"}
+			Expect(k8sClient.Update(ctx, role)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, role)).To(Succeed())
+			Expect(role.Rules).To(Equal(expectedRules))
+		})
+
+		It("should discover other namespaces with a ClusterRole deleted with the router", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "shared-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "k8s",
+					K8sLabelSelector: "app=vllm",
+					WatchNamespaces:  []string{"team-a", "team-b"},
+					RBAC:             productionstackv1alpha1.RBACSpec{ClusterWide: true},
+					Replicas:         1,
+					Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			controllerReconciler := &VLLMRouterReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterKey := types.NamespacedName{Name: "default-shared-router"}
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(k8sClient.Get(ctx, clusterKey, clusterRole)).To(Succeed())
+			Expect(clusterRole.Rules).To(ContainElement(HaveField("Resources", ContainElement("pods"))))
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(k8sClient.Get(ctx, clusterKey, clusterRoleBinding)).To(Succeed())
+			Expect(clusterRoleBinding.RoleRef.Name).To(Equal(clusterRole.Name))
+			Expect(clusterRoleBinding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: router.Name, Namespace: router.Namespace}))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &rbacv1.Role{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			Expect(router.Finalizers).To(ContainElement(routerClusterRBACFinalizer))
+
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+			Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--k8s-namespace", "team-a,team-b"))
+
+			By("deleting the router")
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRole{}))).To(BeTrue())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, clusterKey, &rbacv1.ClusterRoleBinding{}))).To(BeTrue())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &productionstackv1alpha1.VLLMRouter{}))).To(BeTrue())
+		})
+
+		It("should report SpecInvalid for other namespaces without cluster-wide RBAC", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{Name: "router", Namespace: "default"},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					ServiceDiscovery: "k8s",
+					WatchNamespaces:  []string{"default"},
+				},
+			}
+			Expect(routerSpecInvalidCondition(router)).To(BeNil())
+			router.Spec.WatchNamespaces = []string{"*"}
+			Expect(routerSpecInvalidCondition(router)).To(HaveField("Reason", "ClusterWideRBACRequired"))
+			router.Spec.RBAC.ClusterWide = true
+			Expect(routerSpecInvalidCondition(router)).To(BeNil())
+		})
+
+		DescribeTable("should only provision RBAC when enabled",
+			func(serviceDiscovery, serviceAccountName string, create []bool, expected bool) {
+				router := &productionstackv1alpha1.VLLMRouter{
+					ObjectMeta: metav1.ObjectMeta{Name: "router", Namespace: "default"},
+					Spec: productionstackv1alpha1.VLLMRouterSpec{
+						ServiceDiscovery:   serviceDiscovery,
+						ServiceAccountName: serviceAccountName,
+					},
+				}
+				if len(create) > 0 {
+					router.Spec.RBAC.Create = &create[0]
+				}
+				Expect(rbacEnabled(router)).To(Equal(expected))
+			},
+			Entry("k8s discovery", "k8s", "", nil, true),
+			Entry("k8s discovery with a service account", "k8s", "custom", nil, false),
+			Entry("static discovery", "static", "", nil, false),
+			Entry("explicitly disabled", "k8s", "", []bool{false}, false),
+			Entry("explicitly enabled", "static", "custom", []bool{true}, true),
+		)
+	})
+
+	It("should generate the backends from the VLLMRuntimes matching runtimeSelector", func() {
+		ctx := context.Background()
+		for _, name := range []string{"selected-b", "selected-a", "unselected"} {
+			vr := &productionstackv1alpha1.VLLMRuntime{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels:    map[string]string{"team": "selected"},
+				},
+				Spec: productionstackv1alpha1.VLLMRuntimeSpec{
+					Model: productionstackv1alpha1.ModelSpec{ModelURL: "org/" + name},
+				},
+			}
+			if name == "unselected" {
+				vr.Labels["team"] = "other"
+			}
+			Expect(k8sClient.Create(ctx, vr)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, vr)).To(Succeed())
+			})
+		}
+
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "selector-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "static",
+				RuntimeSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "selected"}},
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements(
+			"--static-backends", "http://selected-a.default.svc:80,http://selected-b.default.svc:80",
+			"--static-models", "org/selected-a,org/selected-b",
+		))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.Runtimes).To(Equal([]string{"selected-a", "selected-b"}))
+		Expect(router.Status.ActiveRuntimes).To(Equal(int32(2)))
+
+		By("relabeling a runtime out of the selector")
+		vr := &productionstackv1alpha1.VLLMRuntime{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "selected-b", Namespace: "default"}, vr)).To(Succeed())
+		vr.Labels["team"] = "other"
+		Expect(k8sClient.Update(ctx, vr)).To(Succeed())
+		Expect(controllerReconciler.findVLLMRoutersForVLLMRuntime(ctx, vr)).To(ContainElement(reconcile.Request{NamespacedName: key}))
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements(
+			"--static-backends", "http://selected-a.default.svc:80",
+			"--static-models", "org/selected-a",
+		))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.Runtimes).To(Equal([]string{"selected-a"}))
+	})
+
+	It("should manage the PodDisruptionBudget of multi-replica routers", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pdb-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:                80,
+				ServiceDiscovery:    "k8s",
+				K8sLabelSelector:    "app=vllm",
+				Replicas:            2,
+				PodDisruptionBudget: productionstackv1alpha1.PodDisruptionBudgetSpec{Enabled: true},
+				Image:               productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+		Expect(metav1.IsControlledBy(pdb, router)).To(BeTrue())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": router.Name}))
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+
+		By("switching to minAvailable")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		minAvailable := intstr.FromString("50%")
+		router.Spec.PodDisruptionBudget.MinAvailable = &minAvailable
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.String()).To(Equal("50%"))
+		Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+
+		By("scaling down to a single replica")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Replicas = 1
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, pdb))).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PodDisruptionBudgetSkipped")))
+
+		By("disabling the PodDisruptionBudget")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.Replicas = 3
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.PodDisruptionBudget.Enabled = false
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, pdb))).To(BeTrue())
+	})
+
+	It("should scale the Deployment through the scale subresource", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "scale-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.Selector).To(Equal("app=scale-router"))
+
+		By("scaling the router like kubectl scale")
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"spec":{"replicas":4}}`))
+		Expect(k8sClient.SubResource("scale").Patch(ctx, router, patch)).To(Succeed())
+		scale := &autoscalingv1.Scale{}
+		Expect(k8sClient.SubResource("scale").Get(ctx, router, scale)).To(Succeed())
+		Expect(scale.Spec.Replicas).To(Equal(int32(4)))
+		Expect(scale.Status.Selector).To(Equal("app=scale-router"))
+
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(4)))
+	})
+
+	It("should scale the router with a HorizontalPodAutoscaler", func() {
+		ctx := context.Background()
+		minReplicas := int32(2)
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hpa-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a",
+				StaticModels:     "model-a",
+				Replicas:         1,
+				Autoscaling: productionstackv1alpha1.AutoscalingSpec{
+					Enabled:     true,
+					MinReplicas: &minReplicas,
+					MaxReplicas: 5,
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		Expect(k8sClient.Get(ctx, key, hpa)).To(Succeed())
+		Expect(metav1.IsControlledBy(hpa, router)).To(BeTrue())
+		Expect(hpa.Spec.ScaleTargetRef).To(Equal(autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: router.Name}))
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+		Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+		Expect(hpa.Spec.Metrics).To(HaveLen(1))
+		Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(80)))
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+
+		By("letting the HorizontalPodAutoscaler scale the Deployment")
+		scaled := int32(4)
+		dep.Spec.Replicas = &scaled
+		Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+		dep.Status.Replicas = 4
+		dep.Status.ReadyReplicas = 3
+		Expect(k8sClient.Status().Update(ctx, dep)).To(Succeed())
+		hpa.Status.DesiredReplicas = 4
+		hpa.Status.Conditions = []autoscalingv2.HorizontalPodAutoscalerCondition{{
+			Type:    autoscalingv2.ScalingActive,
+			Status:  corev1.ConditionTrue,
+			Reason:  "ValidMetricFound",
+			Message: "the HPA was able to successfully calculate a replica count",
+		}}
+		Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(4)))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.Replicas).To(Equal(int32(4)))
+		Expect(router.Status.ReadyReplicas).To(Equal(int32(3)))
+		Expect(router.Status.DesiredReplicas).To(Equal(int32(4)))
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionAutoscalingActive)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("ValidMetricFound"))
+
+		By("disabling autoscaling")
+		router.Spec.Autoscaling.Enabled = false
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		for range 2 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, hpa))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(1)))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionAutoscalingActive)).To(BeNil())
+	})
+
+	It("should expose the router through an Ingress", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a",
+				StaticModels:     "model-a",
+				Replicas:         1,
+				Ingress: productionstackv1alpha1.IngressSpec{
+					Enabled:   true,
+					ClassName: "nginx",
+					Host:      "router.example.com",
+					Path:      "/",
+					TLS:       &productionstackv1alpha1.IngressTLS{SecretName: "router-tls"},
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		ingress := &networkingv1.Ingress{}
+		Expect(k8sClient.Get(ctx, key, ingress)).To(Succeed())
+		Expect(metav1.IsControlledBy(ingress, router)).To(BeTrue())
+		backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+		Expect(backend.Name).To(Equal(router.Name))
+		Expect(backend.Port.Name).To(Equal("http"))
+		Expect(ingress.Spec.TLS).To(Equal([]networkingv1.IngressTLS{{Hosts: []string{"router.example.com"}, SecretName: "router-tls"}}))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.URL).To(BeEmpty())
+
+		By("reporting the URL once the Ingress has an address")
+		ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}}
+		Expect(k8sClient.Status().Update(ctx, ingress)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.URL).To(Equal("https://router.example.com/"))
+
+		By("changing the host")
+		router.Spec.Ingress.Host = "llm.example.com"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, ingress)).To(Succeed())
+		Expect(ingress.Spec.Rules[0].Host).To(Equal("llm.example.com"))
+		Expect(ingress.Spec.TLS[0].Hosts).To(Equal([]string{"llm.example.com"}))
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.URL).To(Equal("https://llm.example.com/"))
+
+		By("disabling the Ingress")
+		router.Spec.Ingress.Enabled = false
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, ingress))).To(BeTrue())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.URL).To(BeEmpty())
+	})
+
+	Context("When an HTTPRoute is configured", func() {
+		ctx := context.Background()
+
+		It("should report GatewayAPIUnavailable without the Gateway API CRDs", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "httproute-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "static",
+					StaticBackends:   "http://runtime-a",
+					StaticModels:     "model-a",
+					Replicas:         1,
+					HTTPRoute: productionstackv1alpha1.HTTPRouteSpec{
+						Enabled:    true,
+						ParentRefs: []productionstackv1alpha1.GatewayParentReference{{Name: "public"}},
+					},
+					Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			})
+			controllerReconciler := &VLLMRouterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionGatewayAPIUnavailable)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionHTTPRouteAccepted)).To(BeNil())
+		})
+
+		DescribeTable("should summarize the route status reported by the Gateways",
+			func(parents []interface{}, expectedStatus metav1.ConditionStatus, expectedReason string) {
+				route := newHTTPRoute()
+				if parents != nil {
+					Expect(unstructured.SetNestedSlice(route.Object, parents, "status", "parents")).To(Succeed())
+				}
+				condition := httpRouteAcceptedCondition(route, productionstackv1alpha1.ConditionHTTPRouteAccepted, 1)
+				Expect(condition.Type).To(Equal(productionstackv1alpha1.ConditionHTTPRouteAccepted))
+				Expect(condition.Status).To(Equal(expectedStatus))
+				Expect(condition.Reason).To(Equal(expectedReason))
+			},
+			Entry("no status yet", nil, metav1.ConditionUnknown, "Pending"),
+			Entry("accepted with resolved refs", []interface{}{
+				map[string]interface{}{
+					"parentRef": map[string]interface{}{"name": "public"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted"},
+						map[string]interface{}{"type": "ResolvedRefs", "status": "True", "reason": "ResolvedRefs"},
+					},
+				},
+			}, metav1.ConditionTrue, "Accepted"),
+			Entry("rejected by the Gateway", []interface{}{
+				map[string]interface{}{
+					"parentRef": map[string]interface{}{"name": "public"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "False", "reason": "NotAllowedByListeners", "message": "hostname does not match"},
+						map[string]interface{}{"type": "ResolvedRefs", "status": "True", "reason": "ResolvedRefs"},
+					},
+				},
+			}, metav1.ConditionFalse, "NotAllowedByListeners"),
+			Entry("backend not resolved", []interface{}{
+				map[string]interface{}{
+					"parentRef": map[string]interface{}{"name": "public"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted"},
+						map[string]interface{}{"type": "ResolvedRefs", "status": "False", "reason": "BackendNotFound"},
+					},
+				},
+			}, metav1.ConditionFalse, "BackendNotFound"),
+		)
+	})
+
+	Context("When monitoring is enabled", func() {
+		ctx := context.Background()
+
+		It("should report MonitoringCRDsMissing without the Prometheus Operator CRDs", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "monitored-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "static",
+					StaticBackends:   "http://runtime-a",
+					StaticModels:     "model-a",
+					Replicas:         1,
+					Monitoring:       productionstackv1alpha1.MonitoringSpec{Enabled: true},
+					Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			})
+			controllerReconciler := &VLLMRouterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionMonitoringCRDsMissing)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("disabling monitoring")
+			router.Spec.Monitoring.Enabled = false
+			Expect(k8sClient.Update(ctx, router)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionMonitoringCRDsMissing)).To(BeNil())
+		})
+
+		It("should keep the ServiceMonitor in sync with the monitoring settings", func() {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(serviceMonitorGVK, meta.RESTScopeNamespace)
+			c := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithRESTMapper(mapper).Build()
+
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "monitored-router",
+					Namespace: "default",
+					UID:       "monitored-router-uid",
+				},
+			}
+			spec := productionstackv1alpha1.MonitoringSpec{
+				Enabled:     true,
+				Interval:    "30s",
+				ExtraLabels: map[string]string{"release": "prometheus"},
+			}
+			selector := map[string]string{"app": router.Name}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+
+			condition, err := reconcileServiceMonitor(ctx, c, c.Scheme(), router, spec, router.Name, selector, "http")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeNil())
+			monitor := newServiceMonitor()
+			Expect(c.Get(ctx, key, monitor)).To(Succeed())
+			Expect(monitor.GetLabels()).To(Equal(map[string]string{"release": "prometheus"}))
+			endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+			Expect(endpoints).To(Equal([]interface{}{map[string]interface{}{"port": "http", "path": "/metrics", "interval": "30s"}}))
+			matchLabels, _, _ := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+			Expect(matchLabels).To(Equal(selector))
+
+			By("changing the interval and labels")
+			spec.Interval = "1m"
+			spec.ExtraLabels = map[string]string{"release": "kube-prometheus"}
+			_, err = reconcileServiceMonitor(ctx, c, c.Scheme(), router, spec, router.Name, selector, "http")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, key, monitor)).To(Succeed())
+			Expect(monitor.GetLabels()).To(Equal(map[string]string{"release": "kube-prometheus"}))
+			interval, _, _ := unstructured.NestedString(monitor.Object["spec"].(map[string]interface{})["endpoints"].([]interface{})[0].(map[string]interface{}), "interval")
+			Expect(interval).To(Equal("1m"))
+
+			By("disabling monitoring")
+			spec.Enabled = false
+			_, err = reconcileServiceMonitor(ctx, c, c.Scheme(), router, spec, router.Name, selector, "http")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(c.Get(ctx, key, monitor))).To(BeTrue())
+		})
+	})
+
+	Context("When autoscaling with a KEDA ScaledObject", func() {
+		ctx := context.Background()
+		maxReplicas := int32(4)
+		triggers := []productionstackv1alpha1.ScaledObjectTrigger{{
+			Type:       "prometheus",
+			MetricType: "AverageValue",
+			Metadata: map[string]string{
+				"serverAddress": "http://prometheus.monitoring:9090",
+				"query":         "sum(vllm:num_requests_running)",
+				"threshold":     "32",
+			},
+		}}
+
+		It("should report KEDAUnavailable without the KEDA CRDs instead of creating a HorizontalPodAutoscaler", func() {
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "keda-router",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.VLLMRouterSpec{
+					Port:             80,
+					ServiceDiscovery: "static",
+					StaticBackends:   "http://runtime-a",
+					StaticModels:     "model-a",
+					Replicas:         1,
+					Autoscaling: productionstackv1alpha1.AutoscalingSpec{
+						Enabled:      true,
+						MaxReplicas:  maxReplicas,
+						ScaledObject: productionstackv1alpha1.ScaledObjectSpec{Enabled: true, Triggers: triggers},
+					},
+					Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, router)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+			})
+			controllerReconciler := &VLLMRouterReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionKEDAUnavailable)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{}))).To(BeTrue())
+
+			By("switching back to the HorizontalPodAutoscaler")
+			router.Spec.Autoscaling.ScaledObject = productionstackv1alpha1.ScaledObjectSpec{}
+			Expect(k8sClient.Update(ctx, router)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+			Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionKEDAUnavailable)).To(BeNil())
+			Expect(k8sClient.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{})).To(Succeed())
+		})
+
+		It("should keep the ScaledObject in sync with the autoscaling settings", func() {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(scaledObjectGVK, meta.RESTScopeNamespace)
+			c := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithRESTMapper(mapper).Build()
+
+			router := &productionstackv1alpha1.VLLMRouter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "keda-router",
+					Namespace: "default",
+					UID:       "keda-router-uid",
+				},
+			}
+			spec := productionstackv1alpha1.AutoscalingSpec{
+				Enabled:      true,
+				MaxReplicas:  maxReplicas,
+				ScaledObject: productionstackv1alpha1.ScaledObjectSpec{Enabled: true, Triggers: triggers},
+			}
+			labels := map[string]string{"app": router.Name}
+			key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+
+			_, condition, err := reconcileScaledObject(ctx, c, c.Scheme(), router, spec, router.Name, labels)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition).To(BeNil())
+			scaledObject := newScaledObject()
+			Expect(c.Get(ctx, key, scaledObject)).To(Succeed())
+			targetName, _, _ := unstructured.NestedString(scaledObject.Object, "spec", "scaleTargetRef", "name")
+			Expect(targetName).To(Equal(router.Name))
+			minReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "minReplicaCount")
+			Expect(minReplicas).To(Equal(int64(1)))
+			maxReplicaCount, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+			Expect(maxReplicaCount).To(Equal(int64(4)))
+			liveTriggers, _, _ := unstructured.NestedSlice(scaledObject.Object, "spec", "triggers")
+			Expect(liveTriggers).To(HaveLen(1))
+			Expect(liveTriggers[0]).To(HaveKeyWithValue("type", "prometheus"))
+
+			By("changing the polling interval")
+			pollingInterval := int32(15)
+			spec.ScaledObject.PollingInterval = &pollingInterval
+			_, _, err = reconcileScaledObject(ctx, c, c.Scheme(), router, spec, router.Name, labels)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, key, scaledObject)).To(Succeed())
+			interval, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "pollingInterval")
+			Expect(interval).To(Equal(int64(15)))
+
+			By("disabling the ScaledObject")
+			spec.ScaledObject.Enabled = false
+			live, _, err := reconcileScaledObject(ctx, c, c.Scheme(), router, spec, router.Name, labels)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(live).To(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, key, scaledObject))).To(BeTrue())
+		})
+	})
+
+	It("should keep externally managed replicas when updating the Deployment", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hpa-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:               80,
+				ServiceDiscovery:   "k8s",
+				K8sLabelSelector:   "app=vllm",
+				Replicas:           1,
+				IgnoreReplicaDrift: true,
+				Image:              productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 3 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// The HPA scales the router
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		replicas := int32(4)
+		dep.Spec.Replicas = &replicas
+		Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.ExtraArgs = []string{"--log-stats"}
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--log-stats"))
+		Expect(*dep.Spec.Replicas).To(Equal(int32(4)))
+	})
+
+	It("should report SpecInvalid instead of crashing for static discovery without backends", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid-static-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "static",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		Expect(func() {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}).NotTo(Panic())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSpecInvalid)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("StaticBackendsMissing"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("StaticBackendsMissing")))
+		err := k8sClient.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		By("fixing the spec")
+		router.Spec.StaticBackends = "http://runtime-a"
+		router.Spec.StaticModels = "model-a"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSpecInvalid)).To(BeNil())
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, dep)).To(Succeed())
+		})
+	})
+
+	It("should wait for the dynamic config ConfigMap and mount it", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dynamic-config-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a",
+				StaticModels:     "model-a",
+				Replicas:         1,
+				DynamicConfig: productionstackv1alpha1.RouterDynamicConfigSpec{
+					Enabled:       true,
+					ConfigMapName: "dynamic-config-routes",
+					Key:           "dynamic_config.json",
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionDynamicConfigResolved)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ConfigMapNotFound"))
+		err = k8sClient.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		By("creating the ConfigMap")
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dynamic-config-routes", Namespace: "default"},
+			Data:       map[string]string{"dynamic_config.json": `{"service_discovery": "static"}`},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+		})
+		Expect(controllerReconciler.findVLLMRoutersForConfigMap(ctx, configMap)).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition = meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionDynamicConfigResolved)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, dep)).To(Succeed())
+		})
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--dynamic-config-json", "/etc/vllm-router/dynamic-config/dynamic_config.json"))
+		Expect(dep.Spec.Template.Spec.Volumes).To(ConsistOf(HaveField("ConfigMap.Name", "dynamic-config-routes")))
+		Expect(dep.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(HaveField("MountPath", "/etc/vllm-router/dynamic-config")))
+	})
+
+	DescribeTable("When mapping the Deployment status to router conditions",
+		func(depConditions []appsv1.DeploymentCondition, stale bool, expected map[string]metav1.ConditionStatus) {
+			dep := &appsv1.Deployment{}
+			dep.Generation = 2
+			dep.Status.ObservedGeneration = 2
+			if stale {
+				dep.Status.ObservedGeneration = 1
+			}
+			dep.Status.Conditions = depConditions
+			statuses := map[string]metav1.ConditionStatus{}
+			for _, condition := range routerStatusConditions(dep, 0) {
+				statuses[condition.Type] = condition.Status
+			}
+			Expect(statuses).To(Equal(expected))
+		},
+		Entry("a Deployment not reporting yet", nil, false, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionUnknown, "Degraded": metav1.ConditionFalse, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+		Entry("a completed rollout", []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+		}, false, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionTrue, "Progressing": metav1.ConditionFalse, "Degraded": metav1.ConditionFalse, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+		Entry("a rollout in progress", []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+		}, false, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionTrue, "Progressing": metav1.ConditionTrue, "Degraded": metav1.ConditionFalse, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+		Entry("a spec not observed yet", []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+		}, true, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionTrue, "Degraded": metav1.ConditionFalse, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+		Entry("a rollout past its deadline", []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+		}, false, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionFalse, "Degraded": metav1.ConditionTrue, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+		Entry("a replica failure", []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Reason: "FailedCreate"},
+		}, false, map[string]metav1.ConditionStatus{
+			"Available": metav1.ConditionFalse, "Progressing": metav1.ConditionUnknown, "Degraded": metav1.ConditionTrue, "BackendsDiscovered": metav1.ConditionFalse,
+		}),
+	)
+
+	It("should report observedGeneration, the endpoint and the backends in the status", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "status-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a,http://runtime-b",
+				StaticModels:     "model-a,model-b",
+				Replicas:         1,
+				Service:          productionstackv1alpha1.RouterServiceSpec{Port: 8080},
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}})).To(Succeed())
+		})
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.ObservedGeneration).To(Equal(router.Generation))
+		Expect(router.Status.ServiceEndpoint).To(Equal("http://status-router.default.svc:8080"))
+		Expect(router.Status.ActiveRuntimes).To(Equal(int32(2)))
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionBackendsDiscovered)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.ObservedGeneration).To(Equal(router.Generation))
+		Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionAvailable)).NotTo(BeNil())
+	})
+
+	It("should wait for the API key Secret and roll the router when it rotates", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api-key-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:                  80,
+				ServiceDiscovery:      "static",
+				StaticBackends:        "http://runtime-a",
+				StaticModels:          "model-a",
+				Replicas:              1,
+				VLLMApiKeySecret:      corev1.LocalObjectReference{Name: "router-api-key"},
+				VLLMApiKeyName:        "token",
+				RestartOnSecretChange: true,
+				Image:                 productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSecretMissing)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("SecretNotFound"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("SecretNotFound")))
+		err = k8sClient.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		By("creating the Secret")
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "router-api-key", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("first")},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		})
+		Expect(controllerReconciler.findVLLMRoutersForSecret(ctx, secret)).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSecretMissing)).To(BeNil())
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, dep)).To(Succeed())
+		})
+		firstHash := dep.Spec.Template.Annotations[apiKeyHashAnnotation]
+		Expect(firstHash).NotTo(BeEmpty())
+
+		By("restarting the Deployment by hand")
+		dep.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = "2025-01-01T00:00:00Z"
+		Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Annotations).To(HaveKey("kubectl.kubernetes.io/restartedAt"))
+
+		By("rotating the API key")
+		secret.Data["token"] = []byte("second")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Annotations[apiKeyHashAnnotation]).NotTo(BeEmpty())
+		Expect(dep.Spec.Template.Annotations[apiKeyHashAnnotation]).NotTo(Equal(firstHash))
+	})
+
+	It("should merge pod labels, annotations and volumes into the pod template", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-template-router", Namespace: "default"},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				PodLabels:        map[string]string{"team": "inference", "app": "ignored"},
+				PodAnnotations:   map[string]string{"prometheus.io/scrape": "true"},
+				Volumes: []corev1.Volume{{
+					Name: "ca-bundle",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "backend-ca"}},
+					},
+				}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/backends", ReadOnly: true}},
+				Image:        productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		dep := controllerReconciler.deploymentForVLLMRouter(router, &routerInputs{annotations: map[string]string{apiKeyHashAnnotation: "abc"}})
+		template := dep.Spec.Template
+		Expect(template.Labels).To(Equal(map[string]string{"app": "pod-template-router", "team": "inference"}))
+		Expect(template.Annotations).To(Equal(map[string]string{"prometheus.io/scrape": "true", apiKeyHashAnnotation: "abc"}))
+		Expect(template.Spec.Volumes).To(HaveLen(1))
+		Expect(*template.Spec.Volumes[0].ConfigMap.DefaultMode).To(Equal(corev1.ConfigMapVolumeSourceDefaultMode))
+		Expect(template.Spec.Containers[0].VolumeMounts).To(Equal(router.Spec.VolumeMounts))
+
+		By("comparing against the live Deployment")
+		inputs := &routerInputs{annotations: map[string]string{apiKeyHashAnnotation: "abc"}}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, inputs)).To(BeFalse())
+		router.Spec.PodAnnotations = nil
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, inputs)).To(BeTrue())
+		router.Spec.PodAnnotations = map[string]string{"prometheus.io/scrape": "true"}
+		router.Spec.PodLabels["team"] = "platform"
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, inputs)).To(BeTrue())
+		router.Spec.PodLabels["team"] = "inference"
+		router.Spec.VolumeMounts[0].MountPath = "/etc/ssl/certs"
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, inputs)).To(BeTrue())
+	})
+
+	It("should render the security contexts and a writable /tmp for a read-only root filesystem", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{Name: "restricted-router", Namespace: "default"},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.SecurityContext).To(Equal(&corev1.PodSecurityContext{}))
+		Expect(dep.Spec.Template.Spec.Containers[0].SecurityContext).To(BeNil())
+		Expect(dep.Spec.Template.Spec.Volumes).To(BeEmpty())
+
+		readOnly, nonRoot, escalation := true, true, false
+		router.Spec.PodSecurityContext = &corev1.PodSecurityContext{
+			RunAsNonRoot:   &nonRoot,
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		}
+		router.Spec.SecurityContext = &corev1.SecurityContext{
+			ReadOnlyRootFilesystem:   &readOnly,
+			AllowPrivilegeEscalation: &escalation,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.SecurityContext).To(Equal(router.Spec.PodSecurityContext))
+		Expect(dep.Spec.Template.Spec.Containers[0].SecurityContext).To(Equal(router.Spec.SecurityContext))
+		Expect(dep.Spec.Template.Spec.Volumes).To(ConsistOf(HaveField("Name", "tmp")))
+		Expect(dep.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "tmp", MountPath: "/tmp"}))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+	})
+
+	It("should render the deploy strategy and drain in-flight requests on shutdown", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{Name: "rollout-router", Namespace: "default"},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         2,
+				DeployStrategy:   "RollingUpdate",
+				Termination:      productionstackv1alpha1.RouterTerminationSpec{GracePeriodSeconds: 120, DrainSleepSeconds: 10},
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		dep := controllerReconciler.deploymentForVLLMRouter(router, nil)
+		defaultSurge := intstr.FromString("25%")
+		Expect(dep.Spec.Strategy).To(Equal(appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &defaultSurge, MaxUnavailable: &defaultSurge},
+		}))
+		Expect(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(120)))
+		Expect(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "10"}))
+
+		By("Keeping one router serving during a rollout")
+		maxSurge, maxUnavailable := intstr.FromInt(1), intstr.FromInt(0)
+		router.Spec.RollingUpdate = &productionstackv1alpha1.RollingUpdateSpec{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Strategy.RollingUpdate).To(Equal(&appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable}))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+
+		By("Switching to Recreate")
+		router.Spec.DeployStrategy = "Recreate"
+		router.Spec.RollingUpdate = nil
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Strategy).To(Equal(appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}))
+
+		By("Calling the drain endpoint before the sleep")
+		router.Spec.Termination.DrainEndpoint = true
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(dep.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{
+			"python3", "-c", routerDrainScript, "http://127.0.0.1:8000/drain", "10",
+		}))
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+
+		By("Changing the shutdown settings")
+		router.Spec.Termination = productionstackv1alpha1.RouterTerminationSpec{GracePeriodSeconds: 600}
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeTrue())
+		dep = controllerReconciler.deploymentForVLLMRouter(router, nil)
+		Expect(*dep.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(600)))
+		Expect(dep.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+		Expect(controllerReconciler.deploymentNeedsUpdate(dep, router, nil)).To(BeFalse())
+	})
+
+	It("should report SpecInvalid for a volume mount of an undeclared volume", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				ServiceDiscovery: "k8s",
+				VolumeMounts:     []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/backends"}},
+			},
+		}
+		condition := routerSpecInvalidCondition(router)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("UnknownVolume"))
+
+		router.Spec.Volumes = []corev1.Volume{{Name: "ca-bundle"}}
+		Expect(routerSpecInvalidCondition(router)).To(BeNil())
+		router.Spec.Volumes = append(router.Spec.Volumes, corev1.Volume{Name: "dynamic-config"})
+		Expect(routerSpecInvalidCondition(router).Reason).To(Equal("ReservedVolumeName"))
+	})
+
+	It("should report SpecInvalid for a malformed k8sLabelSelector", func() {
+		router := &productionstackv1alpha1.VLLMRouter{
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app in (vllm",
+			},
+		}
+		Expect(routerSpecInvalidCondition(router)).To(HaveField("Reason", "InvalidLabelSelector"))
+		router.Spec.K8sLabelSelector = "app in (vllm)"
+		Expect(routerSpecInvalidCondition(router)).To(BeNil())
+	})
+
+	It("should report SpecInvalid instead of crashing for an unparsable resource quantity", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid-quantity-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "k8s",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+				Resources:        productionstackv1alpha1.ResourceRequirements{CPU: "1", Memory: "2 GiB"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		recorder := record.NewFakeRecorder(10)
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		Expect(func() {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}).NotTo(Panic())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSpecInvalid)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("InvalidQuantity"))
+		Expect(condition.Message).To(ContainSubstring("resources.memory"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("InvalidQuantity")))
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+
+		By("fixing the quantity")
+		router.Spec.Resources.Memory = "2Gi"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Resources.Limits).To(HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("2Gi")))
+	})
+
+	It("should provision the batch API file storage and remove it when disabled", func() {
+		ctx := context.Background()
+		size := resource.MustParse("50Gi")
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "batch-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				Replicas:         1,
+				FeatureGates: productionstackv1alpha1.RouterFeatureGates{
+					EnableBatchAPI: true,
+					FileStorePath:  "/var/lib/vllm-router/files",
+				},
+				FileStorage: productionstackv1alpha1.RouterFileStorageSpec{
+					Size:             &size,
+					StorageClassName: "standard-rwo",
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		pvcKey := types.NamespacedName{Name: "batch-router-files", Namespace: router.Namespace}
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+		Expect(metav1.IsControlledBy(pvc, router)).To(BeTrue())
+		Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+		Expect(*pvc.Spec.StorageClassName).To(Equal("standard-rwo"))
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.Args).To(ContainElement("--enable-batch-api"))
+		Expect(container.Args).To(ContainElements("--file-storage-path", "/var/lib/vllm-router/files"))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "files", MountPath: "/var/lib/vllm-router/files"}))
+		Expect(dep.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", "batch-router-files")))
+
+		By("disabling the batch API without retaining the files")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		retain := false
+		router.Spec.FeatureGates.EnableBatchAPI = false
+		router.Spec.FileStorage.RetainOnDisable = &retain
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("--enable-batch-api"))
+		Expect(dep.Spec.Template.Spec.Containers[0].VolumeMounts).NotTo(ContainElement(HaveField("Name", "files")))
+		err = k8sClient.Get(ctx, pvcKey, pvc)
+		Expect(errors.IsNotFound(err) || !pvc.DeletionTimestamp.IsZero()).To(BeTrue())
+	})
+
+	It("should serve HTTPS from the TLS Secret and roll the router when it rotates", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tls-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a",
+				StaticModels:     "model-a",
+				Replicas:         1,
+				TLS: productionstackv1alpha1.RouterTLSSpec{
+					Enabled:    true,
+					SecretName: "router-tls",
+					CertKey:    "tls.crt",
+					KeyKey:     "tls.key",
+				},
+				Image: productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition := meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSecretMissing)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("SecretNotFound"))
+		err = k8sClient.Get(ctx, key, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		By("creating the Secret without the key")
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "router-tls", Namespace: "default"},
+			Data:       map[string][]byte{"tls.crt": []byte("cert-1")},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		})
+		Expect(controllerReconciler.findVLLMRoutersForSecret(ctx, secret)).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		condition = meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionSecretMissing)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("KeyNotFound"))
+
+		By("adding the key")
+		secret.Data["tls.key"] = []byte("key-1")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, dep)).To(Succeed())
+		})
+		container := dep.Spec.Template.Spec.Containers[0]
+		Expect(container.Args).To(ContainElements("--ssl-certfile", "/etc/vllm-router/tls/tls.crt", "--ssl-keyfile", "/etc/vllm-router/tls/tls.key"))
+		Expect(container.Ports[0].Name).To(Equal("https"))
+		Expect(container.LivenessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
+		Expect(container.ReadinessProbe.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTPS))
+		Expect(container.VolumeMounts).To(ConsistOf(HaveField("MountPath", "/etc/vllm-router/tls")))
+		Expect(dep.Spec.Template.Spec.Volumes).To(ConsistOf(HaveField("Secret.SecretName", "router-tls")))
+		firstHash := dep.Spec.Template.Annotations[tlsHashAnnotation]
+		Expect(firstHash).NotTo(BeEmpty())
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(svc.Spec.Ports[0].Name).To(Equal("https"))
+		Expect(routerServiceEndpoint(router)).To(Equal("https://tls-router.default.svc:80"))
+
+		By("rotating the certificate")
+		secret.Data["tls.crt"] = []byte("cert-2")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Annotations[tlsHashAnnotation]).NotTo(Equal(firstHash))
+	})
+
+	It("should remove the children while disabled and recreate them when enabled", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "disabled-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:                80,
+				ServiceDiscovery:    "k8s",
+				K8sLabelSelector:    "app=vllm",
+				Replicas:            2,
+				Autoscaling:         productionstackv1alpha1.AutoscalingSpec{Enabled: true, MaxReplicas: 4},
+				PodDisruptionBudget: productionstackv1alpha1.PodDisruptionBudgetSpec{Enabled: true},
+				Ingress:             productionstackv1alpha1.IngressSpec{Enabled: true, Host: "router.example.com"},
+				Image:               productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		children := []client.Object{
+			&appsv1.Deployment{},
+			&corev1.Service{},
+			&autoscalingv2.HorizontalPodAutoscaler{},
+			&policyv1.PodDisruptionBudget{},
+			&networkingv1.Ingress{},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		for _, child := range children {
+			Expect(k8sClient.Get(ctx, key, child)).To(Succeed())
+		}
+
+		By("disabling the router")
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		enabled := false
+		router.Spec.EnableRouter = &enabled
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		for _, child := range children {
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, child))).To(BeTrue(), "%T", child)
+		}
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(router.Status.Status).To(Equal("Disabled"))
+		Expect(meta.IsStatusConditionTrue(router.Status.Conditions, productionstackv1alpha1.ConditionDisabled)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(router.Status.Conditions, productionstackv1alpha1.ConditionAvailable)).To(BeTrue())
+
+		By("enabling the router again")
+		enabled = true
+		router.Spec.EnableRouter = &enabled
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		for range 2 {
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		for _, child := range children {
+			Expect(k8sClient.Get(ctx, key, child)).To(Succeed())
+		}
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		Expect(meta.FindStatusCondition(router.Status.Conditions, productionstackv1alpha1.ConditionDisabled)).To(BeNil())
+		Expect(router.Status.Status).NotTo(Equal("Disabled"))
+	})
+
+	It("should remove the HTTPRoute, ServiceMonitor and ScaledObject of a disabled router", func() {
+		ctx := context.Background()
+		mapper := meta.NewDefaultRESTMapper(nil)
+		for _, gvk := range []schema.GroupVersionKind{httpRouteGVK, serviceMonitorGVK, scaledObjectGVK} {
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "disabled-crd-router",
+				Namespace: "default",
+				UID:       "disabled-crd-router-uid",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:       80,
+				Monitoring: productionstackv1alpha1.MonitoringSpec{Enabled: true},
+				HTTPRoute: productionstackv1alpha1.HTTPRouteSpec{
+					Enabled:    true,
+					ParentRefs: []productionstackv1alpha1.GatewayParentReference{{Name: "gateway"}},
+				},
+				Autoscaling: productionstackv1alpha1.AutoscalingSpec{
+					Enabled:      true,
+					MaxReplicas:  4,
+					ScaledObject: productionstackv1alpha1.ScaledObjectSpec{Enabled: true},
+				},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithRESTMapper(mapper).
+			WithStatusSubresource(&productionstackv1alpha1.VLLMRouter{}).Build()
+		selector := map[string]string{"app": router.Name}
+		children := []client.Object{
+			httpRouteForService(router, router.Spec.HTTPRoute, router.Name, 80, c.Scheme()),
+			serviceMonitorForService(router, router.Spec.Monitoring, router.Name, selector, "http", c.Scheme()),
+			scaledObjectFor(router, router.Spec.Autoscaling, router.Name, selector, c.Scheme()),
+		}
+		for _, child := range append(children, router) {
+			Expect(c.Create(ctx, child)).To(Succeed())
+		}
+
+		controllerReconciler := &VLLMRouterReconciler{
+			Client:   c,
+			Scheme:   c.Scheme(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		Expect(controllerReconciler.reconcileDisabled(ctx, router)).To(Succeed())
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for _, child := range children {
+			Expect(errors.IsNotFound(c.Get(ctx, key, child))).To(BeTrue(), "%s", child.GetObjectKind().GroupVersionKind().Kind)
+		}
+	})
+
+	It("should update the Deployment when the routing logic changes", func() {
+		ctx := context.Background()
+		router := &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rollout-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             80,
+				ServiceDiscovery: "k8s",
+				K8sLabelSelector: "app=vllm",
+				RoutingLogic:     "roundrobin",
+				Replicas:         1,
+				Image:            productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/lmstack-router:latest"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, router)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, router)).To(Succeed())
+		})
+		controllerReconciler := &VLLMRouterReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		key := types.NamespacedName{Name: router.Name, Namespace: router.Namespace}
+		for range 3 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(k8sClient.Get(ctx, key, router)).To(Succeed())
+		router.Spec.RoutingLogic = "session"
+		router.Spec.SessionKey = "x-user-id"
+		Expect(k8sClient.Update(ctx, router)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--routing-logic", "session", "--session-key", "x-user-id"))
+	})
 })
@@ -18,38 +18,115 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"reflect"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	productionstackv1alpha1 "production-stack/api/v1alpha1"
 )
 
+const (
+	// chatTemplateVolumeName is the name of the volume holding the chat template
+	chatTemplateVolumeName = "chat-template"
+	// chatTemplateMountPath is where the chat template ConfigMap is mounted
+	chatTemplateMountPath = "/etc/vllm/chat-template"
+	// chatTemplateHashAnnotation records the chat template content hash on the pod template
+	chatTemplateHashAnnotation = annotationPrefix + "chat-template-hash"
+	// hfTokenHashAnnotation records the HF token hash on the pod template
+	hfTokenHashAnnotation = annotationPrefix + "hf-token-hash"
+	// lmCacheAuthHashAnnotation records the hash of the password of the
+	// external backend of the CacheServer on the pod template
+	lmCacheAuthHashAnnotation = annotationPrefix + "lmcache-auth-hash"
+	// imageAnnotation records the resolved image on the pod template
+	imageAnnotation = annotationPrefix + "image"
+
+	// modelVolumeName is the name of the volume holding local model weights
+	modelVolumeName = "model"
+	// modelMountPath is where local model weights are mounted in the vLLM container
+	modelMountPath = "/models"
+	// hfCacheVolumeName is the name of the volume backing the HuggingFace cache
+	hfCacheVolumeName = "hf-cache"
+
+	// vllmRuntimeServicePort is the port exposed by the VLLMRuntime Service
+	vllmRuntimeServicePort = 80
+
+	// sleepResyncPeriod is how often the engines of a sleeping VLLMRuntime are checked
+	sleepResyncPeriod = time.Minute
+	// degradedResyncPeriod is how often the pods of a degraded VLLMRuntime or
+	// CacheServer are checked
+	degradedResyncPeriod = 30 * time.Second
+	// rolloutStuckThreshold is how long a pod of a rolling update may stay
+	// unschedulable before a warning is recorded
+	rolloutStuckThreshold = 10 * time.Minute
+)
+
+// vllmRuntimeFieldOwner is the server-side apply field manager of the
+// VLLMRuntime controller
+const vllmRuntimeFieldOwner = client.FieldOwner("vllmruntime-controller")
+
+// resolvedInputs holds values resolved from objects referenced by a VLLMRuntime
+type resolvedInputs struct {
+	// remoteURL is the LMCache remote cache server URL
+	remoteURL string
+	// lmCachePassword is the password of the external backend of the
+	// referenced CacheServer, if any
+	lmCachePassword *productionstackv1alpha1.SecretKeyReference
+	// annotations are added to the pod template so that changes to referenced
+	// objects roll the pods
+	annotations map[string]string
+	// labels are added to the pod template, e.g. for the NetworkPolicy of
+	// the referenced CacheServer
+	labels map[string]string
+}
+
 // VLLMRuntimeReconciler reconciles a VLLMRuntime object
 type VLLMRuntimeReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ForbidTrustRemoteCode rejects runtimes that set model.trustRemoteCode
+	ForbidTrustRemoteCode bool
 }
 
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=vllmruntimes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=vllmruntimes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=vllmruntimes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=production-stack.vllm.ai,resources=cacheservers,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -71,103 +148,973 @@ func (r *VLLMRuntimeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Check if the service already exists, if not create a new one
-	foundService := &corev1.Service{}
-	err = r.Get(ctx, types.NamespacedName{Name: vllmRuntime.Name, Namespace: vllmRuntime.Namespace}, foundService)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new service
-		svc := r.serviceForVLLMRuntime(vllmRuntime)
-		log.Info("Creating a new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
-		err = r.Create(ctx, svc)
-		if err != nil {
-			log.Error(err, "Failed to create new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+	// Enforce operator-wide restrictions before touching any child resources
+	if forbiddenCondition := r.specForbiddenCondition(vllmRuntime); forbiddenCondition != nil {
+		r.recordConditionWarning(vllmRuntime, forbiddenCondition)
+		if err := r.setStatusCondition(ctx, vllmRuntime, *forbiddenCondition); err != nil {
+			log.Error(err, "Failed to update VLLMRuntime status")
 			return ctrl.Result{}, err
 		}
-		// Service created successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Service")
+		log.Info("VLLMRuntime spec is forbidden", "Message", forbiddenCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionSpecForbidden, nil); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
 		return ctrl.Result{}, err
 	}
 
-	// Update the service if needed
-	if r.serviceNeedsUpdate(foundService, vllmRuntime) {
-		log.Info("Updating Service", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
-		// Create new service spec
-		newSvc := r.serviceForVLLMRuntime(vllmRuntime)
+	// Warn about GPU device selections that do not match the GPU request
+	gpuCondition := gpuDevicesCondition(vllmRuntime)
+	r.recordConditionWarning(vllmRuntime, gpuCondition)
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionGPUDevicesConflict, gpuCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
 
-		err = r.Update(ctx, newSvc)
-		if err != nil {
-			log.Error(err, "Failed to update Service", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
+	// Reject specs the pods could not be created from
+	if invalidCondition := specInvalidCondition(vllmRuntime); invalidCondition != nil {
+		r.recordConditionWarning(vllmRuntime, invalidCondition)
+		if err := r.setStatusCondition(ctx, vllmRuntime, *invalidCondition); err != nil {
+			log.Error(err, "Failed to update VLLMRuntime status")
 			return ctrl.Result{}, err
 		}
-		// Service updated successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
+		log.Info("VLLMRuntime spec is invalid", "Message", invalidCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionSpecInvalid, nil); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
 	}
 
-	// Check if the deployment already exists, if not create a new one
-	found := &appsv1.Deployment{}
-	err = r.Get(ctx, types.NamespacedName{Name: vllmRuntime.Name, Namespace: vllmRuntime.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new deployment
-		dep := r.deploymentForVLLMRuntime(vllmRuntime)
-		log.Info("Creating a new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
-		err = r.Create(ctx, dep)
-		if err != nil {
-			log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+	// A user-supplied HF_TOKEN takes precedence over the HF token secret
+	hfTokenCondition := hfTokenOverriddenCondition(vllmRuntime)
+	r.recordConditionWarning(vllmRuntime, hfTokenCondition)
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionHFTokenOverridden, hfTokenCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+
+	inputs := &resolvedInputs{annotations: map[string]string{}}
+
+	// Resolve the HF token and API key Secrets. A missing HF token does not
+	// block the deployment, while pods without their API key would not start,
+	// so the deployment is left unchanged until the Secret exists. The Secret
+	// watch updates the runtime once it does.
+	hfTokenHash, secretCondition, err := r.resolveHFTokenSecret(ctx, vllmRuntime)
+	if err != nil {
+		log.Error(err, "Failed to resolve HF token Secret")
+		return ctrl.Result{}, err
+	}
+	apiKeyCondition, err := r.resolveAPIKeySecret(ctx, vllmRuntime)
+	if err != nil {
+		log.Error(err, "Failed to resolve API key Secret")
+		return ctrl.Result{}, err
+	}
+	if secretCondition == nil {
+		secretCondition = apiKeyCondition
+	}
+	r.recordConditionWarning(vllmRuntime, secretCondition)
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionSecretMissing, secretCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+	if apiKeyCondition != nil {
+		log.Info("Waiting for API key Secret", "Message", apiKeyCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if vllmRuntime.Spec.RestartOnSecretChange && hfTokenHash != "" {
+		inputs.annotations[hfTokenHashAnnotation] = hfTokenHash
+	}
+
+	// Resolve the remote cache server URL and password
+	remote, cacheServerCondition, err := r.resolveLMCacheRemote(ctx, vllmRuntime)
+	if err != nil {
+		log.Error(err, "Failed to resolve CacheServer")
+		return ctrl.Result{}, err
+	}
+	r.recordConditionWarning(vllmRuntime, remote.serdeMismatch)
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionRemoteSerdeMismatch, remote.serdeMismatch); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+	if ready, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionCacheServerResolved, cacheServerCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	} else if !ready {
+		// The CacheServer watch requeues the runtime once it becomes ready.
+		// Meanwhile the runtime keeps its last resolved remote, a new one
+		// starts without, so that the rest of the spec is still applied.
+		log.Info("Waiting for CacheServer", "Message", cacheServerCondition.Message)
+		if remote, err = r.lastLMCacheRemote(ctx, vllmRuntime); err != nil {
+			log.Error(err, "Failed to get the last resolved CacheServer")
 			return ctrl.Result{}, err
 		}
-		// Deployment created successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Deployment")
+	}
+	inputs.remoteURL = remote.url
+	inputs.lmCachePassword = remote.password
+	inputs.labels = remote.labels
+	if remote.authHash != "" {
+		inputs.annotations[lmCacheAuthHashAnnotation] = remote.authHash
+	}
+
+	// Resolve the chat template ConfigMap
+	chatTemplateHash, chatTemplateCondition, err := r.resolveChatTemplate(ctx, vllmRuntime)
+	if err != nil {
+		log.Error(err, "Failed to resolve chat template ConfigMap")
 		return ctrl.Result{}, err
 	}
+	if ready, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionChatTemplateResolved, chatTemplateCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	} else if !ready {
+		// The ConfigMap watch requeues the runtime once the template exists
+		log.Info("Waiting for chat template", "Message", chatTemplateCondition.Message)
+		return ctrl.Result{}, nil
+	}
+	if chatTemplateHash != "" {
+		inputs.annotations[chatTemplateHashAnnotation] = chatTemplateHash
+	}
 
-	// Update the deployment if needed
-	if r.deploymentNeedsUpdate(found, vllmRuntime) {
-		log.Info("Updating Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
-		// Create new deployment spec
-		newDep := r.deploymentForVLLMRuntime(vllmRuntime)
+	// Apply the service
+	svc := r.serviceForVLLMRuntime(vllmRuntime)
+	if err := applyAndRecord(ctx, r.Client, r.Recorder, vllmRuntime, svc, vllmRuntimeFieldOwner); err != nil {
+		log.Error(err, "Failed to apply Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		return ctrl.Result{}, err
+	}
 
-		err = r.Update(ctx, newDep)
-		if err != nil {
-			log.Error(err, "Failed to update Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+	// Apply or remove the headless service
+	if vllmRuntime.Spec.HeadlessService {
+		headlessSvc := r.headlessServiceForVLLMRuntime(vllmRuntime)
+		if err := applyAndRecord(ctx, r.Client, r.Recorder, vllmRuntime, headlessSvc, vllmRuntimeFieldOwner); err != nil {
+			log.Error(err, "Failed to apply Service", "Service.Namespace", headlessSvc.Namespace, "Service.Name", headlessSvc.Name)
 			return ctrl.Result{}, err
 		}
-		// Deployment updated successfully - return and requeue
-		return ctrl.Result{Requeue: true}, nil
+	} else if err := r.deleteHeadlessService(ctx, vllmRuntime); err != nil {
+		log.Error(err, "Failed to delete headless Service")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the Ingress exposing the service
+	if err := reconcileIngress(ctx, r.Client, r.Scheme, vllmRuntime, vllmRuntime.Spec.Ingress, vllmRuntime.Name, "http"); err != nil {
+		log.Error(err, "Failed to reconcile Ingress")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the HTTPRoute exposing the service through a Gateway
+	httpRouteCondition, err := reconcileHTTPRoute(ctx, r.Client, r.Scheme, vllmRuntime, vllmRuntime.Spec.HTTPRoute, vllmRuntime.Name, vllmRuntimeServicePort)
+	if err != nil {
+		log.Error(err, "Failed to reconcile HTTPRoute")
+		return ctrl.Result{}, err
+	}
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionGatewayAPIUnavailable, httpRouteCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+
+	// Apply the deployment. Server-side apply only touches the fields set by
+	// the operator, so sidecars and fields managed by other controllers are
+	// kept, and an unchanged spec does not roll the pods.
+	dep := r.deploymentForVLLMRuntime(vllmRuntime, inputs)
+	if err := applyAndRecord(ctx, r.Client, r.Recorder, vllmRuntime, dep, vllmRuntimeFieldOwner); err != nil {
+		log.Error(err, "Failed to apply Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+		return ctrl.Result{}, err
+	}
+
+	// Put the engines to sleep or wake them up
+	sleepState, sleepCondition, err := r.reconcileSleep(ctx, vllmRuntime)
+	if err != nil {
+		log.Error(err, "Failed to reconcile sleep mode")
+		return ctrl.Result{}, err
+	}
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionAsleep, sleepCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+
+	// Report crash-looping pods
+	degradedCondition, err := podsDegradedCondition(ctx, r.Client, vllmRuntime.Namespace, map[string]string{"app": vllmRuntime.Name})
+	if err != nil {
+		log.Error(err, "Failed to list VLLMRuntime pods")
+		return ctrl.Result{}, err
+	}
+	r.recordConditionWarning(vllmRuntime, degradedCondition)
+	if _, err := r.reconcileCondition(ctx, vllmRuntime, productionstackv1alpha1.ConditionDegraded, degradedCondition); err != nil {
+		log.Error(err, "Failed to update VLLMRuntime status")
+		return ctrl.Result{}, err
+	}
+
+	// Warn about rolling updates waiting for GPUs held by the old pods
+	var rolloutWait time.Duration
+	if dep.Spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(vllmRuntime.Namespace), client.MatchingLabels{"app": vllmRuntime.Name}); err != nil {
+			log.Error(err, "Failed to list VLLMRuntime pods")
+			return ctrl.Result{}, err
+		}
+		var stuckPod *corev1.Pod
+		stuckPod, rolloutWait = unschedulableRolloutPod(pods.Items, time.Now())
+		if stuckPod != nil {
+			r.Recorder.Eventf(vllmRuntime, corev1.EventTypeWarning, "RolloutStuck",
+				"Pod %s of the new ReplicaSet has been unschedulable for more than %s while the old pods are running; "+
+					"a rolling update needs spare GPUs for the surge pod, consider deploymentStrategy Recreate or Auto",
+				stuckPod.Name, rolloutStuckThreshold)
+		}
 	}
 
 	// Update the status
-	if err := r.updateStatus(ctx, vllmRuntime, found); err != nil {
+	if err := r.updateStatus(ctx, vllmRuntime, dep, sleepState); err != nil {
 		log.Error(err, "Failed to update VLLMRuntime status")
 		return ctrl.Result{}, err
 	}
 
+	// Pods are not watched, recheck periodically so that pods started while
+	// asleep are put to sleep too
+	if sleepRequested(vllmRuntime) {
+		return ctrl.Result{RequeueAfter: sleepResyncPeriod}, nil
+	}
+	// and so that the Degraded condition follows the pods
+	if degradedCondition != nil {
+		return ctrl.Result{RequeueAfter: degradedResyncPeriod}, nil
+	}
+	// and so that stuck rolling updates are reported
+	if rolloutWait > 0 {
+		return ctrl.Result{RequeueAfter: rolloutWait}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// deploymentStrategyType returns the Deployment strategy of a VLLMRuntime.
+// Auto selects Recreate for a single replica requesting GPUs: the surge pod of
+// a rolling update cannot be scheduled while the old pod holds the GPUs.
+func deploymentStrategyType(spec productionstackv1alpha1.VLLMRuntimeSpec) appsv1.DeploymentStrategyType {
+	if spec.DeployStrategy != productionstackv1alpha1.DeployStrategyAuto {
+		return appsv1.DeploymentStrategyType(spec.DeployStrategy)
+	}
+	if spec.Replicas == 1 && spec.Resources.GPU != "" {
+		if gpus, err := resource.ParseQuantity(spec.Resources.GPU); err == nil && gpus.Sign() > 0 {
+			return appsv1.RecreateDeploymentStrategyType
+		}
+	}
+	return appsv1.RollingUpdateDeploymentStrategyType
+}
+
+// unschedulableRolloutPod returns a pod that has been unschedulable for longer
+// than rolloutStuckThreshold while pods of another ReplicaSet are running. If
+// there is none, it returns how long until the next unschedulable pod reaches
+// the threshold, 0 if no pod is waiting.
+func unschedulableRolloutPod(pods []corev1.Pod, now time.Time) (*corev1.Pod, time.Duration) {
+	var wait time.Duration
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+			continue
+		}
+		var since time.Time
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+				condition.Reason == corev1.PodReasonUnschedulable {
+				since = condition.LastTransitionTime.Time
+			}
+		}
+		if since.IsZero() || !oldReplicaSetRunning(pods, pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]) {
+			continue
+		}
+		remaining := since.Add(rolloutStuckThreshold).Sub(now)
+		if remaining <= 0 {
+			return pod, 0
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return nil, wait
+}
+
+// oldReplicaSetRunning reports whether a running pod belongs to a ReplicaSet
+// other than the one identified by podTemplateHash
+func oldReplicaSetRunning(pods []corev1.Pod, podTemplateHash string) bool {
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey] != podTemplateHash {
+			return true
+		}
+	}
+	return false
+}
+
+// recordConditionWarning records a Warning event for a condition reporting a
+// problem, unless the condition is already set with the same reason
+func (r *VLLMRuntimeReconciler) recordConditionWarning(vr *productionstackv1alpha1.VLLMRuntime, condition *metav1.Condition) {
+	if condition == nil {
+		return
+	}
+	if previous := meta.FindStatusCondition(vr.Status.Conditions, condition.Type); previous != nil && previous.Reason == condition.Reason {
+		return
+	}
+	r.Recorder.Event(vr, corev1.EventTypeWarning, condition.Reason, condition.Message)
+}
+
+// sleepRequested reports whether the engines of the VLLMRuntime should be asleep
+func sleepRequested(vr *productionstackv1alpha1.VLLMRuntime) bool {
+	return vr.Spec.EnableSleepMode && vr.Annotations[productionstackv1alpha1.SleepAnnotation] == "true"
+}
+
+// reconcileSleep puts the engines of the VLLMRuntime to sleep or wakes them up
+// according to the sleep annotation. It returns the resulting sleep state and
+// the Asleep condition, nil once the engines are awake again.
+func (r *VLLMRuntimeReconciler) reconcileSleep(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (string, *metav1.Condition, error) {
+	sleep := vr.Annotations[productionstackv1alpha1.SleepAnnotation] == "true"
+	if !vr.Spec.EnableSleepMode {
+		if sleep {
+			return "", &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionAsleep,
+				Status:  metav1.ConditionFalse,
+				Reason:  "SleepModeDisabled",
+				Message: "The sleep annotation requires enableSleepMode",
+			}, nil
+		}
+		return "", nil, nil
+	}
+	if !sleep && vr.Status.SleepState != productionstackv1alpha1.SleepStateSleeping {
+		// Nothing to wake up
+		return vr.Status.SleepState, nil, nil
+	}
+
+	apiKey, err := r.vllmAPIKey(ctx, vr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(vr.Namespace), client.MatchingLabels{"app": vr.Name}); err != nil {
+		return "", nil, err
+	}
+
+	var failed []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := setEngineSleep(ctx, pod.Status.PodIP, vr.Spec.Port, apiKey, sleep); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to change engine sleep state", "Pod", pod.Name)
+			failed = append(failed, pod.Name)
+		}
+	}
+
+	if sleep {
+		if len(failed) > 0 {
+			return vr.Status.SleepState, &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionAsleep,
+				Status:  metav1.ConditionFalse,
+				Reason:  "SleepFailed",
+				Message: fmt.Sprintf("Failed to put pods to sleep: %s", strings.Join(failed, ", ")),
+			}, nil
+		}
+		return productionstackv1alpha1.SleepStateSleeping, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionAsleep,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Sleeping",
+			Message: "All engines are asleep",
+		}, nil
+	}
+
+	if len(failed) > 0 {
+		return vr.Status.SleepState, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionAsleep,
+			Status:  metav1.ConditionTrue,
+			Reason:  "WakeUpFailed",
+			Message: fmt.Sprintf("Failed to wake up pods: %s", strings.Join(failed, ", ")),
+		}, nil
+	}
+	return productionstackv1alpha1.SleepStateAwake, nil, nil
+}
+
+// setEngineSleep puts the vLLM engine at podIP to sleep or wakes it up,
+// skipping engines already in the requested state
+func setEngineSleep(ctx context.Context, podIP string, port int32, apiKey string, sleep bool) error {
+	baseURL := "http://" + net.JoinHostPort(podIP, strconv.Itoa(int(port)))
+
+	body, err := sendRequest(ctx, http.MethodGet, baseURL+"/is_sleeping", apiKey)
+	if err != nil {
+		return err
+	}
+	var state struct {
+		IsSleeping bool `json:"is_sleeping"`
+	}
+	if err := json.Unmarshal(body, &state); err != nil {
+		return err
+	}
+	if state.IsSleeping == sleep {
+		return nil
+	}
+
+	if sleep {
+		_, err = sendRequest(ctx, http.MethodPost, baseURL+"/sleep?level=1", apiKey)
+	} else {
+		_, err = sendRequest(ctx, http.MethodPost, baseURL+"/wake_up", apiKey)
+	}
+	return err
+}
+
+// vllmAPIKey returns the API key of the VLLMRuntime from its env or
+// apiKeySecret, if any
+func (r *VLLMRuntimeReconciler) vllmAPIKey(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (string, error) {
+	if ref := vr.Spec.APIKeySecret; ref != nil && !envVarDefined(vr.Spec.Env, "VLLM_API_KEY") {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: vr.Namespace}, secret); err != nil {
+			return "", err
+		}
+		return string(secret.Data[ref.Key]), nil
+	}
+
+	for _, e := range vr.Spec.Env {
+		if e.Name != "VLLM_API_KEY" {
+			continue
+		}
+		if e.ValueFrom == nil || e.ValueFrom.SecretKeyRef == nil {
+			return e.Value, nil
+		}
+		ref := e.ValueFrom.SecretKeyRef
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: vr.Namespace}, secret); err != nil {
+			return "", err
+		}
+		return string(secret.Data[ref.Key]), nil
+	}
+	return "", nil
+}
+
+// speculativeConfig is the --speculative-config JSON accepted by vLLM
+type speculativeConfig struct {
+	Method                  string `json:"method"`
+	Model                   string `json:"model,omitempty"`
+	NumSpeculativeTokens    int32  `json:"num_speculative_tokens"`
+	DraftTensorParallelSize int32  `json:"draft_tensor_parallel_size,omitempty"`
+	PromptLookupMax         int32  `json:"prompt_lookup_max,omitempty"`
+	PromptLookupMin         int32  `json:"prompt_lookup_min,omitempty"`
+}
+
+// speculativeDecodingArgs renders the speculative decoding configuration as vLLM arguments
+func speculativeDecodingArgs(spec *productionstackv1alpha1.SpeculativeDecodingSpec) []string {
+	if spec.ArgStyle == "flags" {
+		model := spec.DraftModelURL
+		if spec.Method == "ngram" {
+			model = "[ngram]"
+		}
+		args := []string{
+			"--speculative-model", model,
+			"--num-speculative-tokens", fmt.Sprintf("%d", spec.NumSpeculativeTokens),
+		}
+		if spec.DraftTensorParallelSize > 0 {
+			args = append(args, "--speculative-draft-tensor-parallel-size", fmt.Sprintf("%d", spec.DraftTensorParallelSize))
+		}
+		if spec.PromptLookupMax > 0 {
+			args = append(args, "--ngram-prompt-lookup-max", fmt.Sprintf("%d", spec.PromptLookupMax))
+		}
+		if spec.PromptLookupMin > 0 {
+			args = append(args, "--ngram-prompt-lookup-min", fmt.Sprintf("%d", spec.PromptLookupMin))
+		}
+		return args
+	}
+
+	// Marshalling a struct of strings and integers cannot fail
+	config, _ := json.Marshal(speculativeConfig{
+		Method:                  spec.Method,
+		Model:                   spec.DraftModelURL,
+		NumSpeculativeTokens:    spec.NumSpeculativeTokens,
+		DraftTensorParallelSize: spec.DraftTensorParallelSize,
+		PromptLookupMax:         spec.PromptLookupMax,
+		PromptLookupMin:         spec.PromptLookupMin,
+	})
+	return []string{"--speculative-config", string(config)}
+}
+
+// specForbiddenCondition returns a SpecForbidden condition if the VLLMRuntime uses
+// a feature the operator has been configured to deny, or nil otherwise.
+func (r *VLLMRuntimeReconciler) specForbiddenCondition(vr *productionstackv1alpha1.VLLMRuntime) *metav1.Condition {
+	if r.ForbidTrustRemoteCode && vr.Spec.Model.TrustRemoteCode {
+		return &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionSpecForbidden,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TrustRemoteCodeForbidden",
+			Message: "model.trustRemoteCode is forbidden by the operator",
+		}
+	}
+	return nil
+}
+
+// specInvalidCondition returns a SpecInvalid condition if a resource quantity
+// cannot be parsed, the user volumes use a name reserved for operator volumes
+// or a volume mount references an unknown volume, or nil otherwise.
+func specInvalidCondition(vr *productionstackv1alpha1.VLLMRuntime) *metav1.Condition {
+	// Without the webhook unparsable quantities reach the controller
+	if condition := invalidQuantityCondition(map[string]string{
+		"resources.cpu":    vr.Spec.Resources.CPU,
+		"resources.memory": vr.Spec.Resources.Memory,
+		"resources.gpu":    vr.Spec.Resources.GPU,
+	}); condition != nil {
+		return condition
+	}
+
+	reserved := map[string]bool{
+		modelVolumeName:        true,
+		hfCacheVolumeName:      true,
+		chatTemplateVolumeName: true,
+	}
+
+	declared := make(map[string]bool, len(vr.Spec.Volumes))
+	for _, v := range vr.Spec.Volumes {
+		if reserved[v.Name] {
+			return &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionSpecInvalid,
+				Status:  metav1.ConditionTrue,
+				Reason:  "ReservedVolumeName",
+				Message: fmt.Sprintf("volume name %s is reserved for the operator", v.Name),
+			}
+		}
+		declared[v.Name] = true
+	}
+
+	for _, m := range vr.Spec.VolumeMounts {
+		if !declared[m.Name] && !reserved[m.Name] {
+			return &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionSpecInvalid,
+				Status:  metav1.ConditionTrue,
+				Reason:  "UnknownVolume",
+				Message: fmt.Sprintf("volume mount %s references undeclared volume %s", m.MountPath, m.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// hfTokenOverriddenCondition returns an HFTokenOverridden condition if spec.env
+// defines HF_TOKEN alongside hfTokenSecret, or nil otherwise.
+func hfTokenOverriddenCondition(vr *productionstackv1alpha1.VLLMRuntime) *metav1.Condition {
+	if vr.Spec.HFTokenSecret.Name == "" || !envVarDefined(vr.Spec.Env, "HF_TOKEN") {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionHFTokenOverridden,
+		Status:  metav1.ConditionTrue,
+		Reason:  "HFTokenOverridden",
+		Message: "spec.env defines HF_TOKEN, ignoring hfTokenSecret",
+	}
+}
+
+// gpuDevicesCondition returns a GPUDevicesConflict condition if gpu.visibleDevices
+// does not list as many devices as resources.gpu requests, or nil otherwise.
+func gpuDevicesCondition(vr *productionstackv1alpha1.VLLMRuntime) *metav1.Condition {
+	if vr.Spec.GPU == nil || vr.Spec.GPU.VisibleDevices == "" {
+		return nil
+	}
+
+	devices := len(strings.Split(vr.Spec.GPU.VisibleDevices, ","))
+	var requested int64
+	if vr.Spec.Resources.GPU != "" {
+		quantity, err := resource.ParseQuantity(vr.Spec.Resources.GPU)
+		if err != nil {
+			return nil
+		}
+		requested = quantity.Value()
+	}
+	if int64(devices) == requested {
+		return nil
+	}
+
+	return &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionGPUDevicesConflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  "DeviceCountMismatch",
+		Message: fmt.Sprintf("gpu.visibleDevices lists %d devices but resources.gpu requests %d", devices, requested),
+	}
+}
+
+// lmCacheRemote is the remote cache server of a VLLMRuntime
+type lmCacheRemote struct {
+	url string
+	// password is the password of the external backend of a CacheServer
+	password *productionstackv1alpha1.SecretKeyReference
+	// authHash is the hash of the password
+	authHash string
+	// labels identify the CacheServer on the pods of the runtime
+	labels map[string]string
+	// serdeMismatch is a RemoteSerdeMismatch condition if the runtime
+	// serde differs from the one of the CacheServer
+	serdeMismatch *metav1.Condition
+}
+
+// resolveLMCacheRemote returns the remote cache server of the VLLMRuntime.
+// When lmCacheConfig.cacheServerRef or autoDiscover is set, the CacheServer is
+// resolved to its Service or external backend and the password of the latter,
+// and a CacheServerResolved condition describing the outcome is returned. The
+// Secret of a CacheServer can only be used in its namespace.
+func (r *VLLMRuntimeReconciler) resolveLMCacheRemote(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (lmCacheRemote, *metav1.Condition, error) {
+	lmCacheConfig := vr.Spec.LMCacheConfig
+	if !lmCacheConfig.Enabled || lmCacheConfig.CacheServerRef == nil && !lmCacheConfig.AutoDiscover {
+		return lmCacheRemote{url: lmCacheConfig.RemoteURL}, nil, nil
+	}
+
+	cacheServer, condition, err := r.lmCacheServer(ctx, vr)
+	if err != nil || condition != nil {
+		return lmCacheRemote{}, condition, err
+	}
+	namespace, name := cacheServer.Namespace, cacheServer.Name
+
+	if cacheServer.Status.Status != "Ready" {
+		return lmCacheRemote{}, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "CacheServerNotReady",
+			Message: fmt.Sprintf("CacheServer %s/%s is not ready", namespace, name),
+		}, nil
+	}
+
+	remote := lmCacheRemote{
+		url: cacheServerRemoteURL(cacheServer),
+		labels: map[string]string{
+			productionstackv1alpha1.CacheServerLabel:          name,
+			productionstackv1alpha1.CacheServerNamespaceLabel: namespace,
+		},
+	}
+
+	// The runtime could not read KV stored with another serde
+	if serde := cacheServer.Spec.Serde; serde != "" && serde != vr.Spec.LMCacheConfig.RemoteSerde {
+		remote.serdeMismatch = &metav1.Condition{
+			Type:   productionstackv1alpha1.ConditionRemoteSerdeMismatch,
+			Status: metav1.ConditionTrue,
+			Reason: "RemoteSerdeMismatch",
+			Message: fmt.Sprintf("lmCacheConfig.remoteSerde %q does not match the serde %q of CacheServer %s/%s",
+				vr.Spec.LMCacheConfig.RemoteSerde, serde, namespace, name),
+		}
+	}
+	if secretRef := cacheServerClientSecret(cacheServer); secretRef != nil {
+		if namespace != vr.Namespace {
+			return lmCacheRemote{}, &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+				Status:  metav1.ConditionFalse,
+				Reason:  "AuthSecretInOtherNamespace",
+				Message: fmt.Sprintf("CacheServer %s/%s requires a password, which is only available in namespace %s", namespace, name, namespace),
+			}, nil
+		}
+		token, secretCondition, err := resolveSecretKey(ctx, r.Client, namespace, secretRef.Name, secretRef.Key)
+		if err != nil {
+			return lmCacheRemote{}, nil, err
+		} else if secretCondition != nil {
+			return lmCacheRemote{}, &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+				Status:  metav1.ConditionFalse,
+				Reason:  "AuthSecretMissing",
+				Message: fmt.Sprintf("CacheServer %s/%s password: %s", namespace, name, secretCondition.Message),
+			}, nil
+		}
+		remote.password = secretRef
+		remote.url = remoteURLWithPassword(remote.url)
+		remote.authHash = hashString(string(token))
+	}
+
+	return remote, &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CacheServerReady",
+		Message: fmt.Sprintf("Using CacheServer %s/%s at %s", namespace, name, remote.url),
+	}, nil
+}
+
+// lastLMCacheRemote returns the remote cache server the Deployment of the
+// VLLMRuntime was last applied with, or none without Deployment
+func (r *VLLMRuntimeReconciler) lastLMCacheRemote(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (lmCacheRemote, error) {
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace}, dep); errors.IsNotFound(err) {
+		return lmCacheRemote{}, nil
+	} else if err != nil {
+		return lmCacheRemote{}, err
+	}
+
+	remote := lmCacheRemote{authHash: dep.Spec.Template.Annotations[lmCacheAuthHashAnnotation]}
+	for _, key := range []string{productionstackv1alpha1.CacheServerLabel, productionstackv1alpha1.CacheServerNamespaceLabel} {
+		if value, ok := dep.Spec.Template.Labels[key]; ok {
+			if remote.labels == nil {
+				remote.labels = map[string]string{}
+			}
+			remote.labels[key] = value
+		}
+	}
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		if container.Name != "vllm" {
+			continue
+		}
+		for _, e := range container.Env {
+			switch {
+			case e.Name == "LMCACHE_REMOTE_URL":
+				remote.url = e.Value
+			case e.Name == lmCacheRemotePasswordEnv && e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+				remote.password = &productionstackv1alpha1.SecretKeyReference{
+					Name: e.ValueFrom.SecretKeyRef.Name,
+					Key:  e.ValueFrom.SecretKeyRef.Key,
+				}
+			}
+		}
+	}
+	return remote, nil
+}
+
+// lmCacheServer returns the CacheServer of the VLLMRuntime, the one referenced
+// by lmCacheConfig.cacheServerRef or with autoDiscover the single one of its
+// namespace. A CacheServerResolved condition is returned instead when there is
+// no such CacheServer.
+func (r *VLLMRuntimeReconciler) lmCacheServer(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (*productionstackv1alpha1.CacheServer, *metav1.Condition, error) {
+	if ref := vr.Spec.LMCacheConfig.CacheServerRef; ref != nil {
+		namespace := cacheServerRefNamespace(vr)
+		cacheServer := &productionstackv1alpha1.CacheServer{}
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, cacheServer)
+		if err != nil && errors.IsNotFound(err) {
+			return nil, &metav1.Condition{
+				Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CacheServerNotFound",
+				Message: fmt.Sprintf("CacheServer %s/%s not found", namespace, ref.Name),
+			}, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return cacheServer, nil, nil
+	}
+
+	cacheServers := &productionstackv1alpha1.CacheServerList{}
+	if err := r.List(ctx, cacheServers, client.InNamespace(vr.Namespace)); err != nil {
+		return nil, nil, err
+	}
+	switch len(cacheServers.Items) {
+	case 0:
+		return nil, &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NoCacheServer",
+			Message: fmt.Sprintf("autoDiscover found no CacheServer in namespace %s", vr.Namespace),
+		}, nil
+	case 1:
+		return &cacheServers.Items[0], nil, nil
+	}
+	names := make([]string, 0, len(cacheServers.Items))
+	for _, cs := range cacheServers.Items {
+		names = append(names, cs.Name)
+	}
+	sort.Strings(names)
+	return nil, &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionCacheServerResolved,
+		Status:  metav1.ConditionFalse,
+		Reason:  "MultipleCacheServers",
+		Message: fmt.Sprintf("autoDiscover found %d CacheServers in namespace %s, %s; select one with cacheServerRef", len(names), vr.Namespace, strings.Join(names, ", ")),
+	}, nil
+}
+
+// remoteURLWithPassword returns the remote URL with the password of the
+// external backend, expanded by the kubelet from LMCACHE_REMOTE_PASSWORD. The
+// reference is added verbatim since url.URL would escape it.
+func remoteURLWithPassword(remoteURL string) string {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return remoteURL
+	}
+	userinfo := fmt.Sprintf(":$(%s)@", lmCacheRemotePasswordEnv)
+	if u.User != nil {
+		userinfo = url.User(u.User.Username()).String() + userinfo
+	}
+	u.User = nil
+	prefix := u.Scheme + "://"
+	return prefix + userinfo + strings.TrimPrefix(u.String(), prefix)
+}
+
+// resolveChatTemplate returns the content hash of the chat template referenced by
+// model.chatTemplateConfigMap, along with a ChatTemplateResolved condition.
+func (r *VLLMRuntimeReconciler) resolveChatTemplate(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (string, *metav1.Condition, error) {
+	ref := vr.Spec.Model.ChatTemplateConfigMap
+	if ref == nil {
+		return "", nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: vr.Namespace}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		return "", &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionChatTemplateResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ConfigMapNotFound",
+			Message: fmt.Sprintf("ConfigMap %s not found", ref.Name),
+		}, nil
+	} else if err != nil {
+		return "", nil, err
+	}
+
+	template, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", &metav1.Condition{
+			Type:    productionstackv1alpha1.ConditionChatTemplateResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "KeyNotFound",
+			Message: fmt.Sprintf("ConfigMap %s has no key %s", ref.Name, ref.Key),
+		}, nil
+	}
+
+	return hashString(template), &metav1.Condition{
+		Type:    productionstackv1alpha1.ConditionChatTemplateResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ChatTemplateFound",
+		Message: fmt.Sprintf("Using chat template %s/%s", ref.Name, ref.Key),
+	}, nil
+}
+
+// resolveHFTokenSecret returns the hash of the HF token used by the VLLMRuntime
+// and a SecretMissing condition if the Secret or key does not exist
+func (r *VLLMRuntimeReconciler) resolveHFTokenSecret(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (string, *metav1.Condition, error) {
+	if vr.Spec.HFTokenSecret.Name == "" || envVarDefined(vr.Spec.Env, "HF_TOKEN") {
+		return "", nil, nil
+	}
+
+	token, condition, err := resolveSecretKey(ctx, r.Client, vr.Namespace, vr.Spec.HFTokenSecret.Name, vr.Spec.HFTokenName)
+	if err != nil || condition != nil {
+		return "", condition, err
+	}
+	return hashString(string(token)), nil, nil
+}
+
+// resolveAPIKeySecret returns a SecretMissing condition if the API key Secret
+// or key of the VLLMRuntime does not exist
+func (r *VLLMRuntimeReconciler) resolveAPIKeySecret(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) (*metav1.Condition, error) {
+	ref := vr.Spec.APIKeySecret
+	if ref == nil || envVarDefined(vr.Spec.Env, "VLLM_API_KEY") {
+		return nil, nil
+	}
+
+	_, condition, err := resolveSecretKey(ctx, r.Client, vr.Namespace, ref.Name, ref.Key)
+	return condition, err
+}
+
+// cacheServerRefNamespace returns the namespace of the CacheServer referenced by the VLLMRuntime
+func cacheServerRefNamespace(vr *productionstackv1alpha1.VLLMRuntime) string {
+	if vr.Spec.LMCacheConfig.CacheServerRef.Namespace != "" {
+		return vr.Spec.LMCacheConfig.CacheServerRef.Namespace
+	}
+	return vr.Namespace
+}
+
 // deploymentForVLLMRuntime returns a VLLMRuntime Deployment object
-func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *productionstackv1alpha1.VLLMRuntime) *appsv1.Deployment {
+func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *productionstackv1alpha1.VLLMRuntime, inputs *resolvedInputs) *appsv1.Deployment {
 	labels := map[string]string{
 		"app": vllmRuntime.Name,
 	}
 
-	// Build command line arguments
-	args := []string{
-		"--model",
-		vllmRuntime.Spec.Model.ModelURL,
+	// Resolve the model location, mounting local weights when needed
+	modelPath := servedModelName(vllmRuntime.Spec.Model)
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	switch vllmRuntime.Spec.Model.Source {
+	case "pvc":
+		volumes = append(volumes, corev1.Volume{
+			Name: modelVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: vllmRuntime.Spec.Model.PVCName,
+					ReadOnly:  true,
+				},
+			},
+		})
+	case "hostPath":
+		hostPathType := corev1.HostPathDirectory
+		volumes = append(volumes, corev1.Volume{
+			Name: modelVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: vllmRuntime.Spec.Model.Path,
+					Type: &hostPathType,
+				},
+			},
+		})
+	}
+	if len(volumes) > 0 {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      modelVolumeName,
+			MountPath: modelMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// User volumes, copied since the applied object is overwritten with the server response
+	for i := range vllmRuntime.Spec.Volumes {
+		volumes = append(volumes, *vllmRuntime.Spec.Volumes[i].DeepCopy())
+	}
+	for i := range vllmRuntime.Spec.VolumeMounts {
+		volumeMounts = append(volumeMounts, *vllmRuntime.Spec.VolumeMounts[i].DeepCopy())
+	}
+
+	// Back the HuggingFace cache with a writable volume unless it is already mounted
+	hfCachePath := vllmRuntime.Spec.Model.HFCachePath
+	if hfCachePath != "" && !pathWithinVolumeMounts(volumeMounts, hfCachePath) {
+		volumes = append(volumes, corev1.Volume{
+			Name: hfCacheVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      hfCacheVolumeName,
+			MountPath: hfCachePath,
+		})
+	}
+
+	// Mount the chat template ConfigMap
+	if vllmRuntime.Spec.Model.ChatTemplateConfigMap != nil {
+		defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+		volumes = append(volumes, corev1.Volume{
+			Name: chatTemplateVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: vllmRuntime.Spec.Model.ChatTemplateConfigMap.Name,
+					},
+					DefaultMode: &defaultMode,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      chatTemplateVolumeName,
+			MountPath: chatTemplateMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// Build the command and command line arguments
+	var command, args []string
+	if vllmRuntime.Spec.CLIStyle == "vllmServe" {
+		command = []string{"vllm", "serve"}
+		args = []string{modelPath}
+	} else {
+		command = []string{"python3", "-m", "vllm.entrypoints.openai.api_server"}
+		args = []string{"--model", modelPath}
+	}
+	if len(vllmRuntime.Spec.Command) > 0 {
+		command = vllmRuntime.Spec.Command
+	}
+
+	args = append(args,
 		"--host",
 		"0.0.0.0",
 		"--port",
 		fmt.Sprintf("%d", vllmRuntime.Spec.Port),
-	}
+	)
 
 	if vllmRuntime.Spec.Model.EnableLoRA {
 		args = append(args, "--enable-lora")
 	}
 
+	if vllmRuntime.Spec.Model.EnableLoRA && len(vllmRuntime.Spec.LoRAModules) > 0 {
+		args = append(args, "--lora-modules")
+		for _, m := range vllmRuntime.Spec.LoRAModules {
+			args = append(args, m.Name+"="+m.Path)
+		}
+	}
+
 	if vllmRuntime.Spec.Model.EnableTool {
 		args = append(args, "--enable-auto-tool-choice")
 	}
@@ -196,6 +1143,34 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		args = append(args, "--dtype", vllmRuntime.Spec.Model.DType)
 	}
 
+	if vllmRuntime.Spec.Model.ChatTemplateConfigMap != nil {
+		args = append(args, "--chat-template", path.Join(chatTemplateMountPath, vllmRuntime.Spec.Model.ChatTemplateConfigMap.Key))
+	}
+
+	if vllmRuntime.Spec.Model.TrustRemoteCode {
+		args = append(args, "--trust-remote-code")
+	}
+
+	if vllmRuntime.Spec.EnableSleepMode {
+		args = append(args, "--enable-sleep-mode")
+	}
+
+	if vllmRuntime.Spec.Model.Quantization != "" {
+		args = append(args, "--quantization", vllmRuntime.Spec.Model.Quantization)
+	}
+
+	if vllmRuntime.Spec.Model.LoadFormat != "" {
+		args = append(args, "--load-format", vllmRuntime.Spec.Model.LoadFormat)
+	}
+
+	if vllmRuntime.Spec.Model.Tokenizer != "" {
+		args = append(args, "--tokenizer", vllmRuntime.Spec.Model.Tokenizer)
+	}
+
+	if vllmRuntime.Spec.Model.TokenizerMode != "" {
+		args = append(args, "--tokenizer-mode", vllmRuntime.Spec.Model.TokenizerMode)
+	}
+
 	if vllmRuntime.Spec.TensorParallelSize > 0 {
 		args = append(args, "--tensor-parallel-size", fmt.Sprintf("%d", vllmRuntime.Spec.TensorParallelSize))
 	}
@@ -212,6 +1187,10 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		args = append(args, "--max_loras", fmt.Sprintf("%d", vllmRuntime.Spec.MaxLoras))
 	}
 
+	if vllmRuntime.Spec.SpeculativeDecoding != nil {
+		args = append(args, speculativeDecodingArgs(vllmRuntime.Spec.SpeculativeDecoding)...)
+	}
+
 	if vllmRuntime.Spec.ExtraArgs != nil {
 		args = append(args, vllmRuntime.Spec.ExtraArgs...)
 	}
@@ -230,6 +1209,36 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		})
 	}
 
+	// HuggingFace Hub configuration
+	if vllmRuntime.Spec.Model.HFEndpoint != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "HF_ENDPOINT",
+			Value: vllmRuntime.Spec.Model.HFEndpoint,
+		})
+	}
+
+	// Local model weights must not be fetched from the hub
+	localModel := vllmRuntime.Spec.Model.Source == "pvc" || vllmRuntime.Spec.Model.Source == "hostPath"
+	if vllmRuntime.Spec.Model.HFHubOffline || localModel {
+		env = append(env, corev1.EnvVar{
+			Name:  "HF_HUB_OFFLINE",
+			Value: "1",
+		})
+	}
+
+	if hfCachePath != "" {
+		env = append(env,
+			corev1.EnvVar{
+				Name:  "HF_HOME",
+				Value: hfCachePath,
+			},
+			corev1.EnvVar{
+				Name:  "HUGGINGFACE_HUB_CACHE",
+				Value: path.Join(hfCachePath, "hub"),
+			},
+		)
+	}
+
 	// LM Cache configuration
 	if vllmRuntime.Spec.LMCacheConfig.Enabled {
 		env = append(env,
@@ -282,11 +1291,25 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 			)
 		}
 
-		if vllmRuntime.Spec.LMCacheConfig.RemoteURL != "" {
+		// The password of an external backend, referenced by the remote URL
+		// and so declared before it
+		if ref := inputs.lmCachePassword; ref != nil {
+			env = append(env, corev1.EnvVar{
+				Name: lmCacheRemotePasswordEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+						Key:                  ref.Key,
+					},
+				},
+			})
+		}
+
+		if inputs.remoteURL != "" {
 			env = append(env,
 				corev1.EnvVar{
 					Name:  "LMCACHE_REMOTE_URL",
-					Value: vllmRuntime.Spec.LMCacheConfig.RemoteURL,
+					Value: inputs.remoteURL,
 				},
 				corev1.EnvVar{
 					Name:  "LMCACHE_REMOTE_SERDE",
@@ -296,13 +1319,55 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		}
 	}
 
+	// vLLM reads the API key from VLLM_API_KEY, keeping it out of the args
+	if ref := vllmRuntime.Spec.APIKeySecret; ref != nil && !envVarDefined(vllmRuntime.Spec.Env, "VLLM_API_KEY") {
+		env = append(env, corev1.EnvVar{
+			Name: "VLLM_API_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+					Key:                  ref.Key,
+				},
+			},
+		})
+	}
+
+	// The sleep endpoints are only served in development mode
+	if vllmRuntime.Spec.EnableSleepMode && !envVarDefined(vllmRuntime.Spec.Env, "VLLM_SERVER_DEV_MODE") {
+		env = append(env, corev1.EnvVar{
+			Name:  "VLLM_SERVER_DEV_MODE",
+			Value: "1",
+		})
+	}
+
+	// GPU device selection and NCCL tuning, overridden by user-defined variables
+	if gpu := vllmRuntime.Spec.GPU; gpu != nil {
+		if gpu.VisibleDevices != "" && !envVarDefined(vllmRuntime.Spec.Env, "CUDA_VISIBLE_DEVICES") {
+			env = append(env, corev1.EnvVar{
+				Name:  "CUDA_VISIBLE_DEVICES",
+				Value: gpu.VisibleDevices,
+			})
+		}
+
+		ncclNames := make([]string, 0, len(gpu.NCCLEnv))
+		for name := range gpu.NCCLEnv {
+			if !envVarDefined(vllmRuntime.Spec.Env, name) {
+				ncclNames = append(ncclNames, name)
+			}
+		}
+		sort.Strings(ncclNames)
+		for _, name := range ncclNames {
+			env = append(env, corev1.EnvVar{
+				Name:  name,
+				Value: gpu.NCCLEnv[name],
+			})
+		}
+	}
+
 	// Add user-defined environment variables
 	if vllmRuntime.Spec.Env != nil {
 		for _, e := range vllmRuntime.Spec.Env {
-			env = append(env, corev1.EnvVar{
-				Name:  e.Name,
-				Value: e.Value,
-			})
+			env = append(env, containerEnvVar(e))
 		}
 	}
 
@@ -312,25 +1377,13 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		Limits:   corev1.ResourceList{},
 	}
 
-	if vllmRuntime.Spec.Resources.CPU != "" {
-		resources.Requests[corev1.ResourceCPU] = resource.MustParse(vllmRuntime.Spec.Resources.CPU)
-		resources.Limits[corev1.ResourceCPU] = resource.MustParse(vllmRuntime.Spec.Resources.CPU)
-	}
-
-	if vllmRuntime.Spec.Resources.Memory != "" {
-		resources.Requests[corev1.ResourceMemory] = resource.MustParse(vllmRuntime.Spec.Resources.Memory)
-		resources.Limits[corev1.ResourceMemory] = resource.MustParse(vllmRuntime.Spec.Resources.Memory)
-	}
-
-	if vllmRuntime.Spec.Resources.GPU != "" {
-		// Parse GPU resource as a decimal value
-		gpuResource := resource.MustParse(vllmRuntime.Spec.Resources.GPU)
-		resources.Requests["nvidia.com/gpu"] = gpuResource
-		resources.Limits["nvidia.com/gpu"] = gpuResource
-	}
+	// Reconcile stops at unparsable quantities, so none are left out here
+	setResourceQuantity(&resources, corev1.ResourceCPU, vllmRuntime.Spec.Resources.CPU)
+	setResourceQuantity(&resources, corev1.ResourceMemory, vllmRuntime.Spec.Resources.Memory)
+	setResourceQuantity(&resources, "nvidia.com/gpu", vllmRuntime.Spec.Resources.GPU)
 
 	// Get the image from Image spec or use default
-	image := vllmRuntime.Spec.Image.Registry + "/" + vllmRuntime.Spec.Image.Name
+	image := imageReference(vllmRuntime.Spec.Image)
 
 	// Get the image pull policy
 	imagePullPolicy := corev1.PullIfNotPresent
@@ -346,7 +1399,7 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		})
 	}
 
-	if vllmRuntime.Spec.HFTokenSecret.Name != "" {
+	if vllmRuntime.Spec.HFTokenSecret.Name != "" && !envVarDefined(vllmRuntime.Spec.Env, "HF_TOKEN") {
 		env = append(env, corev1.EnvVar{
 			Name: "HF_TOKEN",
 			ValueFrom: &corev1.EnvVarSource{
@@ -358,37 +1411,104 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 		})
 	}
 
+	// Give the router time to stop sending traffic before vLLM shuts down
+	var lifecycle *corev1.Lifecycle
+	if vllmRuntime.Spec.Termination.DrainSleepSeconds > 0 {
+		lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sleep", fmt.Sprintf("%d", vllmRuntime.Spec.Termination.DrainSleepSeconds)},
+				},
+			},
+		}
+	}
+	terminationGracePeriodSeconds := vllmRuntime.Spec.Termination.GracePeriodSeconds
+
+	// User annotations and annotations tracking referenced objects
+	var podAnnotations map[string]string
+	if len(vllmRuntime.Spec.PodAnnotations) > 0 || len(inputs.annotations) > 0 {
+		podAnnotations = make(map[string]string, len(vllmRuntime.Spec.PodAnnotations)+len(inputs.annotations))
+		for k, v := range vllmRuntime.Spec.PodAnnotations {
+			podAnnotations[k] = v
+		}
+		for k, v := range inputs.annotations {
+			podAnnotations[k] = v
+		}
+	}
+	if vllmRuntime.Spec.Image.RolloutOnChange {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[imageAnnotation] = image
+	}
+
+	// Copy the fields shared with the spec, the applied object is overwritten
+	// with the server response
+	replicas := vllmRuntime.Spec.Replicas
+	var envFrom []corev1.EnvFromSource
+	for i := range vllmRuntime.Spec.EnvFrom {
+		envFrom = append(envFrom, *vllmRuntime.Spec.EnvFrom[i].DeepCopy())
+	}
+
+	// Pods get DNS records in the headless service through their subdomain
+	var subdomain string
+	if vllmRuntime.Spec.HeadlessService {
+		subdomain = headlessServiceName(vllmRuntime)
+	}
+
+	// Pods are also labeled with the served model for service discovery
+	podLabels := map[string]string{
+		"app":                              vllmRuntime.Name,
+		productionstackv1alpha1.ModelLabel: modelLabelValue(modelPath),
+	}
+	for key, value := range inputs.labels {
+		podLabels[key] = value
+	}
+
 	dep := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      vllmRuntime.Name,
 			Namespace: vllmRuntime.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &vllmRuntime.Spec.Replicas,
+			Replicas: &replicas,
 			Strategy: appsv1.DeploymentStrategy{
-				Type: appsv1.DeploymentStrategyType(vllmRuntime.Spec.DeployStrategy),
+				Type: deploymentStrategyType(vllmRuntime.Spec),
 			},
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      podLabels,
+					Annotations: podAnnotations,
 				},
 				Spec: corev1.PodSpec{
-					ImagePullSecrets: imagePullSecrets,
+					Subdomain:                     subdomain,
+					SchedulerName:                 vllmRuntime.Spec.SchedulerName,
+					ImagePullSecrets:              imagePullSecrets,
+					Volumes:                       volumes,
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
 					Containers: []corev1.Container{
 						{
 							Name:            "vllm",
 							Image:           image,
 							ImagePullPolicy: imagePullPolicy,
-							Command:         []string{"python3", "-m", "vllm.entrypoints.openai.api_server"},
+							Command:         command,
 							Args:            args,
 							Env:             env,
+							EnvFrom:         envFrom,
+							VolumeMounts:    volumeMounts,
+							Lifecycle:       lifecycle,
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "http",
 									ContainerPort: vllmRuntime.Spec.Port,
+									Protocol:      corev1.ProtocolTCP,
 								},
 							},
 							Resources: resources,
@@ -432,81 +1552,69 @@ func (r *VLLMRuntimeReconciler) deploymentForVLLMRuntime(vllmRuntime *production
 	return dep
 }
 
-// deploymentNeedsUpdate checks if the deployment needs to be updated
-func (r *VLLMRuntimeReconciler) deploymentNeedsUpdate(dep *appsv1.Deployment, vr *productionstackv1alpha1.VLLMRuntime) bool {
-	// Generate the expected deployment
-	expectedDep := r.deploymentForVLLMRuntime(vr)
-
-	// Compare model URL
-	expectedModelURL := vr.Spec.Model.ModelURL
-	actualModelURL := ""
-	// For vllm serve, the model URL is the first argument after the command
-	if len(dep.Spec.Template.Spec.Containers[0].Args) > 0 {
-		actualModelURL = dep.Spec.Template.Spec.Containers[0].Args[1]
-	}
-	if expectedModelURL != actualModelURL {
-		return true
+// servedModelName returns the model passed to vLLM: the model URL, or the
+// mount path of local model weights
+func servedModelName(model productionstackv1alpha1.ModelSpec) string {
+	switch model.Source {
+	case "pvc":
+		return path.Join(modelMountPath, model.Path)
+	case "hostPath":
+		return modelMountPath
 	}
+	return model.ModelURL
+}
 
-	// Compare port
-	expectedPort := vr.Spec.Port
-	actualPort := dep.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort
-	if expectedPort != actualPort {
-		return true
-	}
+// runtimeServiceEndpoint returns the in-cluster URL of the runtime Service
+func runtimeServiceEndpoint(vr *productionstackv1alpha1.VLLMRuntime) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", vr.Name, vr.Namespace, vllmRuntimeServicePort)
+}
 
-	// Compare image
-	if expectedDep.Spec.Template.Spec.Containers[0].Image != dep.Spec.Template.Spec.Containers[0].Image {
-		return true
-	}
+// modelLabelValue converts a model name into a valid label value: lower case
+// alphanumerics, '-' and '.', at most 63 characters. Truncated names keep a
+// hash suffix so distinct models get distinct labels.
+func modelLabelValue(model string) string {
+	value := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, model)
+	value = strings.Trim(value, "-.")
 
-	// Compare resources
-	expectedResources := expectedDep.Spec.Template.Spec.Containers[0].Resources
-	actualResources := dep.Spec.Template.Spec.Containers[0].Resources
-	if !reflect.DeepEqual(expectedResources, actualResources) {
-		return true
+	if len(value) > validation.LabelValueMaxLength {
+		suffix := hashString(model)[:8]
+		value = strings.TrimRight(value[:validation.LabelValueMaxLength-len(suffix)-1], "-.") + "-" + suffix
 	}
+	return value
+}
 
-	// Compare LM Cache configuration
-	expectedLMCacheConfig := vr.Spec.LMCacheConfig
-	actualLMCacheConfig := dep.Spec.Template.Spec.Containers[0].Env
-
-	// Extract actual values from environment variables
-	actualEnabled := false
-	actualCPUOffloadingBufferSize := ""
-	actualDiskOffloadingBufferSize := ""
-	actualRemoteURL := ""
-	actualRemoteSerde := ""
-
-	for _, env := range actualLMCacheConfig {
-		switch env.Name {
-		case "LMCACHE_USE_EXPERIMENTAL":
-			actualEnabled = env.Value == "True"
-		case "LMCACHE_MAX_LOCAL_CPU_SIZE":
-			actualCPUOffloadingBufferSize = env.Value
-		case "LMCACHE_MAX_LOCAL_DISK_SIZE":
-			actualDiskOffloadingBufferSize = env.Value
-		case "LMCACHE_REMOTE_URL":
-			actualRemoteURL = env.Value
-		case "LMCACHE_REMOTE_SERDE":
-			actualRemoteSerde = env.Value
+// pathWithinVolumeMounts reports whether p is at or below one of the mount paths
+func pathWithinVolumeMounts(mounts []corev1.VolumeMount, p string) bool {
+	p = path.Clean(p)
+	for _, m := range mounts {
+		mountPath := path.Clean(m.MountPath)
+		if p == mountPath || strings.HasPrefix(p, mountPath+"/") {
+			return true
 		}
 	}
+	return false
+}
 
-	// Compare specific fields
-	if expectedLMCacheConfig.Enabled != actualEnabled ||
-		expectedLMCacheConfig.CPUOffloadingBufferSize != actualCPUOffloadingBufferSize ||
-		expectedLMCacheConfig.DiskOffloadingBufferSize != actualDiskOffloadingBufferSize ||
-		expectedLMCacheConfig.RemoteURL != actualRemoteURL ||
-		expectedLMCacheConfig.RemoteSerde != actualRemoteSerde {
-		return true
+// envVarDefined reports whether the user-defined environment contains the named variable
+func envVarDefined(env []productionstackv1alpha1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
 	}
-
 	return false
 }
 
 // updateStatus updates the status of the VLLMRuntime
-func (r *VLLMRuntimeReconciler) updateStatus(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime, dep *appsv1.Deployment) error {
+func (r *VLLMRuntimeReconciler) updateStatus(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime, dep *appsv1.Deployment, sleepState string) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Get the latest version of the VLLMRuntime
 		latestVR := &productionstackv1alpha1.VLLMRuntime{}
@@ -517,6 +1625,24 @@ func (r *VLLMRuntimeReconciler) updateStatus(ctx context.Context, vr *production
 		// Update the status fields
 		latestVR.Status.LastUpdated = metav1.Now()
 
+		latestVR.Status.ModelName = servedModelName(vr.Spec.Model)
+		latestVR.Status.ServiceEndpoint = runtimeServiceEndpoint(vr)
+		latestVR.Status.HeadlessServiceName = ""
+		if vr.Spec.HeadlessService {
+			latestVR.Status.HeadlessServiceName = headlessServiceName(vr)
+		}
+		latestVR.Status.URL = ingressURL(vr.Spec.Ingress)
+		latestVR.Status.SleepState = sleepState
+		latestVR.Status.APIKeySecret = vr.Spec.APIKeySecret
+
+		// Record the adapters loaded at startup
+		latestVR.Status.LoRAModules = nil
+		if vr.Spec.Model.EnableLoRA {
+			for _, m := range vr.Spec.LoRAModules {
+				latestVR.Status.LoRAModules = append(latestVR.Status.LoRAModules, m.Name)
+			}
+		}
+
 		// Update model status based on deployment status
 		if dep.Status.AvailableReplicas > 0 {
 			latestVR.Status.ModelStatus = "Ready"
@@ -531,13 +1657,82 @@ func (r *VLLMRuntimeReconciler) updateStatus(ctx context.Context, vr *production
 	})
 }
 
+// reconcileCondition records the condition resolved for a referenced object and
+// reports whether reconciliation can proceed. A nil condition means the reference
+// is not configured and any stale condition of that type is removed.
+func (r *VLLMRuntimeReconciler) reconcileCondition(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime, conditionType string, condition *metav1.Condition) (bool, error) {
+	if condition == nil {
+		if meta.FindStatusCondition(vr.Status.Conditions, conditionType) == nil {
+			return true, nil
+		}
+		return true, retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latestVR := &productionstackv1alpha1.VLLMRuntime{}
+			if err := r.Get(ctx, types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace}, latestVR); err != nil {
+				return err
+			}
+			if !meta.RemoveStatusCondition(&latestVR.Status.Conditions, conditionType) {
+				return nil
+			}
+			return r.Status().Update(ctx, latestVR)
+		})
+	}
+
+	if err := r.setStatusCondition(ctx, vr, *condition); err != nil {
+		return false, err
+	}
+	return condition.Status == metav1.ConditionTrue, nil
+}
+
+// setStatusCondition records a condition on the VLLMRuntime status if it changed
+func (r *VLLMRuntimeReconciler) setStatusCondition(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime, condition metav1.Condition) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Get the latest version of the VLLMRuntime
+		latestVR := &productionstackv1alpha1.VLLMRuntime{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace}, latestVR); err != nil {
+			return err
+		}
+
+		condition.ObservedGeneration = latestVR.Generation
+		if !meta.SetStatusCondition(&latestVR.Status.Conditions, condition) {
+			return nil
+		}
+		latestVR.Status.LastUpdated = metav1.Now()
+
+		return r.Status().Update(ctx, latestVR)
+	})
+}
+
 // serviceForVLLMRuntime returns a VLLMRuntime Service object
 func (r *VLLMRuntimeReconciler) serviceForVLLMRuntime(vllmRuntime *productionstackv1alpha1.VLLMRuntime) *corev1.Service {
 	labels := map[string]string{
 		"app": vllmRuntime.Name,
 	}
 
+	ports := []corev1.ServicePort{
+		{
+			Name:       "http",
+			Port:       vllmRuntimeServicePort,
+			TargetPort: intstr.FromInt(int(vllmRuntime.Spec.Port)),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+
+	// Metrics are served on the HTTP port of vLLM. The container port is not
+	// declared twice, duplicate container ports break server-side apply.
+	if vllmRuntime.Spec.MetricsPort != 0 {
+		ports = append(ports, corev1.ServicePort{
+			Name:       "metrics",
+			Port:       vllmRuntime.Spec.MetricsPort,
+			TargetPort: intstr.FromInt(int(vllmRuntime.Spec.Port)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+
 	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      vllmRuntime.Name,
 			Namespace: vllmRuntime.Namespace,
@@ -545,10 +1740,43 @@ func (r *VLLMRuntimeReconciler) serviceForVLLMRuntime(vllmRuntime *productionsta
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeClusterIP,
 			Selector: labels,
+			Ports:    ports,
+		},
+	}
+
+	// Set the owner reference
+	ctrl.SetControllerReference(vllmRuntime, svc, r.Scheme)
+	return svc
+}
+
+// headlessServiceName returns the name of the headless Service of the VLLMRuntime
+func headlessServiceName(vr *productionstackv1alpha1.VLLMRuntime) string {
+	return vr.Name + "-pods"
+}
+
+// headlessServiceForVLLMRuntime returns the headless Service addressing the
+// VLLMRuntime pods individually
+func (r *VLLMRuntimeReconciler) headlessServiceForVLLMRuntime(vllmRuntime *productionstackv1alpha1.VLLMRuntime) *corev1.Service {
+	labels := map[string]string{
+		"app": vllmRuntime.Name,
+	}
+
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      headlessServiceName(vllmRuntime),
+			Namespace: vllmRuntime.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "http",
-					Port:       80,
+					Port:       vllmRuntime.Spec.Port,
 					TargetPort: intstr.FromInt(int(vllmRuntime.Spec.Port)),
 					Protocol:   corev1.ProtocolTCP,
 				},
@@ -561,23 +1789,138 @@ func (r *VLLMRuntimeReconciler) serviceForVLLMRuntime(vllmRuntime *productionsta
 	return svc
 }
 
-// serviceNeedsUpdate checks if the service needs to be updated
-func (r *VLLMRuntimeReconciler) serviceNeedsUpdate(svc *corev1.Service, vr *productionstackv1alpha1.VLLMRuntime) bool {
-	// Compare target port
-	expectedTargetPort := int(vr.Spec.Port)
-	actualTargetPort := svc.Spec.Ports[0].TargetPort.IntValue()
-	if expectedTargetPort != actualTargetPort {
-		return true
+// deleteHeadlessService removes the headless Service after headlessService was turned off
+func (r *VLLMRuntimeReconciler) deleteHeadlessService(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) error {
+	svc := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: headlessServiceName(vr), Namespace: vr.Namespace}, svc)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
 	}
-
-	return false
+	if !metav1.IsControlledBy(svc, vr) {
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting headless Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, svc))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VLLMRuntimeReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&productionstackv1alpha1.VLLMRuntime{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.Ingress{})
+
+	// HTTPRoutes can only be watched when the Gateway API CRDs are installed
+	installed, err := gatewayAPIInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		b = b.Owns(newHTTPRoute())
+	}
+
+	return b.
+		Watches(
+			&productionstackv1alpha1.CacheServer{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRuntimesForCacheServer),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRuntimesForConfigMap),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findVLLMRuntimesForSecret),
+		).
 		Complete(r)
 }
+
+// findVLLMRuntimesForCacheServer maps a CacheServer to the VLLMRuntimes that
+// reference it, or may discover it in its namespace
+func (r *VLLMRuntimeReconciler) findVLLMRuntimesForCacheServer(ctx context.Context, obj client.Object) []reconcile.Request {
+	vllmRuntimes := &productionstackv1alpha1.VLLMRuntimeList{}
+	if err := r.List(ctx, vllmRuntimes); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRuntimes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range vllmRuntimes.Items {
+		vr := &vllmRuntimes.Items[i]
+		ref := vr.Spec.LMCacheConfig.CacheServerRef
+		discovered := vr.Spec.LMCacheConfig.AutoDiscover && vr.Namespace == obj.GetNamespace()
+		referenced := ref != nil && ref.Name == obj.GetName() && cacheServerRefNamespace(vr) == obj.GetNamespace()
+		if !discovered && !referenced {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace},
+		})
+	}
+	return requests
+}
+
+// cacheServerAuthSecretName returns the password Secret of the
+// CacheServer used by the VLLMRuntime in its namespace, if any
+func (r *VLLMRuntimeReconciler) cacheServerAuthSecretName(ctx context.Context, vr *productionstackv1alpha1.VLLMRuntime) string {
+	lmCacheConfig := vr.Spec.LMCacheConfig
+	if lmCacheConfig.CacheServerRef == nil && !lmCacheConfig.AutoDiscover {
+		return ""
+	}
+	cacheServer, _, err := r.lmCacheServer(ctx, vr)
+	if err != nil || cacheServer == nil || cacheServer.Namespace != vr.Namespace {
+		return ""
+	}
+	if secretRef := cacheServerClientSecret(cacheServer); secretRef != nil {
+		return secretRef.Name
+	}
+	return ""
+}
+
+// findVLLMRuntimesForConfigMap maps a ConfigMap to the VLLMRuntimes using it as chat template
+func (r *VLLMRuntimeReconciler) findVLLMRuntimesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	vllmRuntimes := &productionstackv1alpha1.VLLMRuntimeList{}
+	if err := r.List(ctx, vllmRuntimes, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRuntimes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, vr := range vllmRuntimes.Items {
+		ref := vr.Spec.Model.ChatTemplateConfigMap
+		if ref == nil || ref.Name != obj.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace},
+		})
+	}
+	return requests
+}
+
+// findVLLMRuntimesForSecret maps a Secret to the VLLMRuntimes using it as HF
+// token, API key or CacheServer auth secret
+func (r *VLLMRuntimeReconciler) findVLLMRuntimesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	vllmRuntimes := &productionstackv1alpha1.VLLMRuntimeList{}
+	if err := r.List(ctx, vllmRuntimes, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list VLLMRuntimes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, vr := range vllmRuntimes.Items {
+		usesSecret := vr.Spec.HFTokenSecret.Name == obj.GetName() ||
+			(vr.Spec.APIKeySecret != nil && vr.Spec.APIKeySecret.Name == obj.GetName()) ||
+			r.cacheServerAuthSecretName(ctx, &vr) == obj.GetName()
+		if !usesSecret {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vr.Name, Namespace: vr.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var cacheserverlog = logf.Log.WithName("cacheserver-resource")
+
+// externalBackendSchemes are the remote URL schemes of LMCache served by Redis
+// and Valkey
+var externalBackendSchemes = []string{"redis", "rediss", "redis-sentinel"}
+
+// SetupCacheServerWebhookWithManager registers the webhook for CacheServer in the manager.
+func SetupCacheServerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&productionstackv1alpha1.CacheServer{}).
+		WithValidator(&CacheServerCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-production-stack-vllm-ai-v1alpha1-cacheserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=production-stack.vllm.ai,resources=cacheservers,verbs=create;update,versions=v1alpha1,name=vcacheserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// CacheServerCustomValidator validates the cross-field constraints of a
+// CacheServer that the CRD schema cannot express.
+type CacheServerCustomValidator struct{}
+
+var _ webhook.CustomValidator = &CacheServerCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type CacheServer.
+func (v *CacheServerCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cacheserver, ok := obj.(*productionstackv1alpha1.CacheServer)
+	if !ok {
+		return nil, fmt.Errorf("expected a CacheServer object but got %T", obj)
+	}
+	cacheserverlog.Info("Validation for CacheServer upon creation", "name", cacheserver.GetName())
+
+	return nil, validateCacheServer(cacheserver)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type CacheServer.
+// Updates leaving the spec unchanged, e.g. annotations or finalizers, are always allowed.
+func (v *CacheServerCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCacheServer, ok := oldObj.(*productionstackv1alpha1.CacheServer)
+	if !ok {
+		return nil, fmt.Errorf("expected a CacheServer object for the oldObj but got %T", oldObj)
+	}
+	cacheserver, ok := newObj.(*productionstackv1alpha1.CacheServer)
+	if !ok {
+		return nil, fmt.Errorf("expected a CacheServer object for the newObj but got %T", newObj)
+	}
+	cacheserverlog.Info("Validation for CacheServer upon update", "name", cacheserver.GetName())
+
+	if equality.Semantic.DeepEqual(oldCacheServer.Spec, cacheserver.Spec) {
+		return nil, nil
+	}
+	return nil, validateCacheServer(cacheserver)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type CacheServer.
+func (v *CacheServerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateCacheServer returns an Invalid error listing every bad field of the CacheServer
+func validateCacheServer(cs *productionstackv1alpha1.CacheServer) error {
+	specPath := field.NewPath("spec")
+
+	gpus, allErrs := validateResourceQuantities(cs.Spec.Resources.ResourceRequirements, specPath.Child("resources"))
+	allErrs = append(allErrs, validateExtraResources(cs.Spec.Resources.Extra, specPath.Child("resources", "extra"))...)
+
+	// The cuda device holds the cache in GPU memory
+	if cs.Spec.Device == "cuda" && gpus == 0 && cs.Spec.Mode != "external" {
+		allErrs = append(allErrs, field.Required(specPath.Child("resources", "gpu"), "required when device is cuda"))
+	}
+
+	// lmcache_experimental_server serves no metrics, so there is no port to
+	// default to
+	if cs.Spec.Monitoring.Enabled && cs.Spec.Monitoring.MetricsPort == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("monitoring", "metricsPort"), "required when monitoring is enabled"))
+	}
+
+	if cs.Spec.Mode == "external" && cs.Spec.External != nil {
+		allErrs = append(allErrs, validateExternalMode(cs, specPath)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(productionstackv1alpha1.GroupVersion.WithKind("CacheServer").GroupKind(), cs.Name, allErrs)
+}
+
+// validateExternalMode checks the URL of the external backend and that the
+// features of the lmcache server are not requested in external mode
+func validateExternalMode(cs *productionstackv1alpha1.CacheServer, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	urlPath := specPath.Child("external", "url")
+	u, err := url.Parse(cs.Spec.External.URL)
+	if err != nil || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(urlPath, cs.Spec.External.URL, "must be a URL of the form <scheme>://<host>:<port>"))
+	} else if !slices.Contains(externalBackendSchemes, u.Scheme) {
+		allErrs = append(allErrs, field.NotSupported(urlPath.Key("scheme"), u.Scheme, externalBackendSchemes))
+	} else if cs.Spec.External.Service && strings.Contains(u.Host, ",") {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("external", "service"), true, "requires a single host in url"))
+	}
+
+	// The lmcache server features, which create resources for its pods
+	forbidden := []struct {
+		path string
+		set  bool
+	}{
+		{"config", cs.Spec.Config != nil},
+		{"storage", cs.Spec.Storage.Enabled},
+		{"autoscaling", cs.Spec.Autoscaling.Enabled},
+		{"podDisruptionBudget", cs.Spec.PodDisruptionBudget.Enabled},
+		{"monitoring", cs.Spec.Monitoring.Enabled},
+		{"networkPolicy", cs.Spec.NetworkPolicy.Enabled},
+	}
+	for _, f := range forbidden {
+		if f.set {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child(f.path), "not used in external mode"))
+		}
+	}
+	return allErrs
+}
+
+// validateExtraResources checks that the extra resources are extended
+// resources requested in whole, positive counts, as the scheduler requires
+func validateExtraResources(extra map[string]string, extraPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		namePath := extraPath.Key(name)
+		domain, _, found := strings.Cut(name, "/")
+		switch {
+		case len(validation.IsQualifiedName(name)) > 0 || !found:
+			allErrs = append(allErrs, field.Invalid(namePath, name, "must be an extended resource name with a domain, e.g. rdma/hca"))
+			continue
+		case domain == "kubernetes.io" || strings.HasSuffix(domain, ".kubernetes.io"):
+			allErrs = append(allErrs, field.Invalid(namePath, name, "must not be in the kubernetes.io domain"))
+			continue
+		case name == "nvidia.com/gpu":
+			allErrs = append(allErrs, field.Invalid(namePath, name, "is requested with resources.gpu"))
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(extra[name])
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(namePath, extra[name], err.Error()))
+		} else if quantity.Sign() <= 0 || quantity.MilliValue()%1000 != 0 {
+			allErrs = append(allErrs, field.Invalid(namePath, extra[name], "must be a positive whole number"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+var _ = Describe("CacheServer Webhook", func() {
+	var validator CacheServerCustomValidator
+
+	newCacheServer := func() *productionstackv1alpha1.CacheServer {
+		return &productionstackv1alpha1.CacheServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cache",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.CacheServerSpec{
+				Image:    productionstackv1alpha1.ImageSpec{Registry: "docker.io", Name: "lmcache/vllm-openai:latest"},
+				Port:     8000,
+				Replicas: 1,
+				Resources: productionstackv1alpha1.CacheServerResources{
+					ResourceRequirements: productionstackv1alpha1.ResourceRequirements{CPU: "4", Memory: "16Gi"},
+				},
+			},
+		}
+	}
+
+	DescribeTable("When creating a CacheServer",
+		func(mutate func(*productionstackv1alpha1.CacheServer), expectedFields ...string) {
+			cacheserver := newCacheServer()
+			mutate(cacheserver)
+
+			_, err := validator.ValidateCreate(ctx, cacheserver)
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+		Entry("valid spec", func(*productionstackv1alpha1.CacheServer) {}),
+		Entry("unparsable CPU quantity", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.CPU = "four"
+		}, "spec.resources.cpu"),
+		Entry("unparsable memory quantity", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.Memory = "16GB"
+		}, "spec.resources.memory"),
+		Entry("unparsable GPU quantity", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.GPU = "one"
+		}, "spec.resources.gpu"),
+		Entry("cuda device with a GPU", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Device = "cuda"
+			cs.Spec.Resources.GPU = "1"
+		}),
+		Entry("cuda device without a GPU", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Device = "cuda"
+		}, "spec.resources.gpu"),
+		Entry("cuda device with 0 GPUs", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Device = "cuda"
+			cs.Spec.Resources.GPU = "0"
+		}, "spec.resources.gpu"),
+		Entry("extra extended resources", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.Extra = map[string]string{"rdma/hca": "1", "example.com/nic": "2"}
+		}),
+		Entry("extra resources without a domain or in kubernetes.io", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.Extra = map[string]string{"hca": "1", "kubernetes.io/hca": "1", "nvidia.com/gpu": "1"}
+		}, "spec.resources.extra[hca]", "spec.resources.extra[kubernetes.io/hca]", "spec.resources.extra[nvidia.com/gpu]"),
+		Entry("extra resources that are not whole counts", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Resources.Extra = map[string]string{"rdma/hca": "500m", "example.com/nic": "one", "example.com/fpga": "0"}
+		}, "spec.resources.extra[rdma/hca]", "spec.resources.extra[example.com/nic]", "spec.resources.extra[example.com/fpga]"),
+		Entry("monitoring with a metrics port", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Monitoring.Enabled = true
+			cs.Spec.Monitoring.MetricsPort = 9100
+		}),
+		Entry("monitoring without a metrics port", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Monitoring.Enabled = true
+		}, "spec.monitoring.metricsPort"),
+		Entry("external backend", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Mode = "external"
+			cs.Spec.External = &productionstackv1alpha1.CacheServerExternalSpec{URL: "redis://valkey.cache.svc:6379", Service: true}
+		}),
+		Entry("external backend with an unsupported scheme", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Mode = "external"
+			cs.Spec.External = &productionstackv1alpha1.CacheServerExternalSpec{URL: "memcached://cache:11211"}
+		}, "spec.external.url[scheme]"),
+		Entry("external Service for several sentinels", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Mode = "external"
+			cs.Spec.External = &productionstackv1alpha1.CacheServerExternalSpec{URL: "redis-sentinel://a:26379,b:26379", Service: true}
+		}, "spec.external.service"),
+		Entry("lmcache server features in external mode", func(cs *productionstackv1alpha1.CacheServer) {
+			cs.Spec.Mode = "external"
+			cs.Spec.External = &productionstackv1alpha1.CacheServerExternalSpec{URL: "redis://valkey:6379"}
+			cs.Spec.Storage.Enabled = true
+			cs.Spec.Autoscaling.Enabled = true
+			cs.Spec.NetworkPolicy.Enabled = true
+		}, "spec.storage", "spec.autoscaling", "spec.networkPolicy"),
+	)
+
+	Context("When updating a CacheServer", func() {
+		It("should allow metadata changes of an existing invalid object", func() {
+			oldObj := newCacheServer()
+			oldObj.Spec.Device = "cuda"
+			newObj := oldObj.DeepCopy()
+			newObj.Annotations = map[string]string{"example.com/owner": "team-a"}
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,227 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var vllmrouterlog = logf.Log.WithName("vllmrouter-resource")
+
+// routerReservedVolumeNames are the volumes the operator creates for
+// dynamicConfig, a read-only root filesystem, tls and the batch API files
+var routerReservedVolumeNames = []string{"dynamic-config", "tmp", "tls", "files"}
+
+// SetupVLLMRouterWebhookWithManager registers the webhook for VLLMRouter in the manager.
+func SetupVLLMRouterWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&productionstackv1alpha1.VLLMRouter{}).
+		WithValidator(&VLLMRouterCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-production-stack-vllm-ai-v1alpha1-vllmrouter,mutating=false,failurePolicy=fail,sideEffects=None,groups=production-stack.vllm.ai,resources=vllmrouters,verbs=create;update,versions=v1alpha1,name=vvllmrouter-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// VLLMRouterCustomValidator validates the cross-field constraints of a
+// VLLMRouter that the CRD schema cannot express.
+type VLLMRouterCustomValidator struct{}
+
+var _ webhook.CustomValidator = &VLLMRouterCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type VLLMRouter.
+func (v *VLLMRouterCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	vllmrouter, ok := obj.(*productionstackv1alpha1.VLLMRouter)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRouter object but got %T", obj)
+	}
+	vllmrouterlog.Info("Validation for VLLMRouter upon creation", "name", vllmrouter.GetName())
+
+	return nil, validateVLLMRouter(vllmrouter)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type VLLMRouter.
+// Updates leaving the spec unchanged, e.g. annotations or finalizers, are always allowed.
+func (v *VLLMRouterCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldVLLMRouter, ok := oldObj.(*productionstackv1alpha1.VLLMRouter)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRouter object for the oldObj but got %T", oldObj)
+	}
+	vllmrouter, ok := newObj.(*productionstackv1alpha1.VLLMRouter)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRouter object for the newObj but got %T", newObj)
+	}
+	vllmrouterlog.Info("Validation for VLLMRouter upon update", "name", vllmrouter.GetName())
+
+	if equality.Semantic.DeepEqual(oldVLLMRouter.Spec, vllmrouter.Spec) {
+		return nil, nil
+	}
+	return nil, validateVLLMRouter(vllmrouter)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type VLLMRouter.
+func (v *VLLMRouterCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateVLLMRouter returns an Invalid error listing every bad field of the VLLMRouter
+func validateVLLMRouter(router *productionstackv1alpha1.VLLMRouter) error {
+	specPath := field.NewPath("spec")
+	var allErrs field.ErrorList
+
+	// The backends of a runtimeSelector are generated by the operator
+	if router.Spec.ServiceDiscovery == "static" && router.Spec.RuntimeSelector == nil {
+		if router.Spec.StaticBackends == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("staticBackends"), "required when serviceDiscovery is static"))
+		}
+		if router.Spec.StaticModels == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("staticModels"), "required when serviceDiscovery is static"))
+		}
+		if router.Spec.StaticBackends != "" {
+			for _, backend := range strings.Split(router.Spec.StaticBackends, ",") {
+				if u, err := url.Parse(strings.TrimSpace(backend)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					allErrs = append(allErrs, field.Invalid(specPath.Child("staticBackends"), backend, "must be an http or https URL, e.g. http://runtime:8000"))
+				}
+			}
+		}
+		if router.Spec.StaticBackends != "" && router.Spec.StaticModels != "" {
+			backends := strings.Split(router.Spec.StaticBackends, ",")
+			models := strings.Split(router.Spec.StaticModels, ",")
+			if len(backends) != len(models) {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("staticModels"), router.Spec.StaticModels,
+					fmt.Sprintf("must list one model for each of the %d staticBackends", len(backends))))
+			}
+		}
+	}
+
+	// The router watches every pod of the namespace without a selector
+	if router.Spec.ServiceDiscovery == "k8s" {
+		selectorPath := specPath.Child("k8sLabelSelector")
+		if router.Spec.K8sLabelSelector == "" {
+			allErrs = append(allErrs, field.Required(selectorPath, "required when serviceDiscovery is k8s"))
+		} else if _, err := labels.Parse(router.Spec.K8sLabelSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorPath, router.Spec.K8sLabelSelector, err.Error()))
+		}
+	}
+
+	if router.Spec.RoutingLogic == "session" && router.Spec.SessionKey == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("sessionKey"), "required when routingLogic is session"))
+	}
+
+	// A rollout needs to either surge or take down a pod
+	if ru := router.Spec.RollingUpdate; ru != nil {
+		rollingUpdatePath := specPath.Child("rollingUpdate")
+		// Unset values default to 25%
+		maxSurge, maxUnavailable := 1, 1
+		if ru.MaxSurge != nil {
+			var err error
+			if maxSurge, err = intstr.GetScaledValueFromIntOrPercent(ru.MaxSurge, 100, true); err != nil || maxSurge < 0 {
+				allErrs = append(allErrs, field.Invalid(rollingUpdatePath.Child("maxSurge"), ru.MaxSurge.String(), "must be a non-negative integer or percentage"))
+				maxSurge = 1
+			}
+		}
+		if ru.MaxUnavailable != nil {
+			var err error
+			if maxUnavailable, err = intstr.GetScaledValueFromIntOrPercent(ru.MaxUnavailable, 100, false); err != nil || maxUnavailable < 0 {
+				allErrs = append(allErrs, field.Invalid(rollingUpdatePath.Child("maxUnavailable"), ru.MaxUnavailable.String(), "must be a non-negative integer or percentage"))
+				maxUnavailable = 1
+			}
+		}
+		if maxSurge == 0 && maxUnavailable == 0 {
+			allErrs = append(allErrs, field.Invalid(rollingUpdatePath.Child("maxUnavailable"), ru.MaxUnavailable.String(), "may not be 0 when maxSurge is 0"))
+		}
+	}
+
+	// Discovering pods outside of the router namespace needs a ClusterRole
+	if routerRBACManaged(router) && !router.Spec.RBAC.ClusterWide {
+		for i, namespace := range router.Spec.WatchNamespaces {
+			if namespace != router.Namespace {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("watchNamespaces").Index(i), namespace,
+					"requires rbac.clusterWide, the router may only discover pods in its namespace"))
+				break
+			}
+		}
+	}
+
+	// Volume mounts must reference a declared volume
+	declared := make(map[string]bool, len(router.Spec.Volumes))
+	for i, v := range router.Spec.Volumes {
+		if slices.Contains(routerReservedVolumeNames, v.Name) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("volumes").Index(i).Child("name"), v.Name, "is reserved for the operator"))
+		}
+		declared[v.Name] = true
+	}
+	for i, m := range router.Spec.VolumeMounts {
+		if !declared[m.Name] {
+			allErrs = append(allErrs, field.NotFound(specPath.Child("volumeMounts").Index(i).Child("name"), m.Name))
+		}
+	}
+
+	// Sidecars share the pod with the router container and may front it
+	containerNames := map[string]bool{"router": true}
+	sidecarPorts := map[string]bool{}
+	for i, c := range router.Spec.Sidecars {
+		sidecarPath := specPath.Child("sidecars").Index(i)
+		if containerNames[c.Name] {
+			allErrs = append(allErrs, field.Duplicate(sidecarPath.Child("name"), c.Name))
+		}
+		containerNames[c.Name] = true
+		for j, m := range c.VolumeMounts {
+			if !declared[m.Name] {
+				allErrs = append(allErrs, field.NotFound(sidecarPath.Child("volumeMounts").Index(j).Child("name"), m.Name))
+			}
+		}
+		for _, p := range c.Ports {
+			if p.Name != "" {
+				sidecarPorts[p.Name] = true
+			}
+		}
+	}
+	if name := router.Spec.Service.TargetPortName; name != "" && !sidecarPorts[name] {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("service", "targetPortName"), name, "must name a port of a sidecar"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(productionstackv1alpha1.GroupVersion.WithKind("VLLMRouter").GroupKind(), router.Name, allErrs)
+}
+
+// routerRBACManaged reports whether the operator creates the RBAC resources
+// of the router
+func routerRBACManaged(router *productionstackv1alpha1.VLLMRouter) bool {
+	if router.Spec.RBAC.Create != nil {
+		return *router.Spec.RBAC.Create
+	}
+	return router.Spec.ServiceDiscovery == "k8s" && router.Spec.ServiceAccountName == ""
+}
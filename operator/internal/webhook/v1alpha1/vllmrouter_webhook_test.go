@@ -0,0 +1,187 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+var _ = Describe("VLLMRouter Webhook", func() {
+	var validator VLLMRouterCustomValidator
+
+	newVLLMRouter := func() *productionstackv1alpha1.VLLMRouter {
+		return &productionstackv1alpha1.VLLMRouter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-router",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRouterSpec{
+				Port:             8000,
+				ServiceDiscovery: "static",
+				StaticBackends:   "http://runtime-a:8000,http://runtime-b:8000",
+				StaticModels:     "model-a,model-b",
+			},
+		}
+	}
+
+	DescribeTable("When creating a VLLMRouter",
+		func(mutate func(*productionstackv1alpha1.VLLMRouter), expectedFields ...string) {
+			vllmrouter := newVLLMRouter()
+			mutate(vllmrouter)
+
+			_, err := validator.ValidateCreate(ctx, vllmrouter)
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+		Entry("valid static spec", func(*productionstackv1alpha1.VLLMRouter) {}),
+		Entry("static without staticBackends", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = ""
+		}, "spec.staticBackends"),
+		Entry("static without backends and models", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = ""
+			router.Spec.StaticModels = ""
+		}, "spec.staticBackends", "spec.staticModels"),
+		Entry("static with fewer models than backends", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticModels = "model-a"
+		}, "spec.staticModels"),
+		Entry("static with a backend that is not a URL", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = "http://runtime-a:8000,runtime-b:8000"
+		}, "spec.staticBackends"),
+		Entry("static with a backend without a host", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = "http://runtime-a:8000,https://"
+		}, "spec.staticBackends"),
+		Entry("static with an https backend", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = "http://runtime-a:8000, https://runtime-b.example.com"
+		}),
+		Entry("static with a runtimeSelector", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.StaticBackends = ""
+			router.Spec.StaticModels = ""
+			router.Spec.RuntimeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "vllm"}}
+		}),
+		Entry("volume mounts of declared volumes", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Volumes = []corev1.Volume{{Name: "ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}}}}
+			router.Spec.VolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/backends"}}
+		}),
+		Entry("volume mount of an undeclared volume", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.VolumeMounts = []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/backends"}}
+		}, "spec.volumeMounts[0].name"),
+		Entry("volume with the reserved name", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Volumes = []corev1.Volume{{Name: "dynamic-config", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		}, "spec.volumes[0].name"),
+		Entry("rolling update with zero surge", func(router *productionstackv1alpha1.VLLMRouter) {
+			maxSurge := intstr.FromInt(0)
+			router.Spec.RollingUpdate = &productionstackv1alpha1.RollingUpdateSpec{MaxSurge: &maxSurge}
+		}),
+		Entry("rolling update with zero surge and unavailability", func(router *productionstackv1alpha1.VLLMRouter) {
+			maxSurge := intstr.FromInt(0)
+			maxUnavailable := intstr.FromString("0%")
+			router.Spec.RollingUpdate = &productionstackv1alpha1.RollingUpdateSpec{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable}
+		}, "spec.rollingUpdate.maxUnavailable"),
+		Entry("rolling update with a malformed maxSurge", func(router *productionstackv1alpha1.VLLMRouter) {
+			maxSurge := intstr.FromString("one")
+			router.Spec.RollingUpdate = &productionstackv1alpha1.RollingUpdateSpec{MaxSurge: &maxSurge}
+		}, "spec.rollingUpdate.maxSurge"),
+		Entry("service targeting a sidecar port", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Sidecars = []corev1.Container{{Name: "oauth2-proxy", Ports: []corev1.ContainerPort{{Name: "proxy", ContainerPort: 4180}}}}
+			router.Spec.Service.TargetPortName = "proxy"
+		}),
+		Entry("service targeting an undeclared port", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Sidecars = []corev1.Container{{Name: "oauth2-proxy"}}
+			router.Spec.Service.TargetPortName = "proxy"
+		}, "spec.service.targetPortName"),
+		Entry("sidecar named after the router container", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Sidecars = []corev1.Container{{Name: "router"}}
+		}, "spec.sidecars[0].name"),
+		Entry("sidecar mounting an undeclared volume", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.Sidecars = []corev1.Container{{Name: "oauth2-proxy", VolumeMounts: []corev1.VolumeMount{{Name: "proxy-config", MountPath: "/etc/oauth2-proxy"}}}}
+		}, "spec.sidecars[0].volumeMounts[0].name"),
+		Entry("k8s discovery", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.StaticBackends = ""
+			router.Spec.StaticModels = ""
+			router.Spec.K8sLabelSelector = "app=vllm"
+		}),
+		Entry("k8s discovery without a label selector", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+		}, "spec.k8sLabelSelector"),
+		Entry("k8s discovery with a malformed label selector", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.K8sLabelSelector = "app in (vllm"
+		}, "spec.k8sLabelSelector"),
+		Entry("session routing without a session key", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "session"
+		}, "spec.sessionKey"),
+		Entry("session routing", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.RoutingLogic = "session"
+			router.Spec.SessionKey = "x-user-id"
+		}),
+		Entry("k8s discovery of other namespaces", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.K8sLabelSelector = "app=vllm"
+			router.Spec.WatchNamespaces = []string{"team-a", "team-b"}
+		}, "spec.watchNamespaces[0]"),
+		Entry("k8s discovery of other namespaces with cluster-wide RBAC", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.K8sLabelSelector = "app=vllm"
+			router.Spec.WatchNamespaces = []string{"*"}
+			router.Spec.RBAC.ClusterWide = true
+		}),
+		Entry("k8s discovery of other namespaces with a provided ServiceAccount", func(router *productionstackv1alpha1.VLLMRouter) {
+			router.Spec.ServiceDiscovery = "k8s"
+			router.Spec.K8sLabelSelector = "app=vllm"
+			router.Spec.WatchNamespaces = []string{"team-a"}
+			router.Spec.ServiceAccountName = "shared-router"
+		}),
+	)
+
+	Context("When updating a VLLMRouter", func() {
+		It("should validate spec changes", func() {
+			oldObj := newVLLMRouter()
+			newObj := oldObj.DeepCopy()
+			newObj.Spec.StaticModels = ""
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.staticModels"))
+		})
+
+		It("should allow metadata changes of an existing invalid object", func() {
+			oldObj := newVLLMRouter()
+			oldObj.Spec.StaticModels = ""
+			newObj := oldObj.DeepCopy()
+			newObj.Annotations = map[string]string{"team": "inference"}
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var vllmruntimelog = logf.Log.WithName("vllmruntime-resource")
+
+// lmCacheRemoteSchemes are the remote URL schemes supported by LMCache
+var lmCacheRemoteSchemes = []string{"lm", "redis", "rediss", "redis-sentinel", "infinistore", "mooncakestore"}
+
+// SetupVLLMRuntimeWebhookWithManager registers the webhook for VLLMRuntime in the manager.
+func SetupVLLMRuntimeWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&productionstackv1alpha1.VLLMRuntime{}).
+		WithValidator(&VLLMRuntimeCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-production-stack-vllm-ai-v1alpha1-vllmruntime,mutating=false,failurePolicy=fail,sideEffects=None,groups=production-stack.vllm.ai,resources=vllmruntimes,verbs=create;update,versions=v1alpha1,name=vvllmruntime-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// VLLMRuntimeCustomValidator validates the cross-field constraints of a
+// VLLMRuntime that the CRD schema cannot express.
+type VLLMRuntimeCustomValidator struct{}
+
+var _ webhook.CustomValidator = &VLLMRuntimeCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type VLLMRuntime.
+func (v *VLLMRuntimeCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	vllmruntime, ok := obj.(*productionstackv1alpha1.VLLMRuntime)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRuntime object but got %T", obj)
+	}
+	vllmruntimelog.Info("Validation for VLLMRuntime upon creation", "name", vllmruntime.GetName())
+
+	return nil, validateVLLMRuntime(vllmruntime)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type VLLMRuntime.
+// Updates leaving the spec unchanged, e.g. annotations or finalizers, are always allowed.
+func (v *VLLMRuntimeCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldVLLMRuntime, ok := oldObj.(*productionstackv1alpha1.VLLMRuntime)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRuntime object for the oldObj but got %T", oldObj)
+	}
+	vllmruntime, ok := newObj.(*productionstackv1alpha1.VLLMRuntime)
+	if !ok {
+		return nil, fmt.Errorf("expected a VLLMRuntime object for the newObj but got %T", newObj)
+	}
+	vllmruntimelog.Info("Validation for VLLMRuntime upon update", "name", vllmruntime.GetName())
+
+	if equality.Semantic.DeepEqual(oldVLLMRuntime.Spec, vllmruntime.Spec) {
+		return nil, nil
+	}
+	return nil, validateVLLMRuntime(vllmruntime)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type VLLMRuntime.
+func (v *VLLMRuntimeCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateVLLMRuntime returns an Invalid error listing every bad field of the VLLMRuntime
+func validateVLLMRuntime(vr *productionstackv1alpha1.VLLMRuntime) error {
+	specPath := field.NewPath("spec")
+	var allErrs field.ErrorList
+
+	if vr.Spec.Port < 1 || vr.Spec.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("port"), vr.Spec.Port, "must be between 1 and 65535"))
+	}
+
+	allErrs = append(allErrs, validateModel(vr.Spec.Model, specPath.Child("model"))...)
+	allErrs = append(allErrs, validateResources(vr, specPath)...)
+
+	if value := vr.Spec.GpuMemoryUtilization; value != "" {
+		utilization, err := strconv.ParseFloat(value, 64)
+		if err != nil || utilization <= 0 || utilization > 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("gpuMemoryUtilization"), value, "must be a number in (0, 1]"))
+		}
+	}
+
+	if remoteURL := vr.Spec.LMCacheConfig.RemoteURL; remoteURL != "" {
+		fldPath := specPath.Child("lmCacheConfig", "remoteUrl")
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, remoteURL, "must be a URL of the form <scheme>://<host>:<port>"))
+		} else if !slices.Contains(lmCacheRemoteSchemes, u.Scheme) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key("scheme"), u.Scheme, lmCacheRemoteSchemes))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(productionstackv1alpha1.GroupVersion.WithKind("VLLMRuntime").GroupKind(), vr.Name, allErrs)
+}
+
+// validateModel checks that exactly the fields of the selected model source are set
+func validateModel(model productionstackv1alpha1.ModelSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch model.Source {
+	case "", "huggingface":
+		if model.ModelURL == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("modelURL"), "required when source is huggingface"))
+		}
+		if model.PVCName != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("pvcName"), "not allowed when source is huggingface"))
+		}
+		if model.Path != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("path"), "not allowed when source is huggingface"))
+		}
+	case "pvc":
+		if model.PVCName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pvcName"), "required when source is pvc"))
+		}
+		if model.ModelURL != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("modelURL"), "not allowed when source is pvc"))
+		}
+	case "hostPath":
+		if model.Path == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("path"), "required when source is hostPath"))
+		}
+		if model.ModelURL != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("modelURL"), "not allowed when source is hostPath"))
+		}
+		if model.PVCName != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("pvcName"), "not allowed when source is hostPath"))
+		}
+	}
+	return allErrs
+}
+
+// validateResources checks the resource quantities and that enough GPUs are
+// requested for the tensor parallel size
+func validateResources(vr *productionstackv1alpha1.VLLMRuntime, specPath *field.Path) field.ErrorList {
+	gpus, allErrs := validateResourceQuantities(vr.Spec.Resources, specPath.Child("resources"))
+	if gpus < 0 {
+		return allErrs
+	}
+	if tp := vr.Spec.TensorParallelSize; tp > 1 && gpus < int64(tp) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("tensorParallelSize"), tp,
+			fmt.Sprintf("must not exceed the number of GPUs in spec.resources.gpu (%d)", gpus)))
+	}
+	return allErrs
+}
+
+// validateResourceQuantities checks that the resource quantities parse, which
+// the controllers rely on. It returns the number of GPUs requested, or -1 if
+// the GPU quantity is invalid.
+func validateResourceQuantities(resources productionstackv1alpha1.ResourceRequirements, resourcesPath *field.Path) (int64, field.ErrorList) {
+	var allErrs field.ErrorList
+	if value := resources.CPU; value != "" {
+		if _, err := resource.ParseQuantity(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(resourcesPath.Child("cpu"), value, err.Error()))
+		}
+	}
+	if value := resources.Memory; value != "" {
+		if _, err := resource.ParseQuantity(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(resourcesPath.Child("memory"), value, err.Error()))
+		}
+	}
+
+	var gpus int64
+	if value := resources.GPU; value != "" {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return -1, append(allErrs, field.Invalid(resourcesPath.Child("gpu"), value, err.Error()))
+		}
+		gpus = quantity.Value()
+	}
+	return gpus, allErrs
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	productionstackv1alpha1 "production-stack/api/v1alpha1"
+)
+
+var _ = Describe("VLLMRuntime Webhook", func() {
+	var validator VLLMRuntimeCustomValidator
+
+	newVLLMRuntime := func() *productionstackv1alpha1.VLLMRuntime {
+		return &productionstackv1alpha1.VLLMRuntime{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-runtime",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.VLLMRuntimeSpec{
+				Model:                productionstackv1alpha1.ModelSpec{ModelURL: "meta-llama/Llama-3.1-8B-Instruct"},
+				Port:                 8000,
+				TensorParallelSize:   2,
+				GpuMemoryUtilization: "0.9",
+				Resources:            productionstackv1alpha1.ResourceRequirements{CPU: "8", Memory: "32Gi", GPU: "2"},
+			},
+		}
+	}
+
+	DescribeTable("When creating a VLLMRuntime",
+		func(mutate func(*productionstackv1alpha1.VLLMRuntime), expectedFields ...string) {
+			vllmruntime := newVLLMRuntime()
+			mutate(vllmruntime)
+
+			_, err := validator.ValidateCreate(ctx, vllmruntime)
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+		Entry("valid spec", func(*productionstackv1alpha1.VLLMRuntime) {}),
+		Entry("port 0", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Port = 0
+		}, "spec.port"),
+		Entry("port above 65535", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Port = 70000
+		}, "spec.port"),
+		Entry("tensorParallelSize above the GPU count", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.TensorParallelSize = 4
+		}, "spec.tensorParallelSize"),
+		Entry("tensorParallelSize without GPUs", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Resources.GPU = ""
+		}, "spec.tensorParallelSize"),
+		Entry("unparsable GPU quantity", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Resources.GPU = "two"
+		}, "spec.resources.gpu"),
+		Entry("unparsable memory quantity", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Resources.Memory = "32GB"
+		}, "spec.resources.memory"),
+		Entry("gpuMemoryUtilization of 1", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.GpuMemoryUtilization = "1"
+		}),
+		Entry("gpuMemoryUtilization of 0", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.GpuMemoryUtilization = "0"
+		}, "spec.gpuMemoryUtilization"),
+		Entry("gpuMemoryUtilization above 1", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.GpuMemoryUtilization = "1.5"
+		}, "spec.gpuMemoryUtilization"),
+		Entry("gpuMemoryUtilization not a number", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.GpuMemoryUtilization = "90%"
+		}, "spec.gpuMemoryUtilization"),
+		Entry("empty modelURL", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Model.ModelURL = ""
+		}, "spec.model.modelURL"),
+		Entry("pvc source with modelURL", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Model.Source = "pvc"
+		}, "spec.model.pvcName", "spec.model.modelURL"),
+		Entry("hostPath source", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Model = productionstackv1alpha1.ModelSpec{Source: "hostPath", Path: "/mnt/models/llama"}
+		}),
+		Entry("huggingface source with path", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Model.Path = "/models"
+		}, "spec.model.path"),
+		Entry("LMCache remoteUrl with a supported scheme", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.LMCacheConfig.RemoteURL = "redis://cache.default.svc:6379"
+		}),
+		Entry("LMCache remoteUrl with an unsupported scheme", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.LMCacheConfig.RemoteURL = "http://cache.default.svc:8080"
+		}, "spec.lmCacheConfig.remoteUrl[scheme]"),
+		Entry("LMCache remoteUrl without a host", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.LMCacheConfig.RemoteURL = "cache:8080"
+		}, "spec.lmCacheConfig.remoteUrl"),
+		Entry("several invalid fields", func(vr *productionstackv1alpha1.VLLMRuntime) {
+			vr.Spec.Port = 0
+			vr.Spec.GpuMemoryUtilization = "2"
+		}, "spec.port", "spec.gpuMemoryUtilization"),
+	)
+
+	Context("When updating a VLLMRuntime", func() {
+		It("should validate spec changes", func() {
+			oldObj := newVLLMRuntime()
+			newObj := oldObj.DeepCopy()
+			newObj.Spec.TensorParallelSize = 8
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.tensorParallelSize"))
+		})
+
+		It("should allow metadata changes of an existing invalid object", func() {
+			oldObj := newVLLMRuntime()
+			oldObj.Spec.TensorParallelSize = 8
+			newObj := oldObj.DeepCopy()
+			newObj.Annotations = map[string]string{productionstackv1alpha1.SleepAnnotation: "true"}
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -24,6 +24,12 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // StaticRouteSpec defines the desired state of StaticRoute
+// +kubebuilder:validation:XValidation:rule="!(has(self.backends) && (has(self.staticBackends) || has(self.staticModels)))",message="backends and staticBackends/staticModels are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.routerRef) && has(self.routerSelector))",message="routerRef and routerSelector are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.routerNamespaces) || has(self.routerSelector)",message="routerNamespaces requires routerSelector"
+// +kubebuilder:validation:XValidation:rule="self.routingLogic != 'session' || (has(self.sessionKey) && size(self.sessionKey) > 0)",message="sessionKey is required with session routing"
+// +kubebuilder:validation:XValidation:rule="!has(self.aggregate) || !self.aggregate || has(self.routerRef) || has(self.routerSelector)",message="aggregate requires routerRef or routerSelector"
+// +kubebuilder:validation:XValidation:rule="!has(self.aggregate) || !self.aggregate || !has(self.configMapName)",message="configMapName may not be set with aggregate"
 type StaticRouteSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -33,30 +39,126 @@ type StaticRouteSpec struct {
 	// +kubebuilder:default=static
 	ServiceDiscovery string `json:"serviceDiscovery"`
 
-	// RoutingLogic specifies the routing logic to use
-	// +kubebuilder:validation:Enum=roundrobin
+	// RoutingLogic specifies the routing logic to use. The kvaware and
+	// disaggregated_prefill logic of the router need flags the dynamic config
+	// cannot carry.
+	// +kubebuilder:validation:Enum=roundrobin;session;prefixaware
 	// +kubebuilder:default=roundrobin
 	RoutingLogic string `json:"routingLogic"`
 
+	// SessionKey is the request header identifying the session of a request,
+	// required with session routing
+	// +optional
+	SessionKey string `json:"sessionKey,omitempty"`
+
+	// Backends are the backends of the router and the model each serves. They
+	// are preferred to the comma-separated staticBackends and staticModels.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	Backends []StaticBackend `json:"backends,omitempty"`
+
 	// StaticBackends is a comma-separated list of backend URLs
-	// +kubebuilder:validation:Required
-	StaticBackends string `json:"staticBackends"`
+	// +optional
+	StaticBackends string `json:"staticBackends,omitempty"`
 
-	// StaticModels is a comma-separated list of model names
-	// +kubebuilder:validation:Required
-	StaticModels string `json:"staticModels"`
+	// StaticModels is a comma-separated list of model names, one for each of the staticBackends
+	// +optional
+	StaticModels string `json:"staticModels,omitempty"`
 
-	// RouterRef is a reference to the router service
+	// RouterRef is a reference to the router service. Without RouterRef and
+	// RouterSelector the dynamic config is only written to the ConfigMap.
 	// +optional
 	RouterRef *corev1.ObjectReference `json:"routerRef,omitempty"`
 
-	// HealthCheck defines the health check configuration for the router
+	// RouterSelector selects the router services by label, e.g. the Services of
+	// an HA pair of routers, as an alternative to RouterRef
+	// +optional
+	RouterSelector *metav1.LabelSelector `json:"routerSelector,omitempty"`
+
+	// RouterNamespaces are the namespaces of the services selected by
+	// RouterSelector, the namespace of the StaticRoute when empty
+	// +optional
+	RouterNamespaces []string `json:"routerNamespaces,omitempty"`
+
+	// HealthCheck configures the controller's health checks of the static
+	// backends and the routers. It is rendered into the dynamic config, the
+	// router stops routing to the backends failing it.
 	// +optional
 	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
 
 	// ConfigMapName is the name of the ConfigMap to create with the dynamic config
 	// +optional
 	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Aggregate merges the backends with those of the other aggregated
+	// StaticRoutes of each router, into the ConfigMap <router>-dynamic-config
+	// in the namespace of the router. The oldest StaticRoute sets the routing
+	// logic of the router.
+	// +optional
+	Aggregate bool `json:"aggregate,omitempty"`
+
+	// ConfigPush defines how the dynamic config is pushed to the router API
+	// +optional
+	ConfigPush *ConfigPushConfig `json:"configPush,omitempty"`
+}
+
+// StaticBackend is a backend of the router serving a model
+type StaticBackend struct {
+	// URL of the backend, e.g. http://vllm-runtime:8000
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Model served by the backend
+	// +kubebuilder:validation:MinLength=1
+	Model string `json:"model"`
+
+	// Weight of the backend, the backends of a model share its traffic in
+	// proportion to their weights and a backend of weight 0 is drained. The
+	// session routing logic spreads the sessions evenly.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Weight *int32 `json:"weight,omitempty"`
+
+	// Priority of the backend, lower values are preferred. It is currently
+	// ignored: it is not rendered into the dynamic config and the router
+	// does not support priorities.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// EffectiveWeight returns the weight of the backend, 1 when unset
+func (b *StaticBackend) EffectiveWeight() int32 {
+	if b.Weight == nil {
+		return 1
+	}
+	return *b.Weight
+}
+
+// ConfigPushConfig defines the delivery of the dynamic config to the router API
+type ConfigPushConfig struct {
+	// Disabled only writes the ConfigMap, for routers watching its dynamic_config.json file
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Path of the router endpoint the dynamic config is POSTed to
+	// +optional
+	// +kubebuilder:default="/dynamic_config"
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+
+	// Number of seconds after which the push times out
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// TokenSecretRef selects the key of a Secret in the namespace of the
+	// StaticRoute holding the bearer token of the push. The router rejects
+	// pushes without the token of its VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN.
+	// +optional
+	TokenSecretRef *corev1.SecretKeySelector `json:"tokenSecretRef,omitempty"`
 }
 
 // HealthCheckConfig defines the configuration for health checks
@@ -97,17 +199,126 @@ type StaticRouteStatus struct {
 	// +patchStrategy=merge
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 
+	// ObservedGeneration is the generation of the spec the status was computed from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// ConfigMapRef is a reference to the created ConfigMap
 	// +optional
 	ConfigMapRef string `json:"configMapRef,omitempty"`
 
-	// LastAppliedTime is the last time the configuration was applied to the router
+	// LastAppliedTime is the last time the configuration was applied to every router,
+	// or written to the ConfigMap when the push is disabled
 	// +optional
 	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// AppliedConfigHash is the SHA-256 of the dynamic config last applied to every router
+	// +optional
+	AppliedConfigHash string `json:"appliedConfigHash,omitempty"`
+
+	// LastPushTime is the last time the dynamic config was pushed to the routers
+	// +optional
+	LastPushTime *metav1.Time `json:"lastPushTime,omitempty"`
+
+	// LastPushResult is the outcome of the last push to the routers
+	// +optional
+	LastPushResult string `json:"lastPushResult,omitempty"`
+
+	// Backends is the health of each of the static backends
+	// +optional
+	// +listType=map
+	// +listMapKey=url
+	Backends []BackendStatus `json:"backends,omitempty"`
+
+	// Routers are the config push and health check results of each router
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	Routers []RouterStatus `json:"routers,omitempty"`
+
+	// WithdrawAttempts counts the failed attempts to withdraw the backends from
+	// the routers on deletion
+	// +optional
+	WithdrawAttempts int32 `json:"withdrawAttempts,omitempty"`
+}
+
+// RouterStatus is the state of the dynamic config of a router service
+type RouterStatus struct {
+	// Namespace of the router service
+	Namespace string `json:"namespace"`
+
+	// Name of the router service
+	Name string `json:"name"`
+
+	// AppliedConfigHash is the SHA-256 of the dynamic config last pushed to the router
+	// +optional
+	AppliedConfigHash string `json:"appliedConfigHash,omitempty"`
+
+	// ConfigMapRef is the name of the ConfigMap holding the merged dynamic
+	// config of an aggregated StaticRoute, in the namespace of the router
+	// +optional
+	ConfigMapRef string `json:"configMapRef,omitempty"`
+
+	// LastPushTime is the last time the dynamic config was pushed to the router
+	// +optional
+	LastPushTime *metav1.Time `json:"lastPushTime,omitempty"`
+
+	// LastPushResult is the outcome of the last push to the router
+	// +optional
+	LastPushResult string `json:"lastPushResult,omitempty"`
+
+	// Health counts the consecutive results of the router health checks
+	// +optional
+	Health *RouterHealthStatus `json:"health,omitempty"`
+}
+
+// RouterHealthStatus is the result of the health checks of a router, one
+// probe is taken each reconcile
+type RouterHealthStatus struct {
+	// State is Healthy once successThreshold consecutive probes succeeded and
+	// Unhealthy once failureThreshold consecutive probes failed, empty before
+	// +optional
+	// +kubebuilder:validation:Enum=Healthy;Unhealthy
+	State string `json:"state,omitempty"`
+
+	// ConsecutiveSuccesses is the number of consecutive successful probes
+	// +optional
+	ConsecutiveSuccesses int32 `json:"consecutiveSuccesses,omitempty"`
+
+	// ConsecutiveFailures is the number of consecutive failed probes
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastProbeTime is the last time the router was probed
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+
+	// Message describes why the last probe failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BackendStatus is the result of the last health probe of a static backend
+type BackendStatus struct {
+	// URL of the backend
+	URL string `json:"url"`
+
+	// Healthy is true if the backend answered the probe successfully
+	Healthy bool `json:"healthy"`
+
+	// LastProbeTime is the last time the backend was probed
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+
+	// Message describes why the backend is unhealthy
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // StaticRoute is the Schema for the staticroutes API
 type StaticRoute struct {
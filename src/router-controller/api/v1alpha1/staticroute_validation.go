@@ -0,0 +1,166 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateStaticRouteSpec checks that the backends are absolute http(s) URLs
+// with a non-empty model each. Backends and the legacy StaticBackends and
+// StaticModels are mutually exclusive, the latter must be parallel lists, as
+// the router pairs the i-th backend with the i-th model. The routers are
+// referenced by either RouterRef or RouterSelector, which an aggregated
+// StaticRoute needs, and session routing needs a SessionKey.
+func ValidateStaticRouteSpec(spec *StaticRouteSpec, specPath *field.Path) field.ErrorList {
+	allErrs := validateRouters(spec, specPath)
+	if spec.RoutingLogic == "session" && strings.TrimSpace(spec.SessionKey) == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("sessionKey"), "required when routingLogic is session"))
+	}
+	if len(spec.Backends) > 0 {
+		return append(allErrs, validateBackends(spec, specPath)...)
+	}
+
+	backends := strings.Split(spec.StaticBackends, ",")
+	for i, backend := range backends {
+		backend = strings.TrimSpace(backend)
+		if !isBackendURL(backend) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("staticBackends").Index(i), backend, backendURLMessage))
+		}
+	}
+
+	models := strings.Split(spec.StaticModels, ",")
+	for i, model := range models {
+		if strings.TrimSpace(model) == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("staticModels").Index(i), "model names must not be empty"))
+		}
+	}
+
+	if len(backends) != len(models) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("staticModels"), spec.StaticModels,
+			fmt.Sprintf("must list one model for each of the %d staticBackends", len(backends))))
+	}
+	return allErrs
+}
+
+// backendURLMessage describes a valid backend URL
+const backendURLMessage = "must be an absolute http or https URL, e.g. http://vllm-runtime:8000"
+
+// validateBackends checks the structured backends, which are joined into the
+// comma-separated lists of the router. A URL is listed once, the status
+// reports the health of each backend by URL, weights are not negative, and
+// each model keeps a backend of a positive weight.
+func validateBackends(spec *StaticRouteSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.StaticBackends != "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("staticBackends"), "may not be set with backends"))
+	}
+	if spec.StaticModels != "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("staticModels"), "may not be set with backends"))
+	}
+
+	seen := make(map[string]bool, len(spec.Backends))
+	// The index of the first backend of each model, and whether it is served
+	var models []string
+	firstIndex := map[string]int{}
+	served := map[string]bool{}
+	for i, backend := range spec.Backends {
+		backendPath := specPath.Child("backends").Index(i)
+		if !isBackendURL(backend.URL) || strings.Contains(backend.URL, ",") {
+			allErrs = append(allErrs, field.Invalid(backendPath.Child("url"), backend.URL, backendURLMessage))
+		} else if seen[backend.URL] {
+			allErrs = append(allErrs, field.Duplicate(backendPath.Child("url"), backend.URL))
+		}
+		seen[backend.URL] = true
+		if strings.TrimSpace(backend.Model) == "" {
+			allErrs = append(allErrs, field.Required(backendPath.Child("model"), "model names must not be empty"))
+		} else if strings.Contains(backend.Model, ",") {
+			allErrs = append(allErrs, field.Invalid(backendPath.Child("model"), backend.Model, "must not contain a comma"))
+		}
+
+		weight := backend.EffectiveWeight()
+		if weight < 0 {
+			allErrs = append(allErrs, field.Invalid(backendPath.Child("weight"), weight, "must not be negative"))
+		}
+		if _, ok := firstIndex[backend.Model]; !ok {
+			firstIndex[backend.Model] = i
+			models = append(models, backend.Model)
+		}
+		served[backend.Model] = served[backend.Model] || weight > 0
+	}
+
+	for _, model := range models {
+		if !served[model] {
+			weightPath := specPath.Child("backends").Index(firstIndex[model]).Child("weight")
+			allErrs = append(allErrs, field.Invalid(weightPath, spec.Backends[firstIndex[model]].EffectiveWeight(),
+				fmt.Sprintf("model %s needs a backend of a positive weight", model)))
+		}
+	}
+	return allErrs
+}
+
+// validateRouters checks that RouterRef and RouterSelector are mutually
+// exclusive, that the selector does not match every service and that an
+// aggregated StaticRoute has routers. Neither is required otherwise, as a
+// StaticRoute without routers only writes the ConfigMap.
+func validateRouters(spec *StaticRouteSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Aggregated StaticRoutes share the ConfigMap named after the router
+	if spec.Aggregate {
+		if spec.RouterRef == nil && spec.RouterSelector == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("routerRef"), "routerRef or routerSelector is required with aggregate"))
+		}
+		if spec.ConfigMapName != "" {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("configMapName"), "may not be set with aggregate"))
+		}
+	}
+
+	if spec.RouterSelector == nil {
+		if len(spec.RouterNamespaces) > 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("routerNamespaces"), "may only be set with routerSelector"))
+		}
+		return allErrs
+	}
+
+	selectorPath := specPath.Child("routerSelector")
+	if spec.RouterRef != nil {
+		allErrs = append(allErrs, field.Forbidden(selectorPath, "may not be set with routerRef"))
+	}
+	if len(spec.RouterSelector.MatchLabels) == 0 && len(spec.RouterSelector.MatchExpressions) == 0 {
+		allErrs = append(allErrs, field.Required(selectorPath, "must not select every service"))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.RouterSelector, metav1validation.LabelSelectorValidationOptions{}, selectorPath)...)
+	for i, namespace := range spec.RouterNamespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("routerNamespaces").Index(i), namespace, msg))
+		}
+	}
+	return allErrs
+}
+
+// isBackendURL reports whether backend is an absolute http or https URL
+func isBackendURL(backend string) bool {
+	u, err := url.Parse(backend)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -36,6 +36,7 @@ import (
 
 	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
 	"github.com/vllm-project/production-stack/router-controller/internal/controller"
+	webhookv1alpha1 "github.com/vllm-project/production-stack/router-controller/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -151,6 +152,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "StaticRoute")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookv1alpha1.SetupStaticRouteWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "StaticRoute")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,284 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+// conditionAggregationConflict reports whether the backends of an aggregated
+// StaticRoute are left out of the merged dynamic config of a router
+const conditionAggregationConflict = "AggregationConflict"
+
+// aggregatedConfigMapName returns the name of the ConfigMap holding the merged
+// dynamic config of the router
+func aggregatedConfigMapName(router types.NamespacedName) string {
+	return router.Name + "-dynamic-config"
+}
+
+// mergeStaticRoutes merges the backends of the aggregated StaticRoutes of a
+// router, from the oldest to the newest. The oldest StaticRoute sets the
+// routing logic, the session key and the health check of the router. A
+// StaticRoute with another routing logic or session key, claiming a model
+// served by other backends or a backend serving another model conflicts and is
+// left out. mergeStaticRoutes returns the merged spec and the conflict of each
+// StaticRoute left out.
+func mergeStaticRoutes(staticRoutes []productionstackv1alpha1.StaticRoute) (productionstackv1alpha1.StaticRouteSpec, map[types.NamespacedName]string) {
+	staticRoutes = slices.Clone(staticRoutes)
+	slices.SortFunc(staticRoutes, func(a, b productionstackv1alpha1.StaticRoute) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	var merged productionstackv1alpha1.StaticRouteSpec
+	conflicts := make(map[types.NamespacedName]string)
+	// The StaticRoute and the backends serving each model, and the model of each backend
+	modelOwners := make(map[string]types.NamespacedName)
+	modelURLs := make(map[string][]string)
+	urlModels := make(map[string]string)
+	for i, staticRoute := range staticRoutes {
+		key := types.NamespacedName{Namespace: staticRoute.Namespace, Name: staticRoute.Name}
+		spec := &staticRoute.Spec
+		if i == 0 {
+			merged.ServiceDiscovery = spec.ServiceDiscovery
+			merged.RoutingLogic = spec.RoutingLogic
+			merged.SessionKey = spec.SessionKey
+			merged.HealthCheck = spec.HealthCheck
+		}
+
+		// Drained backends serve no traffic and claim no model
+		var backends []productionstackv1alpha1.StaticBackend
+		urls := make(map[string][]string)
+		for _, backend := range staticBackends(spec) {
+			if backend.EffectiveWeight() > 0 {
+				backends = append(backends, backend)
+				urls[backend.Model] = append(urls[backend.Model], backend.URL)
+			}
+		}
+
+		oldest := types.NamespacedName{Namespace: staticRoutes[0].Namespace, Name: staticRoutes[0].Name}
+		var conflict string
+		switch {
+		case spec.RoutingLogic != merged.RoutingLogic:
+			conflict = fmt.Sprintf("routingLogic %s differs from %s of %s", spec.RoutingLogic, merged.RoutingLogic, oldest)
+		case spec.SessionKey != merged.SessionKey:
+			conflict = fmt.Sprintf("sessionKey %q differs from %q of %s", spec.SessionKey, merged.SessionKey, oldest)
+		default:
+			conflict = backendsConflict(backends, urls, modelOwners, modelURLs, urlModels)
+		}
+		if conflict != "" {
+			conflicts[key] = conflict
+			continue
+		}
+
+		for _, backend := range backends {
+			if _, ok := modelOwners[backend.Model]; !ok {
+				modelOwners[backend.Model] = key
+				modelURLs[backend.Model] = urls[backend.Model]
+			}
+			if _, ok := urlModels[backend.URL]; !ok {
+				urlModels[backend.URL] = backend.Model
+				merged.Backends = append(merged.Backends, backend)
+			}
+		}
+	}
+	return merged, conflicts
+}
+
+// backendsConflict returns the first model of the backends served by other
+// merged backends, or the first backend serving another merged model
+func backendsConflict(backends []productionstackv1alpha1.StaticBackend, urls map[string][]string,
+	modelOwners map[string]types.NamespacedName, modelURLs map[string][]string, urlModels map[string]string) string {
+	for _, model := range sortedKeys(urls) {
+		slices.Sort(urls[model])
+		if owner, ok := modelOwners[model]; ok && !slices.Equal(urls[model], modelURLs[model]) {
+			return fmt.Sprintf("model %s is served by other backends of %s", model, owner)
+		}
+	}
+	for _, backend := range backends {
+		if model, ok := urlModels[backend.URL]; ok && model != backend.Model {
+			return fmt.Sprintf("backend %s serves %s", backend.URL, model)
+		}
+	}
+	return ""
+}
+
+// aggregatedStaticRoutes returns the aggregated StaticRoutes of the router
+// with a valid spec that are not being deleted
+func aggregatedStaticRoutes(staticRoutes []productionstackv1alpha1.StaticRoute, router *corev1.Service) []productionstackv1alpha1.StaticRoute {
+	var members []productionstackv1alpha1.StaticRoute
+	for _, staticRoute := range staticRoutes {
+		if !staticRoute.Spec.Aggregate || !staticRoute.DeletionTimestamp.IsZero() || !selectsRouterService(&staticRoute, router) {
+			continue
+		}
+		if len(productionstackv1alpha1.ValidateStaticRouteSpec(&staticRoute.Spec, field.NewPath("spec"))) > 0 {
+			continue
+		}
+		members = append(members, staticRoute)
+	}
+	return members
+}
+
+// reconcileAggregatedConfigs merges the aggregated StaticRoutes of each router
+// of the StaticRoute into the ConfigMap of the router, records the ConfigMaps
+// and the conflicts of the StaticRoute in the status and returns the merged
+// config of each router. The StaticRoute is left out while it is deleted, a
+// router without other StaticRoutes gets the withdrawn config.
+func (r *StaticRouteReconciler) reconcileAggregatedConfigs(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) (map[types.NamespacedName]string, error) {
+	staticRoutes := &productionstackv1alpha1.StaticRouteList{}
+	if err := r.List(ctx, staticRoutes); err != nil {
+		return nil, fmt.Errorf("failed to list StaticRoutes: %w", err)
+	}
+	withdrawn, err := withdrawnDynamicConfig(&staticRoute.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	self := types.NamespacedName{Namespace: staticRoute.Namespace, Name: staticRoute.Name}
+	configs := make(map[types.NamespacedName]string, len(staticRoute.Status.Routers))
+	var conflicts []string
+	for i := range staticRoute.Status.Routers {
+		router := &staticRoute.Status.Routers[i]
+		key := types.NamespacedName{Namespace: router.Namespace, Name: router.Name}
+
+		// A missing router service is only matched by its routerRef
+		service := &corev1.Service{}
+		if err := r.Get(ctx, key, service); errors.IsNotFound(err) {
+			service = nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get router service: %w", err)
+		}
+		target := service
+		if target == nil {
+			target = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		}
+
+		config := withdrawn
+		if members := aggregatedStaticRoutes(staticRoutes.Items, target); len(members) > 0 {
+			merged, memberConflicts := mergeStaticRoutes(members)
+			if config, err = renderDynamicConfig(&merged); err != nil {
+				return nil, err
+			}
+			if conflict, ok := memberConflicts[self]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %s", key, conflict))
+			}
+		}
+		configs[key] = config
+
+		// The ConfigMap is garbage collected with the router service
+		if service == nil {
+			continue
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      aggregatedConfigMapName(key),
+				Namespace: key.Namespace,
+			},
+		}
+		if err := controllerutil.SetOwnerReference(service, configMap, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner reference: %w", err)
+		}
+		if err := r.writeDynamicConfig(ctx, staticRoute, configMap, config); err != nil {
+			return nil, err
+		}
+		router.ConfigMapRef = configMap.Name
+	}
+
+	if len(conflicts) == 0 {
+		meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+			Type:    conditionAggregationConflict,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Merged",
+			Message: "The backends are merged into the dynamic config of every router",
+		})
+		return configs, nil
+	}
+	message := strings.Join(conflicts, "; ")
+	if previous := meta.FindStatusCondition(staticRoute.Status.Conditions, conditionAggregationConflict); previous == nil ||
+		previous.Status != metav1.ConditionTrue || previous.Message != message {
+		r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "AggregationConflict", "The backends are left out of the dynamic config: %s", message)
+	}
+	meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+		Type:    conditionAggregationConflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ConflictingStaticRoutes",
+		Message: message,
+	})
+	return configs, nil
+}
+
+// findAggregatedPeers returns the other aggregated StaticRoutes sharing a
+// router with the StaticRoute, their merged config includes its backends
+func (r *StaticRouteReconciler) findAggregatedPeers(ctx context.Context, obj client.Object) []reconcile.Request {
+	staticRoute, ok := obj.(*productionstackv1alpha1.StaticRoute)
+	if !ok {
+		return nil
+	}
+
+	// The routers the StaticRoute was merged into and the routers it selects now
+	routers := make(map[types.NamespacedName]bool)
+	for _, router := range staticRoute.Status.Routers {
+		routers[types.NamespacedName{Namespace: router.Namespace, Name: router.Name}] = true
+	}
+	keys, err := r.routerKeys(ctx, staticRoute)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to resolve the router services")
+	}
+	for _, key := range keys {
+		routers[key] = true
+	}
+	if len(routers) == 0 {
+		return nil
+	}
+
+	staticRoutes := &productionstackv1alpha1.StaticRouteList{}
+	if err := r.List(ctx, staticRoutes); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list StaticRoutes")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, peer := range staticRoutes.Items {
+		if !peer.Spec.Aggregate || peer.Namespace == staticRoute.Namespace && peer.Name == staticRoute.Name {
+			continue
+		}
+		if slices.ContainsFunc(peer.Status.Routers, func(router productionstackv1alpha1.RouterStatus) bool {
+			return routers[types.NamespacedName{Namespace: router.Namespace, Name: router.Name}]
+		}) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: peer.Namespace, Name: peer.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+var _ = Describe("Aggregation", func() {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newStaticRoute := func(name string, age time.Duration, routingLogic string, backends ...productionstackv1alpha1.StaticBackend) productionstackv1alpha1.StaticRoute {
+		return productionstackv1alpha1.StaticRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Spec: productionstackv1alpha1.StaticRouteSpec{
+				ServiceDiscovery: "static",
+				RoutingLogic:     routingLogic,
+				Backends:         backends,
+				Aggregate:        true,
+			},
+		}
+	}
+	backend := func(url, model string) productionstackv1alpha1.StaticBackend {
+		return productionstackv1alpha1.StaticBackend{URL: url, Model: model}
+	}
+
+	It("should merge the backends of the StaticRoutes", func() {
+		drained := backend("http://runtime-c:8000", "model-c")
+		drained.Weight = ptr.To[int32](0)
+
+		merged, conflicts := mergeStaticRoutes([]productionstackv1alpha1.StaticRoute{
+			newStaticRoute("team-b", time.Minute, "roundrobin", backend("http://runtime-b:8000", "model-b"), drained),
+			newStaticRoute("team-a", time.Hour, "roundrobin", backend("http://runtime-a:8000", "model-a")),
+			// Serving a model with the same backends is no conflict
+			newStaticRoute("team-a-replica", time.Second, "roundrobin", backend("http://runtime-a:8000", "model-a")),
+		})
+		Expect(conflicts).To(BeEmpty())
+		Expect(merged.RoutingLogic).To(Equal("roundrobin"))
+		Expect(merged.Backends).To(Equal([]productionstackv1alpha1.StaticBackend{
+			backend("http://runtime-a:8000", "model-a"),
+			backend("http://runtime-b:8000", "model-b"),
+		}))
+	})
+
+	It("should leave out the StaticRoutes conflicting with older ones", func() {
+		merged, conflicts := mergeStaticRoutes([]productionstackv1alpha1.StaticRoute{
+			newStaticRoute("team-a", time.Hour, "session", backend("http://runtime-a:8000", "model-a")),
+			newStaticRoute("other-model-backends", time.Minute, "session", backend("http://runtime-b:8000", "model-a")),
+			newStaticRoute("other-backend-model", time.Minute, "session", backend("http://runtime-a:8000", "model-b")),
+			newStaticRoute("other-routing-logic", time.Minute, "roundrobin", backend("http://runtime-c:8000", "model-c")),
+		})
+		Expect(merged.RoutingLogic).To(Equal("session"))
+		Expect(merged.Backends).To(Equal([]productionstackv1alpha1.StaticBackend{backend("http://runtime-a:8000", "model-a")}))
+		Expect(conflicts).To(Equal(map[types.NamespacedName]string{
+			{Namespace: "default", Name: "other-model-backends"}: "model model-a is served by other backends of default/team-a",
+			{Namespace: "default", Name: "other-backend-model"}:  "backend http://runtime-a:8000 serves model-a",
+			{Namespace: "default", Name: "other-routing-logic"}:  "routingLogic roundrobin differs from session of default/team-a",
+		}))
+	})
+
+	It("should name the ConfigMap after the router", func() {
+		Expect(aggregatedConfigMapName(types.NamespacedName{Namespace: "routers", Name: "shared-router"})).To(Equal("shared-router-dynamic-config"))
+	})
+})
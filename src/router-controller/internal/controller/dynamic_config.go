@@ -0,0 +1,211 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+// DynamicConfig represents the dynamic configuration for the vllm_router. The
+// router rejects unknown fields, they must match its DynamicRouterConfig.
+type DynamicConfig struct {
+	ServiceDiscovery string `json:"service_discovery"`
+	RoutingLogic     string `json:"routing_logic"`
+	StaticBackends   string `json:"static_backends"`
+	StaticModels     string `json:"static_models"`
+	// StaticBackendWeights are the comma-separated weights of the
+	// staticBackends, omitted while every backend has weight 1
+	StaticBackendWeights string             `json:"static_backend_weights,omitempty"`
+	SessionKey           string             `json:"session_key,omitempty"`
+	HealthCheck          *HealthCheckConfig `json:"health_check,omitempty"`
+}
+
+// HealthCheckConfig represents the health check configuration of the
+// controller's probes of the backends and routers, and of the router's probes
+// of its backends
+type HealthCheckConfig struct {
+	TimeoutSeconds   int32 `json:"timeout_seconds"`
+	PeriodSeconds    int32 `json:"period_seconds"`
+	SuccessThreshold int32 `json:"success_threshold"`
+	FailureThreshold int32 `json:"failure_threshold"`
+}
+
+// healthCheckConfig returns the health check configuration of the spec, with
+// the unset values defaulted
+func healthCheckConfig(hc *productionstackv1alpha1.HealthCheckConfig) HealthCheckConfig {
+	config := HealthCheckConfig{
+		TimeoutSeconds:   5,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	if hc == nil {
+		return config
+	}
+	if hc.TimeoutSeconds > 0 {
+		config.TimeoutSeconds = hc.TimeoutSeconds
+	}
+	if hc.PeriodSeconds > 0 {
+		config.PeriodSeconds = hc.PeriodSeconds
+	}
+	if hc.SuccessThreshold > 0 {
+		config.SuccessThreshold = hc.SuccessThreshold
+	}
+	if hc.FailureThreshold > 0 {
+		config.FailureThreshold = hc.FailureThreshold
+	}
+	return config
+}
+
+// staticBackends returns the backends of the spec, converting the legacy
+// comma-separated staticBackends and staticModels into structured entries
+func staticBackends(spec *productionstackv1alpha1.StaticRouteSpec) []productionstackv1alpha1.StaticBackend {
+	if len(spec.Backends) > 0 {
+		return spec.Backends
+	}
+	if strings.TrimSpace(spec.StaticBackends) == "" {
+		return nil
+	}
+
+	urls := strings.Split(spec.StaticBackends, ",")
+	models := strings.Split(spec.StaticModels, ",")
+	backends := make([]productionstackv1alpha1.StaticBackend, len(urls))
+	for i, url := range urls {
+		backends[i].URL = strings.TrimSpace(url)
+		if i < len(models) {
+			backends[i].Model = strings.TrimSpace(models[i])
+		}
+	}
+	return backends
+}
+
+// dynamicConfigForSpec converts the spec into the dynamic configuration of the
+// router. The legacy staticBackends and staticModels are passed verbatim,
+// structured backends are joined into the comma-separated lists the router
+// expects, with their weights unless every backend has weight 1. Backends of
+// weight 0 are left out so they receive no traffic. The health check is
+// rendered with its defaults, the router excludes the backends failing it.
+func dynamicConfigForSpec(spec *productionstackv1alpha1.StaticRouteSpec) DynamicConfig {
+	dynamicConfig := DynamicConfig{
+		ServiceDiscovery: spec.ServiceDiscovery,
+		RoutingLogic:     spec.RoutingLogic,
+		SessionKey:       spec.SessionKey,
+		StaticBackends:   spec.StaticBackends,
+		StaticModels:     spec.StaticModels,
+	}
+	if spec.HealthCheck != nil {
+		healthCheck := healthCheckConfig(spec.HealthCheck)
+		dynamicConfig.HealthCheck = &healthCheck
+	}
+	if len(spec.Backends) > 0 {
+		var urls, models, weights []string
+		weighted := false
+		for _, backend := range spec.Backends {
+			weight := backend.EffectiveWeight()
+			if weight == 0 {
+				continue
+			}
+			urls = append(urls, backend.URL)
+			models = append(models, backend.Model)
+			weights = append(weights, strconv.Itoa(int(weight)))
+			weighted = weighted || weight != 1
+		}
+		dynamicConfig.StaticBackends = strings.Join(urls, ",")
+		dynamicConfig.StaticModels = strings.Join(models, ",")
+		if weighted {
+			dynamicConfig.StaticBackendWeights = strings.Join(weights, ",")
+		}
+	}
+	return dynamicConfig
+}
+
+// withdrawnDynamicConfig returns the dynamic config of the routers once the
+// StaticRoute is deleted, with its routing logic and without backends
+func withdrawnDynamicConfig(spec *productionstackv1alpha1.StaticRouteSpec) (string, error) {
+	dynamicConfigJSON, err := json.Marshal(DynamicConfig{
+		ServiceDiscovery: spec.ServiceDiscovery,
+		RoutingLogic:     spec.RoutingLogic,
+		SessionKey:       spec.SessionKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dynamic configuration: %w", err)
+	}
+	return string(dynamicConfigJSON), nil
+}
+
+// renderDynamicConfig returns the dynamic_config.json of the spec
+func renderDynamicConfig(spec *productionstackv1alpha1.StaticRouteSpec) (string, error) {
+	dynamicConfigJSON, err := json.Marshal(dynamicConfigForSpec(spec))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dynamic configuration: %w", err)
+	}
+	return string(dynamicConfigJSON), nil
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dynamicConfigDiff summarizes the fields of the dynamic config that changed
+// from previous to current, e.g. "changed static_backends; added session_key"
+func dynamicConfigDiff(previous, current string) string {
+	var before, after map[string]json.RawMessage
+	if json.Unmarshal([]byte(previous), &before) != nil || json.Unmarshal([]byte(current), &after) != nil {
+		return "replaced the dynamic config"
+	}
+
+	var changed, added, removed []string
+	for _, field := range sortedKeys(after) {
+		value, ok := before[field]
+		switch {
+		case !ok:
+			added = append(added, field)
+		case string(value) != string(after[field]):
+			changed = append(changed, field)
+		}
+	}
+	for _, field := range sortedKeys(before) {
+		if _, ok := after[field]; !ok {
+			removed = append(removed, field)
+		}
+	}
+
+	var summary []string
+	for _, part := range []struct {
+		verb   string
+		fields []string
+	}{{"changed", changed}, {"added", added}, {"removed", removed}} {
+		if len(part.fields) > 0 {
+			summary = append(summary, part.verb+" "+strings.Join(part.fields, ", "))
+		}
+	}
+	if len(summary) == 0 {
+		return "the dynamic config is unchanged"
+	}
+	return strings.Join(summary, "; ")
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+// routerConfigFields are the fields of the DynamicRouterConfig of the router
+var routerConfigFields = []string{
+	"service_discovery", "routing_logic",
+	"static_backends", "static_models", "static_backend_weights", "static_aliases",
+	"k8s_port", "k8s_namespace", "k8s_label_selector",
+	"session_key", "health_check",
+}
+
+var _ = Describe("Dynamic config", func() {
+	DescribeTable("rendering the dynamic config of a spec",
+		func(spec productionstackv1alpha1.StaticRouteSpec, golden string) {
+			config, err := renderDynamicConfig(&spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The golden files are indented, the ConfigMap holds compact JSON
+			data, err := os.ReadFile(filepath.Join("testdata", "dynamic_config", golden))
+			Expect(err).NotTo(HaveOccurred())
+			var expected bytes.Buffer
+			Expect(json.Compact(&expected, data)).To(Succeed())
+			Expect(config).To(Equal(expected.String()))
+
+			var fields map[string]any
+			Expect(json.Unmarshal([]byte(config), &fields)).To(Succeed())
+			for field := range fields {
+				Expect(routerConfigFields).To(ContainElement(field))
+			}
+		},
+		Entry("legacy comma-separated strings are passed verbatim", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "roundrobin",
+			StaticBackends:   "http://runtime-a:8000, http://runtime-b:8000",
+			StaticModels:     "facebook/opt-125m, meta-llama/Llama-3.1-8B-Instruct",
+		}, "legacy_strings.json"),
+		Entry("structured backends are joined", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "roundrobin",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](1)},
+				{URL: "http://runtime-b:8000", Model: "meta-llama/Llama-3.1-8B-Instruct", Priority: 1},
+			},
+		}, "structured_backends.json"),
+		Entry("backends of weight 0 are drained", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "roundrobin",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](0)},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](1)},
+				{URL: "http://runtime-c:8000", Model: "facebook/opt-125m"},
+			},
+		}, "drained_backends.json"),
+		Entry("weighted backends carry their weights", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "roundrobin",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](3)},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](0)},
+				{URL: "http://runtime-c:8000", Model: "facebook/opt-125m"},
+			},
+		}, "weighted_backends.json"),
+		Entry("session routing carries the session key", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "session",
+			SessionKey:       "x-user-id",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m"},
+			},
+		}, "session.json"),
+		Entry("prefix-aware routing", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "prefixaware",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m"},
+			},
+		}, "prefixaware.json"),
+		Entry("the health check is rendered with its defaults", productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "roundrobin",
+			Backends: []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m"},
+			},
+			HealthCheck: &productionstackv1alpha1.HealthCheckConfig{PeriodSeconds: 30, FailureThreshold: 5},
+		}, "health_check.json"),
+	)
+
+	DescribeTable("summarizing the changes of the dynamic config",
+		func(previous, current, expected string) {
+			Expect(dynamicConfigDiff(previous, current)).To(Equal(expected))
+		},
+		Entry("changed, added and removed fields",
+			`{"routing_logic":"roundrobin","static_backends":"http://a:8000","static_models":"facebook/opt-125m"}`,
+			`{"routing_logic":"session","static_backends":"http://b:8000","session_key":"x-user-id"}`,
+			"changed routing_logic, static_backends; added session_key; removed static_models"),
+		Entry("an unchanged config",
+			`{"routing_logic":"roundrobin"}`, `{"routing_logic":"roundrobin"}`, "the dynamic config is unchanged"),
+		Entry("an unparsable previous config",
+			`not json`, `{"routing_logic":"roundrobin"}`, "replaced the dynamic config"),
+	)
+
+	It("should withdraw the backends and keep the routing logic", func() {
+		config, err := withdrawnDynamicConfig(&productionstackv1alpha1.StaticRouteSpec{
+			ServiceDiscovery: "static",
+			RoutingLogic:     "session",
+			SessionKey:       "x-user-id",
+			StaticBackends:   "http://runtime-a:8000",
+			StaticModels:     "facebook/opt-125m",
+			HealthCheck:      &productionstackv1alpha1.HealthCheckConfig{},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(`{"service_discovery":"static","routing_logic":"session","static_backends":"","static_models":"","session_key":"x-user-id"}`))
+	})
+
+	It("should convert the legacy strings into backends", func() {
+		Expect(staticBackends(&productionstackv1alpha1.StaticRouteSpec{
+			StaticBackends: "http://runtime-a:8000, http://runtime-b:8000",
+			StaticModels:   "facebook/opt-125m, meta-llama/Llama-3.1-8B-Instruct",
+		})).To(Equal([]productionstackv1alpha1.StaticBackend{
+			{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+			{URL: "http://runtime-b:8000", Model: "meta-llama/Llama-3.1-8B-Instruct"},
+		}))
+		Expect(staticBackends(&productionstackv1alpha1.StaticRouteSpec{})).To(BeEmpty())
+
+		backends := []productionstackv1alpha1.StaticBackend{{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](0)}}
+		Expect(staticBackends(&productionstackv1alpha1.StaticRouteSpec{Backends: backends})).To(Equal(backends))
+	})
+})
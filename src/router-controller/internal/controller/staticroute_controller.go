@@ -16,41 +16,116 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	goerrors "errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
 )
 
-// DynamicConfig represents the dynamic configuration for the vllm_router
-type DynamicConfig struct {
-	ServiceDiscovery string             `json:"service_discovery"`
-	RoutingLogic     string             `json:"routing_logic"`
-	StaticBackends   string             `json:"static_backends"`
-	StaticModels     string             `json:"static_models"`
-	HealthCheck      *HealthCheckConfig `json:"health_check,omitempty"`
+const (
+	// dynamicConfigKey is the ConfigMap key of the dynamic configuration
+	dynamicConfigKey = "dynamic_config.json"
+
+	// conditionConfigPushFailed reports whether the last push of the dynamic
+	// configuration to the router failed
+	conditionConfigPushFailed = "ConfigPushFailed"
+
+	// defaultConfigPushPath is the router endpoint accepting the dynamic configuration
+	defaultConfigPushPath = "/dynamic_config"
+
+	// conditionBackendsHealthy reports whether every static backend is healthy
+	conditionBackendsHealthy = "BackendsHealthy"
+
+	// maxConcurrentBackendProbes bounds the concurrent health probes of the static backends
+	maxConcurrentBackendProbes = 10
+
+	// conditionSpecInvalid reports whether the spec would render a dynamic
+	// configuration the router cannot use
+	conditionSpecInvalid = "SpecInvalid"
+
+	// conditionHealthCheckSucceeded and conditionHealthCheckFailed report
+	// whether the router health checks crossed the successThreshold or the
+	// failureThreshold last
+	conditionHealthCheckSucceeded = "HealthCheckSucceeded"
+	conditionHealthCheckFailed    = "HealthCheckFailed"
+
+	// routerHealthy and routerUnhealthy are the states of a router once a
+	// health check threshold is crossed
+	routerHealthy   = "Healthy"
+	routerUnhealthy = "Unhealthy"
+
+	// conditionReady reports whether the dynamic config of the spec is applied
+	// to every router and the routers are healthy
+	conditionReady = "Ready"
+
+	// withdrawRoutesFinalizer withdraws the backends of a deleted StaticRoute
+	// from the routers its dynamic config was pushed to
+	withdrawRoutesFinalizer = "production-stack.vllm.ai/withdraw-routes"
+
+	// maxWithdrawAttempts bounds the attempts to withdraw the backends from an
+	// unreachable router, the StaticRoute is deleted afterwards
+	maxWithdrawAttempts = 5
+
+	// withdrawRetryInterval is the interval between the attempts to withdraw
+	// the backends
+	withdrawRetryInterval = 10 * time.Second
+)
+
+// specInvalidError is returned for a spec the router cannot use
+type specInvalidError struct {
+	errs field.ErrorList
 }
 
-// HealthCheckConfig represents the health check configuration for the vllm_router
-type HealthCheckConfig struct {
-	TimeoutSeconds   int32 `json:"timeout_seconds,omitempty"`
-	PeriodSeconds    int32 `json:"period_seconds,omitempty"`
-	SuccessThreshold int32 `json:"success_threshold,omitempty"`
-	FailureThreshold int32 `json:"failure_threshold,omitempty"`
+func (e *specInvalidError) Error() string {
+	return e.errs.ToAggregate().Error()
+}
+
+// Reason returns the reason of the SpecInvalid condition after the field of
+// the first error
+func (e *specInvalidError) Reason() string {
+	// e.g. spec.backends[0].url
+	name, _, _ := strings.Cut(strings.TrimPrefix(e.errs[0].Field, "spec."), ".")
+	name, _, _ = strings.Cut(name, "[")
+	switch name {
+	case "backends", "staticBackends", "staticModels":
+		return "InvalidStaticBackends"
+	case "routerRef", "routerSelector", "routerNamespaces":
+		return "InvalidRouters"
+	case "sessionKey":
+		return "InvalidSessionKey"
+	case "configMapName":
+		return "InvalidConfigMapName"
+	default:
+		return "InvalidSpec"
+	}
 }
 
 // StaticRouteReconciler reconciles a StaticRoute object
@@ -66,6 +141,8 @@ type StaticRouteReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -88,35 +165,125 @@ func (r *StaticRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Create or update the ConfigMap with the dynamic configuration
-	configMap, err := r.reconcileConfigMap(ctx, staticRoute)
-	if err != nil {
-		logger.Error(err, "Failed to reconcile ConfigMap")
+	// The routers keep serving the last config they loaded
+	if !staticRoute.DeletionTimestamp.IsZero() {
+		return r.withdrawRoutes(ctx, staticRoute)
+	}
+	withdraws := pushesDynamicConfig(&staticRoute.Spec) || staticRoute.Spec.Aggregate
+	if withdraws && controllerutil.AddFinalizer(staticRoute, withdrawRoutesFinalizer) {
+		if err := r.Update(ctx, staticRoute); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Admission may be bypassed without the webhook, never write a
+	// configuration the router would misroute with
+	config, err := validDynamicConfig(&staticRoute.Spec)
+	var invalid *specInvalidError
+	if goerrors.As(err, &invalid) {
+		// Keep the ConfigMap of the last valid spec until the spec is fixed
+		logger.Info("Invalid StaticRoute spec", "error", invalid.Error())
+		r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "SpecInvalid", "Not applying the dynamic config: %v", invalid)
+		meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+			Type:    conditionSpecInvalid,
+			Status:  metav1.ConditionTrue,
+			Reason:  invalid.Reason(),
+			Message: invalid.Error(),
+		})
+		setReadyCondition(staticRoute)
+		staticRoute.Status.ObservedGeneration = staticRoute.Generation
+		if err := r.Status().Update(ctx, staticRoute); err != nil {
+			logger.Error(err, "Failed to update StaticRoute status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to render the dynamic config")
 		return ctrl.Result{}, err
 	}
 
-	// Update the status with the ConfigMap reference
-	if staticRoute.Status.ConfigMapRef != configMap.Name {
-		staticRoute.Status.ConfigMapRef = configMap.Name
-		now := metav1.Now()
-		staticRoute.Status.LastAppliedTime = &now
+	// Create or update the ConfigMap with the dynamic configuration, the
+	// aggregated StaticRoutes of a router share its ConfigMap instead
+	var configMapRef string
+	if !staticRoute.Spec.Aggregate {
+		configMap, err := r.reconcileConfigMap(ctx, staticRoute, config)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile ConfigMap")
+			return ctrl.Result{}, err
+		}
+		configMapRef = configMap.Name
+	}
 
-		// Update the status
+	// Push the dynamic configuration to the router and record the
+	// ConfigMap reference and the push result in the status
+	status := staticRoute.Status.DeepCopy()
+	staticRoute.Status.ConfigMapRef = configMapRef
+	meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+		Type:    conditionSpecInvalid,
+		Status:  metav1.ConditionFalse,
+		Reason:  "SpecValid",
+		Message: "The static backends and models are valid",
+	})
+	routers, err := r.routerKeys(ctx, staticRoute)
+	if err != nil {
+		logger.Error(err, "Failed to resolve the router services")
+		return ctrl.Result{}, err
+	}
+	syncRouterStatuses(&staticRoute.Status, routers)
+	var merged map[types.NamespacedName]string
+	if staticRoute.Spec.Aggregate {
+		if merged, err = r.reconcileAggregatedConfigs(ctx, staticRoute); err != nil {
+			logger.Error(err, "Failed to merge the aggregated StaticRoutes")
+			return ctrl.Result{}, err
+		}
+	} else {
+		meta.RemoveStatusCondition(&staticRoute.Status.Conditions, conditionAggregationConflict)
+	}
+	pushErr := r.applyDynamicConfig(ctx, staticRoute, config, merged)
+	setReadyCondition(staticRoute)
+	if !equality.Semantic.DeepEqual(status, &staticRoute.Status) {
 		if err := r.Status().Update(ctx, staticRoute); err != nil {
 			logger.Error(err, "Failed to update StaticRoute status")
 			return ctrl.Result{}, err
 		}
 	}
+	if pushErr != nil {
+		// ConfigPushFailed records the failure, the health checks still run
+		logger.Error(pushErr, "Failed to push dynamic config to the router")
+	}
 
-	// Check the router's health endpoint
-	if err := r.checkRouterHealth(ctx, staticRoute); err != nil {
-		logger.Error(err, "Failed to check router health")
+	// Probe the static backends and the router's health endpoint once, the
+	// next probe is requeued
+	healthPending, err := r.checkHealth(ctx, staticRoute)
+	if err != nil {
+		logger.Error(err, "Failed to check health")
 		return ctrl.Result{}, err
 	}
 
+	// The edit of the spec is fully processed once the push succeeded
+	status = staticRoute.Status.DeepCopy()
+	setReadyCondition(staticRoute)
+	if pushErr == nil {
+		staticRoute.Status.ObservedGeneration = staticRoute.Generation
+	}
+	if !equality.Semantic.DeepEqual(status, &staticRoute.Status) {
+		if err := r.Status().Update(ctx, staticRoute); err != nil {
+			logger.Error(err, "Failed to update StaticRoute status")
+			return ctrl.Result{}, err
+		}
+	}
+	if pushErr != nil {
+		// Returning the error retries the push with backoff
+		return ctrl.Result{}, pushErr
+	}
+
 	// Determine requeue interval based on health check configuration
 	requeueAfter := 5 * time.Minute // Default requeue interval
-	if staticRoute.Spec.HealthCheck != nil && staticRoute.Spec.HealthCheck.PeriodSeconds > 0 {
+	if healthPending {
+		// Probe again after the period until a threshold is crossed
+		requeueAfter = time.Duration(healthCheckConfig(staticRoute.Spec.HealthCheck).PeriodSeconds) * time.Second
+	} else if staticRoute.Spec.HealthCheck != nil && staticRoute.Spec.HealthCheck.PeriodSeconds > 0 {
 		// Use the health check period as the requeue interval, but ensure it's not too frequent
 		periodSeconds := staticRoute.Spec.HealthCheck.PeriodSeconds
 		if periodSeconds < 60 {
@@ -131,24 +298,17 @@ func (r *StaticRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-// reconcileConfigMap creates or updates the ConfigMap with the dynamic configuration
-func (r *StaticRouteReconciler) reconcileConfigMap(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) (*corev1.ConfigMap, error) {
-	logger := log.FromContext(ctx)
-
-	// Create the dynamic configuration
-	dynamicConfig := DynamicConfig{
-		ServiceDiscovery: staticRoute.Spec.ServiceDiscovery,
-		RoutingLogic:     staticRoute.Spec.RoutingLogic,
-		StaticBackends:   staticRoute.Spec.StaticBackends,
-		StaticModels:     staticRoute.Spec.StaticModels,
-	}
-
-	// Convert the dynamic configuration to JSON
-	dynamicConfigJSON, err := json.Marshal(dynamicConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal dynamic configuration: %w", err)
+// validDynamicConfig returns the dynamic configuration of the spec, or a
+// specInvalidError if the spec is invalid
+func validDynamicConfig(spec *productionstackv1alpha1.StaticRouteSpec) (string, error) {
+	if errs := productionstackv1alpha1.ValidateStaticRouteSpec(spec, field.NewPath("spec")); len(errs) > 0 {
+		return "", &specInvalidError{errs: errs}
 	}
+	return renderDynamicConfig(spec)
+}
 
+// reconcileConfigMap creates or updates the ConfigMap with the dynamic configuration
+func (r *StaticRouteReconciler) reconcileConfigMap(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, dynamicConfigJSON string) (*corev1.ConfigMap, error) {
 	// Determine the ConfigMap name
 	configMapName := staticRoute.Spec.ConfigMapName
 	if configMapName == "" {
@@ -168,84 +328,385 @@ func (r *StaticRouteReconciler) reconcileConfigMap(ctx context.Context, staticRo
 		return nil, fmt.Errorf("failed to set owner reference: %w", err)
 	}
 
-	// Create or update the ConfigMap
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+	if err := r.writeDynamicConfig(ctx, staticRoute, configMap, dynamicConfigJSON); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// writeDynamicConfig creates or updates the ConfigMap with the dynamic
+// configuration and records the change as an event of the StaticRoute
+func (r *StaticRouteReconciler) writeDynamicConfig(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, configMap *corev1.ConfigMap, dynamicConfigJSON string) error {
+	logger := log.FromContext(ctx)
+
+	var previous string
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
 		if configMap.Data == nil {
 			configMap.Data = make(map[string]string)
 		}
-		configMap.Data["dynamic_config.json"] = string(dynamicConfigJSON)
+		previous = configMap.Data[dynamicConfigKey]
+		configMap.Data[dynamicConfigKey] = dynamicConfigJSON
 		return nil
 	})
 	if err != nil {
 		r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "FailedReconcileConfigMap", "Failed to create or update ConfigMap %s/%s : %v", configMap.Namespace, configMap.Name, err)
-		return nil, fmt.Errorf("failed to create or update ConfigMap: %w", err)
+		return fmt.Errorf("failed to create or update ConfigMap: %w", err)
+	}
+	switch op {
+	case controllerutil.OperationResultCreated:
+		r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "ConfigMapCreated", "Created ConfigMap %s/%s", configMap.Namespace, configMap.Name)
+	case controllerutil.OperationResultUpdated:
+		r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "ConfigMapUpdated", "Updated ConfigMap %s/%s: %s",
+			configMap.Namespace, configMap.Name, dynamicConfigDiff(previous, dynamicConfigJSON))
 	}
 
 	logger.Info("ConfigMap reconciled successfully", "namespace", configMap.Namespace, "name", configMap.Name)
-	return configMap, nil
+	return nil
 }
 
-// checkRouterHealth checks the health endpoint of the router
-func (r *StaticRouteReconciler) checkRouterHealth(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) error {
-	logger := log.FromContext(ctx)
-
-	// Get service that match the router selector or use the router reference
-	service := &corev1.Service{}
+// applyDynamicConfig pushes the dynamic configuration to each router unless
+// it was already applied, and records the outcome in the status. The merged
+// config of a router of an aggregated StaticRoute is pushed instead. With the
+// push disabled or no routers, the router reads the ConfigMap and writing it
+// applies the configuration.
+func (r *StaticRouteReconciler) applyDynamicConfig(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, config string, merged map[types.NamespacedName]string) error {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(config)))
+	if !pushesDynamicConfig(&staticRoute.Spec) || len(staticRoute.Status.Routers) == 0 {
+		meta.RemoveStatusCondition(&staticRoute.Status.Conditions, conditionConfigPushFailed)
+		if staticRoute.Status.AppliedConfigHash != hash {
+			now := metav1.Now()
+			staticRoute.Status.LastAppliedTime = &now
+			staticRoute.Status.AppliedConfigHash = hash
+		}
+		return nil
+	}
 
-	if staticRoute.Spec.RouterRef != nil {
-		// Use the RouterRef to directly access the service
-		serviceKey := client.ObjectKey{
-			Name:      staticRoute.Spec.RouterRef.Name,
-			Namespace: staticRoute.Spec.RouterRef.Namespace,
+	// A failed push is retried even if the configuration is unchanged
+	now := metav1.Now()
+	var pushes []routerPush
+	var hashes []string
+	for i := range staticRoute.Status.Routers {
+		router := &staticRoute.Status.Routers[i]
+		key := types.NamespacedName{Namespace: router.Namespace, Name: router.Name}
+		routerConfig, routerHash := config, hash
+		if mergedConfig, ok := merged[key]; ok {
+			routerConfig, routerHash = mergedConfig, fmt.Sprintf("%x", sha256.Sum256([]byte(mergedConfig)))
 		}
+		if router.AppliedConfigHash == routerHash && router.LastPushResult == "Succeeded" {
+			continue
+		}
+		pushes = append(pushes, routerPush{index: i, key: key, config: routerConfig})
+		hashes = append(hashes, routerHash)
+	}
+	if len(pushes) == 0 {
+		return nil
+	}
 
-		// If namespace is not specified, use the StaticRoute's namespace
-		if serviceKey.Namespace == "" {
-			serviceKey.Namespace = staticRoute.Namespace
+	var failures []string
+	for i, err := range r.pushDynamicConfigs(ctx, staticRoute, pushes) {
+		router := &staticRoute.Status.Routers[pushes[i].index]
+		router.LastPushTime = &now
+		if err != nil {
+			router.LastPushResult = fmt.Sprintf("Failed: %v", err)
+			failures = append(failures, fmt.Sprintf("%s: %v", pushes[i].key, err))
+			continue
 		}
+		router.LastPushResult = "Succeeded"
+		router.AppliedConfigHash = hashes[i]
+	}
+
+	staticRoute.Status.LastPushTime = &now
+	if len(failures) > 0 {
+		message := strings.Join(failures, "; ")
+		staticRoute.Status.LastPushResult = "Failed: " + message
+		meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+			Type:    conditionConfigPushFailed,
+			Status:  metav1.ConditionTrue,
+			Reason:  "PushFailed",
+			Message: fmt.Sprintf("Failed to push the dynamic config to the router: %s", message),
+		})
+		r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "ConfigPushFailed", "Failed to push the dynamic config to the router: %s", message)
+		return fmt.Errorf("failed to push dynamic config: %s", message)
+	}
+
+	staticRoute.Status.LastPushResult = "Succeeded"
+	if staticRoute.Status.AppliedConfigHash != hash {
+		staticRoute.Status.LastAppliedTime = &now
+		staticRoute.Status.AppliedConfigHash = hash
+	}
+	meta.SetStatusCondition(&staticRoute.Status.Conditions, metav1.Condition{
+		Type:    conditionConfigPushFailed,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ConfigPushed",
+		Message: "The dynamic config was applied to the router",
+	})
+	r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "ConfigPushed", "Pushed the dynamic config to %d router(s)", len(pushes))
+	return nil
+}
+
+// pushesDynamicConfig reports whether the dynamic config of the spec is pushed
+// to routers
+func pushesDynamicConfig(spec *productionstackv1alpha1.StaticRouteSpec) bool {
+	if spec.ConfigPush != nil && spec.ConfigPush.Disabled {
+		return false
+	}
+	return spec.RouterRef != nil || spec.RouterSelector != nil
+}
+
+// withdrawRoutes pushes a dynamic config without the backends of the deleted
+// StaticRoute to each router it was applied to, the merged config of the other
+// StaticRoutes for an aggregated StaticRoute, and removes the finalizer once
+// every router acknowledged it. Unreachable routers are retried up to
+// maxWithdrawAttempts times, the StaticRoute is deleted afterwards.
+func (r *StaticRouteReconciler) withdrawRoutes(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
 
-		err := r.Get(ctx, serviceKey, service)
+	if !controllerutil.ContainsFinalizer(staticRoute, withdrawRoutesFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	config, err := withdrawnDynamicConfig(&staticRoute.Spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var merged map[types.NamespacedName]string
+	if staticRoute.Spec.Aggregate {
+		if merged, err = r.reconcileAggregatedConfigs(ctx, staticRoute); err != nil {
+			logger.Error(err, "Failed to merge the aggregated StaticRoutes")
+			return ctrl.Result{}, err
+		}
+	}
+	var pushes []routerPush
+	for i, router := range staticRoute.Status.Routers {
+		if !pushesDynamicConfig(&staticRoute.Spec) || router.AppliedConfigHash == "" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: router.Namespace, Name: router.Name}
+		routerConfig := config
+		if mergedConfig, ok := merged[key]; ok {
+			routerConfig = mergedConfig
+		}
+		pushes = append(pushes, routerPush{index: i, key: key, config: routerConfig})
+	}
+	now := metav1.Now()
+	var withdrawn int
+	var failures []string
+	for i, err := range r.pushDynamicConfigs(ctx, staticRoute, pushes) {
+		router := &staticRoute.Status.Routers[pushes[i].index]
+		router.LastPushTime = &now
 		if err != nil {
-			if errors.IsNotFound(err) {
-				logger.Error(err, "Router service not found", "name", serviceKey.Name, "namespace", serviceKey.Namespace)
-				return fmt.Errorf("router service %s/%s not found: %w", serviceKey.Namespace, serviceKey.Name, err)
+			router.LastPushResult = fmt.Sprintf("Failed: %v", err)
+			failures = append(failures, fmt.Sprintf("%s: %v", pushes[i].key, err))
+			continue
+		}
+		withdrawn++
+		router.LastPushResult = "Withdrawn"
+		router.AppliedConfigHash = ""
+	}
+
+	if len(failures) > 0 {
+		message := strings.Join(failures, "; ")
+		staticRoute.Status.WithdrawAttempts++
+		if staticRoute.Status.WithdrawAttempts < maxWithdrawAttempts {
+			r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "WithdrawFailed", "Failed to withdraw the routes (attempt %d of %d): %s",
+				staticRoute.Status.WithdrawAttempts, maxWithdrawAttempts, message)
+			if err := r.Status().Update(ctx, staticRoute); err != nil {
+				logger.Error(err, "Failed to update StaticRoute status")
+				return ctrl.Result{}, err
 			}
-			return fmt.Errorf("failed to get router service: %w", err)
+			return ctrl.Result{RequeueAfter: withdrawRetryInterval}, nil
 		}
-	} else {
-		logger.Info("No router reference provided")
-		return nil
+		r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "WithdrawSkipped", "Deleting without withdrawing the routes after %d attempts: %s",
+			staticRoute.Status.WithdrawAttempts, message)
+	} else if withdrawn > 0 {
+		r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "RoutesWithdrawn", "Withdrew the routes from %d router(s)", withdrawn)
 	}
 
-	// Get health check configuration with defaults
+	controllerutil.RemoveFinalizer(staticRoute, withdrawRoutesFinalizer)
+	if err := r.Update(ctx, staticRoute); err != nil {
+		logger.Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// routerPush is a dynamic configuration to push to the router of the status
+// at index
+type routerPush struct {
+	index  int
+	key    types.NamespacedName
+	config string
+}
+
+// pushDynamicConfigs pushes the dynamic configurations to their routers
+// concurrently, so a reconcile takes at most one push timeout, and returns the
+// error of each push
+func (r *StaticRouteReconciler) pushDynamicConfigs(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, pushes []routerPush) []error {
+	errs := make([]error, len(pushes))
+	var wg sync.WaitGroup
+	for i, push := range pushes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = r.pushDynamicConfig(ctx, staticRoute, push.key, push.config)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// pushDynamicConfig POSTs the dynamic configuration to the router API
+func (r *StaticRouteReconciler) pushDynamicConfig(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, key types.NamespacedName, config string) error {
+	logger := log.FromContext(ctx)
+
+	service, err := r.getRouterService(ctx, key)
+	if err != nil {
+		return err
+	}
+	baseURL := routerServiceURL(service)
+	if baseURL == "" {
+		return fmt.Errorf("no http port found for router service %s/%s", service.Namespace, service.Name)
+	}
+
+	path := defaultConfigPushPath
 	timeoutSeconds := int32(5)
-	periodSeconds := int32(10)
-	successThreshold := int32(1)
-	failureThreshold := int32(3)
+	var token string
+	if push := staticRoute.Spec.ConfigPush; push != nil {
+		if push.Path != "" {
+			path = push.Path
+		}
+		if push.TimeoutSeconds > 0 {
+			timeoutSeconds = push.TimeoutSeconds
+		}
+		if push.TokenSecretRef != nil {
+			if token, err = r.configPushToken(ctx, staticRoute.Namespace, push.TokenSecretRef); err != nil {
+				return err
+			}
+		}
+	}
+	pushURL := baseURL + path
+
+	logger.Info("Pushing dynamic config", "url", pushURL)
+	return postDynamicConfig(ctx, pushURL, config, token, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// configPushToken returns the bearer token of the pushes from the key of the
+// Secret selected by ref
+func (r *StaticRouteReconciler) configPushToken(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("failed to get the token Secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	token, ok := secret.Data[ref.Key]
+	if !ok || len(token) == 0 {
+		return "", fmt.Errorf("token Secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
 
-	if staticRoute.Spec.HealthCheck != nil {
-		if staticRoute.Spec.HealthCheck.TimeoutSeconds > 0 {
-			timeoutSeconds = staticRoute.Spec.HealthCheck.TimeoutSeconds
+// postDynamicConfig POSTs the dynamic configuration to url, with the bearer
+// token unless it is empty, and fails unless the router accepts it with a 2xx
+// status
+func postDynamicConfig(ctx context.Context, url, config, token string, timeout time.Duration) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(config))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("router returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// routerKeys returns the router services of the StaticRoute: the service of
+// the RouterRef, or the services matching the RouterSelector sorted by
+// namespace and name
+func (r *StaticRouteReconciler) routerKeys(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) ([]types.NamespacedName, error) {
+	if ref := staticRoute.Spec.RouterRef; ref != nil {
+		// If namespace is not specified, use the StaticRoute's namespace
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = staticRoute.Namespace
 		}
-		if staticRoute.Spec.HealthCheck.PeriodSeconds > 0 {
-			periodSeconds = staticRoute.Spec.HealthCheck.PeriodSeconds
+		return []types.NamespacedName{{Namespace: namespace, Name: ref.Name}}, nil
+	}
+	if staticRoute.Spec.RouterSelector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(staticRoute.Spec.RouterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid router selector: %w", err)
+	}
+	namespaces := staticRoute.Spec.RouterNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{staticRoute.Namespace}
+	}
+	var keys []types.NamespacedName
+	for _, namespace := range namespaces {
+		services := &corev1.ServiceList{}
+		if err := r.List(ctx, services, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list router services in namespace %s: %w", namespace, err)
 		}
-		if staticRoute.Spec.HealthCheck.SuccessThreshold > 0 {
-			successThreshold = staticRoute.Spec.HealthCheck.SuccessThreshold
+		for _, service := range services.Items {
+			keys = append(keys, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
 		}
-		if staticRoute.Spec.HealthCheck.FailureThreshold > 0 {
-			failureThreshold = staticRoute.Spec.HealthCheck.FailureThreshold
+	}
+	slices.SortFunc(keys, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return slices.Compact(keys), nil
+}
+
+// syncRouterStatuses keeps the status of each router in keys, in their order,
+// and drops the status of the routers no longer selected
+func syncRouterStatuses(status *productionstackv1alpha1.StaticRouteStatus, keys []types.NamespacedName) {
+	previous := make(map[types.NamespacedName]productionstackv1alpha1.RouterStatus, len(status.Routers))
+	for _, router := range status.Routers {
+		previous[types.NamespacedName{Namespace: router.Namespace, Name: router.Name}] = router
+	}
+	var routers []productionstackv1alpha1.RouterStatus
+	for _, key := range keys {
+		router, ok := previous[key]
+		if !ok {
+			router = productionstackv1alpha1.RouterStatus{Namespace: key.Namespace, Name: key.Name}
 		}
+		routers = append(routers, router)
 	}
+	status.Routers = routers
+}
+
+// getRouterService returns the router service of key
+func (r *StaticRouteReconciler) getRouterService(ctx context.Context, key types.NamespacedName) (*corev1.Service, error) {
+	logger := log.FromContext(ctx)
 
-	logger.Info("Using health check configuration for router service",
-		"namespace", service.Namespace,
-		"name", service.Name,
-		"timeoutSeconds", timeoutSeconds,
-		"periodSeconds", periodSeconds,
-		"successThreshold", successThreshold,
-		"failureThreshold", failureThreshold)
+	service := &corev1.Service{}
+	err := r.Get(ctx, key, service)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Error(err, "Router service not found", "name", key.Name, "namespace", key.Namespace)
+			return nil, fmt.Errorf("router service %s/%s not found: %w", key.Namespace, key.Name, err)
+		}
+		return nil, fmt.Errorf("failed to get router service: %w", err)
+	}
+	return service, nil
+}
 
+// routerServiceURL returns the base URL of the router service, or "" if it has
+// no http port
+func routerServiceURL(service *corev1.Service) string {
 	// Get the service port
 	var port int32
 	for _, p := range service.Spec.Ports {
@@ -256,136 +717,419 @@ func (r *StaticRouteReconciler) checkRouterHealth(ctx context.Context, staticRou
 	}
 
 	if port == 0 {
-		logger.Info("No suitable port found for service", "namespace", service.Namespace, "name", service.Name)
-		return nil
+		return ""
 	}
 
-	// Check the health endpoint
 	// Try to use the service's cluster IP directly instead of DNS name if CoreDNS is not working
-	healthURL := ""
 	if service.Spec.ClusterIP != "" && service.Spec.ClusterIP != "None" {
-		healthURL = fmt.Sprintf("http://%s:%d/health", service.Spec.ClusterIP, port)
-	} else {
-		healthURL = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d/health", service.Name, service.Namespace, port)
+		return fmt.Sprintf("http://%s:%d", service.Spec.ClusterIP, port)
 	}
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, port)
+}
 
-	logger.Info("Checking health endpoint", "url", healthURL)
+// checkHealth probes the static backends and the health endpoint of each
+// router concurrently, all probes of a reconcile share one probe timeout, and
+// records the results in the status. It reports whether a router health
+// threshold flip is pending.
+func (r *StaticRouteReconciler) checkHealth(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) (bool, error) {
+	timeout := time.Duration(healthCheckConfig(staticRoute.Spec.HealthCheck).TimeoutSeconds) * time.Second
+	var backendURLs []string
+	for _, backend := range staticBackends(&staticRoute.Spec) {
+		backendURLs = append(backendURLs, backend.URL)
+	}
+	// A missing router service fails the health check after the backends
+	// are recorded
+	routerURLs, routersErr := r.routerHealthURLs(ctx, staticRoute)
 
-	// Create a client with a timeout
-	client := &http.Client{
-		Timeout: time.Duration(timeoutSeconds) * time.Second,
+	backends, routerErrs := probeHealth(ctx, backendURLs, routerURLs, timeout)
+	r.recordBackendsHealth(staticRoute, backends)
+	pending := false
+	if routersErr == nil {
+		pending = r.recordRouterHealth(ctx, staticRoute, routerURLs, routerErrs)
 	}
 
-	// Track consecutive successes and failures
-	consecutiveSuccesses := int32(0)
-	consecutiveFailures := int32(0)
-	maxRetries := failureThreshold
+	if err := r.Status().Update(ctx, staticRoute); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update StaticRoute status")
+		return false, err
+	}
+	return pending, routersErr
+}
 
-	// Retry the health check based on configuration
-	err := wait.PollUntilContextTimeout(ctx, time.Duration(periodSeconds)*time.Second, time.Duration(periodSeconds*maxRetries)*time.Second, false, func(ctx context.Context) (bool, error) {
-		resp, err := client.Get(healthURL)
-		if err != nil {
-			logger.Error(err, "Failed to connect to health endpoint", "url", healthURL)
-			consecutiveSuccesses = 0
-			consecutiveFailures++
-			if consecutiveFailures >= failureThreshold {
-				return false, fmt.Errorf("health check failed after %d consecutive failures: %w", consecutiveFailures, err)
-			}
-			return false, nil
-		}
-		defer resp.Body.Close()
-		logger.Info("Health endpoint returned status", "url", healthURL, "status", resp.StatusCode)
-		if resp.StatusCode != http.StatusOK {
-			logger.Info("Health endpoint returned non-OK status", "url", healthURL, "status", resp.StatusCode)
-			consecutiveSuccesses = 0
-			consecutiveFailures++
-			if consecutiveFailures >= failureThreshold {
-				return false, fmt.Errorf("health check failed after %d consecutive failures: status code %d", consecutiveFailures, resp.StatusCode)
-			}
-			return false, nil
+// probeHealth probes the backends and the router health URLs concurrently
+// within timeout, and returns the status of each backend and the error of
+// each router probe. Routers without a health URL are not probed.
+func probeHealth(ctx context.Context, backendURLs, routerURLs []string, timeout time.Duration) ([]productionstackv1alpha1.BackendStatus, []error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var backends []productionstackv1alpha1.BackendStatus
+	var routerErrs []error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		backends = probeBackends(ctx, backendURLs, timeout)
+	}()
+	go func() {
+		defer wg.Done()
+		routerErrs = probeRouters(ctx, routerURLs, timeout)
+	}()
+	wg.Wait()
+	return backends, routerErrs
+}
+
+// recordBackendsHealth records the probed backends and the BackendsHealthy
+// condition in the status and an event for each backend turning unhealthy or
+// recovering. A backend not probed in time keeps its last result.
+func (r *StaticRouteReconciler) recordBackendsHealth(staticRoute *productionstackv1alpha1.StaticRoute, backends []productionstackv1alpha1.BackendStatus) {
+	previous := make(map[string]productionstackv1alpha1.BackendStatus, len(staticRoute.Status.Backends))
+	for _, b := range staticRoute.Status.Backends {
+		previous[b.URL] = b
+	}
+	var unhealthy []string
+	for i, b := range backends {
+		last, known := previous[b.URL]
+		switch {
+		case b.LastProbeTime.IsZero() && known:
+			backends[i] = last
+		case b.LastProbeTime.IsZero():
+			backends[i].Message = "not probed yet"
+		case !b.Healthy && (!known || last.Healthy):
+			r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "BackendUnhealthy", "Backend %s is unhealthy: %s", b.URL, b.Message)
+		case b.Healthy && known && !last.Healthy:
+			r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "BackendRecovered", "Backend %s is healthy again", b.URL)
 		}
+		if !backends[i].Healthy {
+			unhealthy = append(unhealthy, b.URL)
+		}
+	}
 
-		logger.Info("Health endpoint check successful", "url", healthURL)
-		consecutiveFailures = 0
-		consecutiveSuccesses++
-		return consecutiveSuccesses >= successThreshold, nil
-	})
+	condition := metav1.Condition{
+		Type:    conditionBackendsHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AllBackendsHealthy",
+		Message: fmt.Sprintf("All %d backends are healthy", len(backends)),
+	}
+	if len(unhealthy) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BackendsUnhealthy"
+		condition.Message = fmt.Sprintf("%d of %d backends are unhealthy: %s", len(unhealthy), len(backends), strings.Join(unhealthy, ", "))
+	}
+	meta.SetStatusCondition(&staticRoute.Status.Conditions, condition)
+	staticRoute.Status.Backends = backends
+}
+
+// probeBackends probes the backends, at most maxConcurrentBackendProbes at a
+// time and all of them within timeout, and returns their status in the order
+// of urls. A backend not probed before the deadline has no LastProbeTime.
+func probeBackends(ctx context.Context, urls []string, timeout time.Duration) []productionstackv1alpha1.BackendStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
+	httpClient := &http.Client{Timeout: timeout}
+	backends := make([]productionstackv1alpha1.BackendStatus, len(urls))
+	sem := make(chan struct{}, maxConcurrentBackendProbes)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		backends[i].URL = url
+		if ctx.Err() != nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			backends[i] = probeBackend(ctx, httpClient, url)
+		}()
+	}
+	wg.Wait()
+	return backends
+}
+
+// probeBackend checks the /health endpoint of a backend, or /v1/models for
+// backends without one
+func probeBackend(ctx context.Context, httpClient *http.Client, url string) productionstackv1alpha1.BackendStatus {
+	backend := productionstackv1alpha1.BackendStatus{URL: url, LastProbeTime: metav1.Now()}
+	base := strings.TrimSuffix(url, "/")
+	status, err := probeStatus(ctx, httpClient, base+"/health")
+	if err == nil && status == http.StatusNotFound {
+		status, err = probeStatus(ctx, httpClient, base+"/v1/models")
+	}
+	switch {
+	case err != nil:
+		backend.Message = err.Error()
+	case status != http.StatusOK:
+		backend.Message = fmt.Sprintf("probe returned status %d", status)
+	default:
+		backend.Healthy = true
+	}
+	return backend
+}
+
+// probeStatus returns the status code of a GET request to url
+func probeStatus(ctx context.Context, httpClient *http.Client, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		logger.Error(err, "Health check failed after retries", "url", healthURL)
-		// Update the status condition
-		condition := metav1.Condition{
-			Type:               "HealthCheckFailed",
-			Status:             metav1.ConditionTrue,
-			LastTransitionTime: metav1.Now(),
-			Reason:             "HealthCheckFailed",
-			Message:            fmt.Sprintf("Health check failed for service %s: %v", service.Name, err),
-		}
-
-		// Initialize conditions if nil
-		if staticRoute.Status.Conditions == nil {
-			staticRoute.Status.Conditions = []metav1.Condition{}
-		}
-
-		// Find and update or append the condition
-		found := false
-		for i, c := range staticRoute.Status.Conditions {
-			if c.Type == condition.Type {
-				staticRoute.Status.Conditions[i] = condition
-				found = true
-				break
-			}
+		return 0, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// routerHealthURLs returns the health URL of each router of the status, ""
+// for a router service without an http port
+func (r *StaticRouteReconciler) routerHealthURLs(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	healthURLs := make([]string, len(staticRoute.Status.Routers))
+	for i, router := range staticRoute.Status.Routers {
+		service, err := r.getRouterService(ctx, types.NamespacedName{Namespace: router.Namespace, Name: router.Name})
+		if err != nil {
+			return nil, err
 		}
-		if !found {
-			staticRoute.Status.Conditions = append(staticRoute.Status.Conditions, condition)
+		if baseURL := routerServiceURL(service); baseURL != "" {
+			healthURLs[i] = baseURL + "/health"
+		} else {
+			logger.Info("No suitable port found for service", "namespace", service.Namespace, "name", service.Name)
 		}
+	}
+	return healthURLs, nil
+}
 
-		if err := r.Status().Update(ctx, staticRoute); err != nil {
-			logger.Error(err, "Failed to update StaticRoute status")
-			return err
+// probeRouters probes the health URLs concurrently within timeout and returns
+// the error of each probe, skipping the empty URLs
+func probeRouters(ctx context.Context, healthURLs []string, timeout time.Duration) []error {
+	httpClient := &http.Client{Timeout: timeout}
+	probeErrs := make([]error, len(healthURLs))
+	var wg sync.WaitGroup
+	for i, healthURL := range healthURLs {
+		if healthURL == "" {
+			continue
 		}
-		return fmt.Errorf("health check failed for service %s: %w", service.Name, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := probeStatus(ctx, httpClient, healthURL)
+			if err == nil && status != http.StatusOK {
+				err = fmt.Errorf("status code %d", status)
+			}
+			probeErrs[i] = err
+		}()
 	}
+	wg.Wait()
+	return probeErrs
+}
 
-	// Update the status condition
-	condition := metav1.Condition{
-		Type:               "HealthCheckSucceeded",
-		Status:             metav1.ConditionTrue,
-		LastTransitionTime: metav1.Now(),
-		Reason:             "HealthCheckSucceeded",
-		Message:            fmt.Sprintf("Health check succeeded for service %s", service.Name),
+// recordRouterHealth counts the probe results of the routers in the status.
+// The HealthCheckSucceeded and HealthCheckFailed conditions flip once the
+// successThreshold or failureThreshold is crossed, the reconcile is requeued
+// after periodSeconds for the next probe. It reports whether a flip is
+// pending.
+func (r *StaticRouteReconciler) recordRouterHealth(ctx context.Context, staticRoute *productionstackv1alpha1.StaticRoute, healthURLs []string, probeErrs []error) bool {
+	logger := log.FromContext(ctx)
+
+	if len(staticRoute.Status.Routers) == 0 {
+		logger.Info("No router reference provided")
+		return false
+	}
+
+	// Get health check configuration with defaults
+	healthCheck := healthCheckConfig(staticRoute.Spec.HealthCheck)
+
+	pending := false
+	for i, healthURL := range healthURLs {
+		if healthURL == "" {
+			continue
+		}
+		if probeErrs[i] != nil {
+			logger.Info("Router health check failed", "url", healthURL, "error", probeErrs[i].Error())
+		}
+		router := &staticRoute.Status.Routers[i]
+		if router.Health == nil {
+			router.Health = &productionstackv1alpha1.RouterHealthStatus{}
+		}
+		state := router.Health.State
+		if recordRouterProbe(router.Health, probeErrs[i], healthCheck.SuccessThreshold, healthCheck.FailureThreshold) {
+			pending = true
+		}
+		switch {
+		case router.Health.State == routerUnhealthy && state != routerUnhealthy:
+			r.Record.Eventf(staticRoute, corev1.EventTypeWarning, "RouterUnhealthy", "Router %s/%s failed %d consecutive health checks: %s",
+				router.Namespace, router.Name, router.Health.ConsecutiveFailures, router.Health.Message)
+		case router.Health.State == routerHealthy && state == routerUnhealthy:
+			r.Record.Eventf(staticRoute, corev1.EventTypeNormal, "RouterRecovered", "Router %s/%s is healthy again", router.Namespace, router.Name)
+		}
 	}
+	setRouterHealthConditions(&staticRoute.Status)
+	return pending
+}
+
+// recordRouterProbe counts the result of a router health probe and sets the
+// state of the router once a threshold is crossed. It reports whether the
+// probe disagrees with the state, i.e. a flip is pending.
+func recordRouterProbe(health *productionstackv1alpha1.RouterHealthStatus, probeErr error, successThreshold, failureThreshold int32) bool {
+	now := metav1.Now()
+	health.LastProbeTime = &now
 
-	// Initialize conditions if nil
-	if staticRoute.Status.Conditions == nil {
-		staticRoute.Status.Conditions = []metav1.Condition{}
+	if probeErr != nil {
+		health.ConsecutiveSuccesses = 0
+		health.ConsecutiveFailures++
+		health.Message = probeErr.Error()
+		if health.ConsecutiveFailures >= failureThreshold {
+			health.State = routerUnhealthy
+		}
+		return health.State != routerUnhealthy
 	}
 
-	// Find and update or append the condition
-	found := false
-	for i, c := range staticRoute.Status.Conditions {
-		if c.Type == condition.Type {
-			staticRoute.Status.Conditions[i] = condition
-			found = true
-			break
+	health.ConsecutiveFailures = 0
+	health.ConsecutiveSuccesses++
+	health.Message = ""
+	if health.ConsecutiveSuccesses >= successThreshold {
+		health.State = routerHealthy
+	}
+	return health.State != routerHealthy
+}
+
+// setRouterHealthConditions sets HealthCheckFailed if a router is unhealthy
+// and HealthCheckSucceeded if every router is healthy, and the other condition
+// to false. The conditions are left as they are while a router is undecided.
+func setRouterHealthConditions(status *productionstackv1alpha1.StaticRouteStatus) {
+	var unhealthy, undecided []string
+	for _, router := range status.Routers {
+		switch {
+		case router.Health == nil || router.Health.State == "":
+			undecided = append(undecided, router.Name)
+		case router.Health.State == routerUnhealthy:
+			unhealthy = append(unhealthy, fmt.Sprintf("%s after %d consecutive failures: %s",
+				router.Name, router.Health.ConsecutiveFailures, router.Health.Message))
 		}
 	}
-	if !found {
-		staticRoute.Status.Conditions = append(staticRoute.Status.Conditions, condition)
+
+	conditionType, other := conditionHealthCheckSucceeded, conditionHealthCheckFailed
+	reason := "HealthCheckSucceeded"
+	message := fmt.Sprintf("Health check succeeded for %d router service(s)", len(status.Routers))
+	switch {
+	case len(unhealthy) > 0:
+		conditionType, other = conditionHealthCheckFailed, conditionHealthCheckSucceeded
+		reason = "HealthCheckFailed"
+		message = fmt.Sprintf("Health check failed for service %s", strings.Join(unhealthy, "; "))
+	case len(undecided) > 0:
+		return
 	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    other,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
 
-	if err = r.Status().Update(ctx, staticRoute); err != nil {
-		logger.Error(err, "Failed to update StaticRoute status")
-		return err
+// setReadyCondition sets the Ready condition, true once the ConfigMap holds
+// the valid spec, the dynamic config was pushed to every router and the router
+// health check passed
+func setReadyCondition(staticRoute *productionstackv1alpha1.StaticRoute) {
+	conditions := staticRoute.Status.Conditions
+	hasRouters := len(staticRoute.Status.Routers) > 0
+	condition := metav1.Condition{
+		Type:               conditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: staticRoute.Generation,
+	}
+	switch {
+	case meta.IsStatusConditionTrue(conditions, conditionSpecInvalid):
+		condition.Reason = "SpecInvalid"
+		condition.Message = "The spec is invalid, the ConfigMap holds the last valid configuration"
+	case meta.IsStatusConditionTrue(conditions, conditionAggregationConflict):
+		condition.Reason = "AggregationConflict"
+		condition.Message = "The backends conflict with other aggregated StaticRoutes of a router"
+	case meta.IsStatusConditionTrue(conditions, conditionConfigPushFailed):
+		condition.Reason = "ConfigPushFailed"
+		condition.Message = "The dynamic config is not applied to every router"
+	case hasRouters && meta.IsStatusConditionTrue(conditions, conditionHealthCheckFailed):
+		condition.Reason = "HealthCheckFailed"
+		condition.Message = "A router failed its health check"
+	case hasRouters && !meta.IsStatusConditionTrue(conditions, conditionHealthCheckSucceeded):
+		condition.Reason = "HealthCheckPending"
+		condition.Message = "Waiting for the routers to pass the health check"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Ready"
+		condition.Message = "The dynamic config is applied"
 	}
+	meta.SetStatusCondition(&staticRoute.Status.Conditions, condition)
+}
 
-	return nil
+// findStaticRoutesForService returns the StaticRoutes referencing or
+// selecting the router service
+func (r *StaticRouteReconciler) findStaticRoutesForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	staticRoutes := &productionstackv1alpha1.StaticRouteList{}
+	if err := r.List(ctx, staticRoutes); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list StaticRoutes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, staticRoute := range staticRoutes.Items {
+		if selectsRouterService(&staticRoute, obj) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: staticRoute.Namespace, Name: staticRoute.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// selectsRouterService reports whether the StaticRoute references or selects
+// the service
+func selectsRouterService(staticRoute *productionstackv1alpha1.StaticRoute, service client.Object) bool {
+	if ref := staticRoute.Spec.RouterRef; ref != nil {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = staticRoute.Namespace
+		}
+		return ref.Name == service.GetName() && namespace == service.GetNamespace()
+	}
+	if staticRoute.Spec.RouterSelector == nil {
+		return false
+	}
+	namespaces := staticRoute.Spec.RouterNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{staticRoute.Namespace}
+	}
+	if !slices.Contains(namespaces, service.GetNamespace()) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(staticRoute.Spec.RouterSelector)
+	return err == nil && selector.Matches(labels.Set(service.GetLabels()))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *StaticRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&productionstackv1alpha1.StaticRoute{}).
+		// Status updates, e.g. of a failed push, must not bypass the backoff
+		For(&productionstackv1alpha1.StaticRoute{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&corev1.ConfigMap{}).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.findStaticRoutesForService)).
+		// An edit of an aggregated StaticRoute changes the merged config of its peers
+		Watches(&productionstackv1alpha1.StaticRoute{}, handler.EnqueueRequestsFromMapFunc(r.findAggregatedPeers),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
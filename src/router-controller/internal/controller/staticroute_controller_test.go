@@ -17,11 +17,23 @@ package controller
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,6 +80,7 @@ var _ = Describe("StaticRoute Controller", func() {
 			controllerReconciler := &StaticRouteReconciler{
 				Client: k8sClient,
 				Scheme: k8sClient.Scheme(),
+				Record: record.NewFakeRecorder(10),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -76,4 +89,834 @@ var _ = Describe("StaticRoute Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Context("When pushing the dynamic config", func() {
+		ctx := context.Background()
+
+		newStaticRoute := func(name string) *productionstackv1alpha1.StaticRoute {
+			return &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1",
+					StaticModels:     "facebook/opt-125m",
+				},
+			}
+		}
+
+		It("should POST the config and report the status of the router", func() {
+			var body, authorization string
+			status := http.StatusOK
+			router := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal("/dynamic_config"))
+				data, _ := io.ReadAll(req.Body)
+				body = string(data)
+				authorization = req.Header.Get("Authorization")
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte("invalid config"))
+			}))
+			defer router.Close()
+
+			Expect(postDynamicConfig(ctx, router.URL+"/dynamic_config", `{"routing_logic":"roundrobin"}`, "s3cr3t", time.Second)).To(Succeed())
+			Expect(body).To(Equal(`{"routing_logic":"roundrobin"}`))
+			Expect(authorization).To(Equal("Bearer s3cr3t"))
+
+			status = http.StatusBadRequest
+			err := postDynamicConfig(ctx, router.URL+"/dynamic_config", "{}", "", time.Second)
+			Expect(err).To(MatchError(ContainSubstring("router returned status 400: invalid config")))
+			Expect(authorization).To(BeEmpty())
+		})
+
+		It("should read the token of the pushes from the Secret", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "router-token", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("s3cr3t\n")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			})
+
+			controllerReconciler := &StaticRouteReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			ref := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "router-token"}, Key: "token"}
+			Expect(controllerReconciler.configPushToken(ctx, "default", ref)).To(Equal("s3cr3t"))
+
+			ref.Key = "missing"
+			_, err := controllerReconciler.configPushToken(ctx, "default", ref)
+			Expect(err).To(MatchError("token Secret default/router-token has no key missing"))
+
+			ref.Name = "missing-token"
+			_, err = controllerReconciler.configPushToken(ctx, "default", ref)
+			Expect(err).To(MatchError(ContainSubstring("failed to get the token Secret default/missing-token")))
+		})
+
+		It("should report a failed push and retry it", func() {
+			staticRoute := newStaticRoute("push-failed-route")
+			staticRoute.Spec.RouterRef = &corev1.ObjectReference{Name: "missing-router"}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			for i := range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).To(MatchError(ContainSubstring("router service default/missing-router not found")))
+				if i == 0 {
+					Expect(recorder.Events).To(Receive(Equal("Normal ConfigMapCreated Created ConfigMap default/push-failed-route-config")))
+				}
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning ConfigPushFailed")))
+				if i == 0 {
+					// The backends are probed despite the failed push
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning BackendUnhealthy Backend http://127.0.0.1:1 is unhealthy")))
+				}
+			}
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionConfigPushFailed)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(staticRoute.Status.Conditions, conditionBackendsHealthy)).To(BeTrue())
+			Expect(staticRoute.Status.LastPushResult).To(HavePrefix("Failed: "))
+			Expect(staticRoute.Status.LastPushTime).NotTo(BeNil())
+			Expect(staticRoute.Status.LastAppliedTime).To(BeNil())
+			Expect(staticRoute.Status.AppliedConfigHash).To(BeEmpty())
+			Expect(staticRoute.Status.ConfigMapRef).To(Equal("push-failed-route-config"))
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionReady).Reason).To(Equal("ConfigPushFailed"))
+			Expect(staticRoute.Status.ObservedGeneration).To(BeZero())
+		})
+
+		It("should apply the config with the ConfigMap when the push is disabled", func() {
+			staticRoute := newStaticRoute("push-disabled-route")
+			staticRoute.Spec.RouterRef = &corev1.ObjectReference{Name: "missing-router"}
+			staticRoute.Spec.ConfigPush = &productionstackv1alpha1.ConfigPushConfig{Disabled: true}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			// The health check of the missing router still fails
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("router service default/missing-router not found")))
+			Expect(recorder.Events).To(Receive(Equal("Normal ConfigMapCreated Created ConfigMap default/push-disabled-route-config")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning BackendUnhealthy Backend http://127.0.0.1:1 is unhealthy")))
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionConfigPushFailed)).To(BeNil())
+			Expect(staticRoute.Status.LastAppliedTime).NotTo(BeNil())
+			Expect(staticRoute.Status.AppliedConfigHash).NotTo(BeEmpty())
+			Expect(staticRoute.Status.LastPushTime).To(BeNil())
+
+			By("changing the health check")
+			hash := staticRoute.Status.AppliedConfigHash
+			staticRoute.Spec.HealthCheck = &productionstackv1alpha1.HealthCheckConfig{FailureThreshold: 5}
+			Expect(k8sClient.Update(ctx, staticRoute)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("router service default/missing-router not found")))
+			Expect(recorder.Events).To(Receive(Equal("Normal ConfigMapUpdated Updated ConfigMap default/push-disabled-route-config: added health_check")))
+			Expect(recorder.Events).NotTo(Receive())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: staticRoute.Status.ConfigMapRef, Namespace: "default"}, configMap)).To(Succeed())
+			Expect(configMap.Data[dynamicConfigKey]).To(ContainSubstring(`"failure_threshold":5`))
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.AppliedConfigHash).NotTo(Equal(hash))
+		})
+	})
+
+	Context("When probing the static backends", func() {
+		ctx := context.Background()
+
+		It("should report the health of each backend", func() {
+			healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Path).To(Equal("/health"))
+			}))
+			defer healthy.Close()
+			// A backend without /health is probed with /v1/models
+			modelsOnly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/v1/models" {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer modelsOnly.Close()
+			unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer unhealthy.Close()
+
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "backends-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   healthy.URL + ", " + modelsOnly.URL + "," + unhealthy.URL,
+					StaticModels:     "facebook/opt-125m,facebook/opt-125m,facebook/opt-125m",
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal ConfigMapCreated Created ConfigMap default/backends-route-config")))
+			Expect(recorder.Events).To(Receive(Equal("Warning BackendUnhealthy Backend " + unhealthy.URL + " is unhealthy: probe returned status 503")))
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.Backends).To(HaveLen(3))
+			for i, url := range []string{healthy.URL, modelsOnly.URL, unhealthy.URL} {
+				Expect(staticRoute.Status.Backends[i].URL).To(Equal(url))
+				Expect(staticRoute.Status.Backends[i].LastProbeTime.IsZero()).To(BeFalse())
+			}
+			Expect(staticRoute.Status.Backends[0].Healthy).To(BeTrue())
+			Expect(staticRoute.Status.Backends[1].Healthy).To(BeTrue())
+			Expect(staticRoute.Status.Backends[2].Healthy).To(BeFalse())
+			Expect(staticRoute.Status.Backends[2].Message).To(Equal("probe returned status 503"))
+			condition := meta.FindStatusCondition(staticRoute.Status.Conditions, conditionBackendsHealthy)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(Equal("1 of 3 backends are unhealthy: " + unhealthy.URL))
+
+			By("not reporting the unhealthy backend again")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+
+			By("dropping the unhealthy backend")
+			staticRoute.Spec.StaticBackends = healthy.URL + "," + modelsOnly.URL
+			staticRoute.Spec.StaticModels = "facebook/opt-125m,facebook/opt-125m"
+			Expect(k8sClient.Update(ctx, staticRoute)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal ConfigMapUpdated Updated ConfigMap default/backends-route-config: changed static_backends, static_models")))
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.Backends).To(HaveLen(2))
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionBackendsHealthy)).To(BeTrue())
+
+			// Without routers the ConfigMap applies the config
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionReady)).To(BeTrue())
+			Expect(staticRoute.Status.ObservedGeneration).To(Equal(staticRoute.Generation))
+		})
+
+		It("should probe the backends concurrently", func() {
+			var inFlight, maxInFlight atomic.Int32
+			slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				n := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					m := maxInFlight.Load()
+					if n <= m || maxInFlight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Millisecond)
+			}))
+			defer slow.Close()
+
+			urls := make([]string, 50)
+			for i := range urls {
+				urls[i] = slow.URL + "/" + strings.Repeat("b", i+1)
+			}
+			start := time.Now()
+			backends := probeBackends(ctx, urls, time.Second)
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+			Expect(backends).To(HaveLen(50))
+			for i, b := range backends {
+				Expect(b.URL).To(Equal(urls[i]))
+				Expect(b.Healthy).To(BeTrue())
+			}
+			Expect(maxInFlight.Load()).To(BeNumerically("<=", maxConcurrentBackendProbes))
+			Expect(maxInFlight.Load()).To(BeNumerically(">", 1))
+		})
+
+		It("should probe the backends within a single timeout", func() {
+			hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				select {
+				case <-req.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			}))
+			defer hanging.Close()
+
+			urls := make([]string, 2*maxConcurrentBackendProbes)
+			for i := range urls {
+				urls[i] = hanging.URL + "/" + strings.Repeat("b", i+1)
+			}
+			start := time.Now()
+			backends := probeBackends(ctx, urls, 200*time.Millisecond)
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(backends).To(HaveLen(len(urls)))
+			for i, b := range backends {
+				Expect(b.URL).To(Equal(urls[i]))
+				Expect(b.Healthy).To(BeFalse())
+				// The backends waiting for a probe slot are not probed
+				Expect(b.LastProbeTime.IsZero()).To(Equal(i >= maxConcurrentBackendProbes))
+			}
+		})
+	})
+
+	Context("When checking the router health", func() {
+		ctx := context.Background()
+
+		It("should probe the backends and the routers within a single timeout", func() {
+			hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				select {
+				case <-req.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			}))
+			defer hanging.Close()
+
+			start := time.Now()
+			backends, routerErrs := probeHealth(ctx, []string{hanging.URL + "/backend"}, []string{hanging.URL + "/health", ""}, 300*time.Millisecond)
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+			Expect(backends).To(HaveLen(1))
+			Expect(backends[0].Healthy).To(BeFalse())
+			Expect(backends[0].LastProbeTime.IsZero()).To(BeFalse())
+			Expect(routerErrs).To(HaveLen(2))
+			Expect(routerErrs[0]).To(HaveOccurred())
+			// A router without a health URL is not probed
+			Expect(routerErrs[1]).NotTo(HaveOccurred())
+		})
+
+		It("should take a single probe per reconcile", func() {
+			// The cluster IP is not served, each probe runs into its timeout
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "unreachable-router", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.0.0.10",
+					Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+			})
+
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unreachable-router-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1",
+					StaticModels:     "facebook/opt-125m",
+					RouterRef:        &corev1.ObjectReference{Name: service.Name},
+					ConfigPush:       &productionstackv1alpha1.ConfigPushConfig{Disabled: true},
+					HealthCheck: &productionstackv1alpha1.HealthCheckConfig{
+						TimeoutSeconds:   1,
+						PeriodSeconds:    60,
+						SuccessThreshold: 1,
+						FailureThreshold: 2,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			By("not flipping the conditions before the failureThreshold")
+			start := time.Now()
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+			Expect(result.RequeueAfter).To(Equal(60 * time.Second))
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.Routers).To(HaveLen(1))
+			Expect(staticRoute.Status.Routers[0].Health.ConsecutiveFailures).To(Equal(int32(1)))
+			Expect(staticRoute.Status.Routers[0].Health.LastProbeTime).NotTo(BeNil())
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionHealthCheckFailed)).To(BeNil())
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionReady).Reason).To(Equal("HealthCheckPending"))
+			Expect(staticRoute.Status.ObservedGeneration).To(Equal(staticRoute.Generation))
+
+			By("flipping the conditions once the failureThreshold is crossed")
+			start = time.Now()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.Routers[0].Health.ConsecutiveFailures).To(Equal(int32(2)))
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionHealthCheckFailed)).To(BeTrue())
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionReady).Reason).To(Equal("HealthCheckFailed"))
+
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal ConfigMapCreated")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning BackendUnhealthy")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning RouterUnhealthy Router default/unreachable-router failed 2 consecutive health checks: ")))
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should flip the state of a router once a threshold is crossed", func() {
+			health := &productionstackv1alpha1.RouterHealthStatus{}
+			probeErr := goerrors.New("connection refused")
+
+			Expect(recordRouterProbe(health, nil, 2, 3)).To(BeTrue())
+			Expect(health.State).To(BeEmpty())
+			Expect(recordRouterProbe(health, nil, 2, 3)).To(BeFalse())
+			Expect(health.State).To(Equal(routerHealthy))
+			Expect(health.ConsecutiveSuccesses).To(Equal(int32(2)))
+
+			// A success resets the failures
+			Expect(recordRouterProbe(health, probeErr, 2, 3)).To(BeTrue())
+			Expect(recordRouterProbe(health, probeErr, 2, 3)).To(BeTrue())
+			Expect(recordRouterProbe(health, nil, 2, 3)).To(BeFalse())
+			Expect(health.ConsecutiveFailures).To(BeZero())
+			Expect(health.State).To(Equal(routerHealthy))
+
+			for range 2 {
+				Expect(recordRouterProbe(health, probeErr, 2, 3)).To(BeTrue())
+			}
+			Expect(recordRouterProbe(health, probeErr, 2, 3)).To(BeFalse())
+			Expect(health.ConsecutiveFailures).To(Equal(int32(3)))
+			Expect(health.Message).To(Equal("connection refused"))
+			Expect(health.State).To(Equal(routerUnhealthy))
+		})
+
+		It("should fail the health check if any router is unhealthy", func() {
+			status := &productionstackv1alpha1.StaticRouteStatus{
+				Routers: []productionstackv1alpha1.RouterStatus{
+					{Namespace: "a", Name: "router", Health: &productionstackv1alpha1.RouterHealthStatus{State: routerHealthy}},
+					{Namespace: "b", Name: "router"},
+				},
+			}
+
+			// An undecided router leaves the conditions as they are
+			setRouterHealthConditions(status)
+			Expect(status.Conditions).To(BeEmpty())
+
+			status.Routers[1].Health = &productionstackv1alpha1.RouterHealthStatus{State: routerHealthy}
+			setRouterHealthConditions(status)
+			Expect(meta.IsStatusConditionTrue(status.Conditions, conditionHealthCheckSucceeded)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(status.Conditions, conditionHealthCheckFailed)).To(BeFalse())
+
+			status.Routers[1].Health = &productionstackv1alpha1.RouterHealthStatus{State: routerUnhealthy, ConsecutiveFailures: 3, Message: "connection refused"}
+			setRouterHealthConditions(status)
+			Expect(meta.IsStatusConditionTrue(status.Conditions, conditionHealthCheckSucceeded)).To(BeFalse())
+			condition := meta.FindStatusCondition(status.Conditions, conditionHealthCheckFailed)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(Equal("Health check failed for service router after 3 consecutive failures: connection refused"))
+		})
+	})
+
+	Context("When selecting the routers by label", func() {
+		ctx := context.Background()
+
+		It("should push the config to each selected router", func() {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "routers-b"}}
+			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+			// The cluster IPs are not served, the pushes fail
+			newService := func(namespace, name, role, clusterIP string) *corev1.Service {
+				service := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+						Labels:    map[string]string{"app": "vllm-router", "role": role},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: clusterIP,
+						Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, service)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+				})
+				return service
+			}
+			newService("default", "router-a", "primary", "10.0.0.11")
+			newService("routers-b", "router-b", "standby", "10.0.0.12")
+			newService("default", "router-canary", "canary", "10.0.0.13")
+
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ha-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1",
+					StaticModels:     "facebook/opt-125m",
+					RouterSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "vllm-router"},
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "role", Operator: metav1.LabelSelectorOpIn, Values: []string{"primary", "standby"}},
+						},
+					},
+					RouterNamespaces: []string{"default", "routers-b"},
+					ConfigPush:       &productionstackv1alpha1.ConfigPushConfig{TimeoutSeconds: 1},
+					HealthCheck:      &productionstackv1alpha1.HealthCheckConfig{TimeoutSeconds: 1},
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(And(ContainSubstring("default/router-a: "), ContainSubstring("routers-b/router-b: "))))
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal ConfigMapCreated")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning ConfigPushFailed")))
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.Routers).To(HaveLen(2))
+			for i, name := range []string{"default/router-a", "routers-b/router-b"} {
+				router := staticRoute.Status.Routers[i]
+				Expect(router.Namespace + "/" + router.Name).To(Equal(name))
+				Expect(router.LastPushResult).To(HavePrefix("Failed: "))
+				Expect(router.LastPushTime).NotTo(BeNil())
+				// The routers are probed despite the failed push
+				Expect(router.Health.ConsecutiveFailures).To(Equal(int32(1)))
+			}
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionConfigPushFailed)).To(BeTrue())
+
+			By("mapping the services to the StaticRoute")
+			for _, service := range []struct{ namespace, name, role string }{
+				{"default", "router-a", "primary"},
+				{"routers-b", "router-b", "standby"},
+				{"default", "router-canary", "canary"},
+			} {
+				obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+					Name:      service.name,
+					Namespace: service.namespace,
+					Labels:    map[string]string{"app": "vllm-router", "role": service.role},
+				}}
+				requests := controllerReconciler.findStaticRoutesForService(ctx, obj)
+				if service.role == "canary" {
+					Expect(requests).To(BeEmpty())
+				} else {
+					Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+				}
+			}
+		})
+	})
+
+	Context("When deleting a StaticRoute", func() {
+		ctx := context.Background()
+
+		It("should retry the withdrawal and delete it after the last attempt", func() {
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "withdrawn-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1",
+					StaticModels:     "facebook/opt-125m",
+					RouterRef:        &corev1.ObjectReference{Name: "withdrawn-router"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(20)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("router service default/withdrawn-router not found")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal ConfigMapCreated")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning ConfigPushFailed")))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning BackendUnhealthy")))
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Finalizers).To(ConsistOf(withdrawRoutesFinalizer))
+
+			By("deleting it once the router went away")
+			staticRoute.Status.Routers[0].AppliedConfigHash = "applied"
+			Expect(k8sClient.Status().Update(ctx, staticRoute)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+
+			for attempt := 1; attempt < maxWithdrawAttempts; attempt++ {
+				result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(withdrawRetryInterval))
+				Expect(recorder.Events).To(Receive(HavePrefix(fmt.Sprintf(
+					"Warning WithdrawFailed Failed to withdraw the routes (attempt %d of %d): default/withdrawn-router: router service default/withdrawn-router not found",
+					attempt, maxWithdrawAttempts))))
+			}
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(staticRoute.Status.WithdrawAttempts).To(Equal(int32(maxWithdrawAttempts - 1)))
+			Expect(staticRoute.Status.Routers[0].LastPushResult).To(HavePrefix("Failed: "))
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning WithdrawSkipped Deleting without withdrawing the routes after 5 attempts: ")))
+			Expect(recorder.Events).NotTo(Receive())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, staticRoute))).To(BeTrue())
+		})
+
+		It("should not hold the deletion of a StaticRoute without pushed routes", func() {
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unpushed-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1",
+					StaticModels:     "facebook/opt-125m",
+					RouterRef:        &corev1.ObjectReference{Name: "unpushed-router"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, staticRoute))).To(BeTrue())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring("Withdr")))
+		})
+	})
+
+	Context("When aggregating StaticRoutes", func() {
+		ctx := context.Background()
+
+		It("should merge the StaticRoutes of a router into its ConfigMap", func() {
+			// Without an http port the router is neither pushed to nor probed
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-router", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "metrics", Port: 9090}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(50)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			var keys []types.NamespacedName
+			for _, team := range []struct{ name, url, model string }{
+				{"team-a", "http://127.0.0.1:1", "facebook/opt-125m"},
+				{"team-b", "http://127.0.0.1:2", "meta-llama/Llama-3.1-8B-Instruct"},
+				{"team-c", "http://127.0.0.1:3", "facebook/opt-125m"},
+			} {
+				staticRoute := &productionstackv1alpha1.StaticRoute{
+					ObjectMeta: metav1.ObjectMeta{Name: team.name, Namespace: "default"},
+					Spec: productionstackv1alpha1.StaticRouteSpec{
+						ServiceDiscovery: "static",
+						RoutingLogic:     "roundrobin",
+						Backends:         []productionstackv1alpha1.StaticBackend{{URL: team.url, Model: team.model}},
+						RouterRef:        &corev1.ObjectReference{Name: service.Name},
+						ConfigPush:       &productionstackv1alpha1.ConfigPushConfig{Disabled: true},
+						Aggregate:        true,
+					},
+				}
+				Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+				keys = append(keys, types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace})
+			}
+			DeferCleanup(func() {
+				for _, key := range keys {
+					staticRoute := &productionstackv1alpha1.StaticRoute{}
+					if err := k8sClient.Get(ctx, key, staticRoute); err == nil {
+						Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+						_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+						Expect(err).NotTo(HaveOccurred())
+					}
+				}
+			})
+
+			for _, key := range keys {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			configMapKey := types.NamespacedName{Name: "shared-router-dynamic-config", Namespace: "default"}
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			Expect(configMap.Data[dynamicConfigKey]).To(ContainSubstring(`"static_backends":"http://127.0.0.1:1,http://127.0.0.1:2"`))
+			Expect(configMap.OwnerReferences).To(HaveLen(1))
+			Expect(configMap.OwnerReferences[0].Name).To(Equal(service.Name))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: "team-a-config", Namespace: "default"}, &corev1.ConfigMap{}))).To(BeTrue())
+
+			staticRoute := &productionstackv1alpha1.StaticRoute{}
+			Expect(k8sClient.Get(ctx, keys[0], staticRoute)).To(Succeed())
+			Expect(staticRoute.Finalizers).To(ConsistOf(withdrawRoutesFinalizer))
+			Expect(staticRoute.Status.ConfigMapRef).To(BeEmpty())
+			Expect(staticRoute.Status.Routers[0].ConfigMapRef).To(Equal(configMapKey.Name))
+			Expect(meta.IsStatusConditionFalse(staticRoute.Status.Conditions, conditionAggregationConflict)).To(BeTrue())
+
+			By("reporting the conflict of the newest StaticRoute")
+			Expect(k8sClient.Get(ctx, keys[2], staticRoute)).To(Succeed())
+			condition := meta.FindStatusCondition(staticRoute.Status.Conditions, conditionAggregationConflict)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(Equal("default/shared-router: model facebook/opt-125m is served by other backends of default/team-a"))
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionReady).Reason).To(Equal("AggregationConflict"))
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElement(HavePrefix("Warning AggregationConflict The backends are left out of the dynamic config: default/shared-router: ")))
+			// The first reconcile merged every StaticRoute already
+			Expect(events).To(ContainElement("Normal ConfigMapCreated Created ConfigMap default/shared-router-dynamic-config"))
+			Expect(events).NotTo(ContainElement(HavePrefix("Normal ConfigMapUpdated")))
+
+			By("mapping an edit to the peers")
+			Expect(controllerReconciler.findAggregatedPeers(ctx, staticRoute)).To(ConsistOf(
+				reconcile.Request{NamespacedName: keys[0]},
+				reconcile.Request{NamespacedName: keys[1]},
+			))
+
+			By("withdrawing the backends of a deleted StaticRoute from the merged config")
+			Expect(k8sClient.Get(ctx, keys[1], staticRoute)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: keys[1]})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, keys[1], staticRoute))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			Expect(configMap.Data[dynamicConfigKey]).To(ContainSubstring(`"static_backends":"http://127.0.0.1:1"`))
+		})
+	})
+
+	Context("When the spec is invalid", func() {
+		ctx := context.Background()
+
+		It("should not write the ConfigMap and report SpecInvalid", func() {
+			staticRoute := &productionstackv1alpha1.StaticRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "invalid-route",
+					Namespace: "default",
+				},
+				Spec: productionstackv1alpha1.StaticRouteSpec{
+					ServiceDiscovery: "static",
+					RoutingLogic:     "roundrobin",
+					StaticBackends:   "http://127.0.0.1:1,http://127.0.0.1:2,http://127.0.0.1:3",
+					StaticModels:     "facebook/opt-125m,facebook/opt-125m",
+				},
+			}
+			Expect(k8sClient.Create(ctx, staticRoute)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, staticRoute)).To(Succeed())
+			})
+
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &StaticRouteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Record: recorder,
+			}
+			key := types.NamespacedName{Name: staticRoute.Name, Namespace: staticRoute.Namespace}
+			configMapKey := types.NamespacedName{Name: "invalid-route-config", Namespace: "default"}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning SpecInvalid")))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{}))).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			condition := meta.FindStatusCondition(staticRoute.Status.Conditions, conditionSpecInvalid)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("InvalidStaticBackends"))
+			Expect(condition.Message).To(ContainSubstring("must list one model for each of the 3 staticBackends"))
+			Expect(meta.FindStatusCondition(staticRoute.Status.Conditions, conditionReady).Reason).To(Equal("SpecInvalid"))
+			Expect(staticRoute.Status.ObservedGeneration).To(Equal(staticRoute.Generation))
+
+			By("fixing the models")
+			staticRoute.Spec.StaticModels = "facebook/opt-125m,facebook/opt-125m,facebook/opt-125m"
+			Expect(k8sClient.Update(ctx, staticRoute)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, key, staticRoute)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(staticRoute.Status.Conditions, conditionSpecInvalid)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(staticRoute.Status.Conditions, conditionReady)).To(BeTrue())
+			Expect(staticRoute.Status.ObservedGeneration).To(Equal(staticRoute.Generation))
+		})
+
+		It("should derive the reason from the invalid field", func() {
+			for path, reason := range map[string]string{
+				"spec.backends[0].url":  "InvalidStaticBackends",
+				"spec.staticModels[1]":  "InvalidStaticBackends",
+				"spec.routerSelector":   "InvalidRouters",
+				"spec.routerNamespaces": "InvalidRouters",
+				"spec.sessionKey":       "InvalidSessionKey",
+				"spec.configMapName":    "InvalidConfigMapName",
+				"spec.serviceDiscovery": "InvalidSpec",
+			} {
+				invalid := &specInvalidError{errs: field.ErrorList{{Type: field.ErrorTypeInvalid, Field: path}}}
+				Expect(invalid.Reason()).To(Equal(reason), path)
+			}
+		})
+	})
 })
@@ -0,0 +1,94 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var staticroutelog = logf.Log.WithName("staticroute-resource")
+
+// SetupStaticRouteWebhookWithManager registers the webhook for StaticRoute in the manager.
+func SetupStaticRouteWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&productionstackv1alpha1.StaticRoute{}).
+		WithValidator(&StaticRouteCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-production-stack-vllm-ai-v1alpha1-staticroute,mutating=false,failurePolicy=fail,sideEffects=None,groups=production-stack.vllm.ai,resources=staticroutes,verbs=create;update,versions=v1alpha1,name=vstaticroute-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// StaticRouteCustomValidator rejects StaticRoutes whose dynamic config the
+// router could not use.
+type StaticRouteCustomValidator struct{}
+
+var _ webhook.CustomValidator = &StaticRouteCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type StaticRoute.
+func (v *StaticRouteCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	staticroute, ok := obj.(*productionstackv1alpha1.StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute object but got %T", obj)
+	}
+	staticroutelog.Info("Validation for StaticRoute upon creation", "name", staticroute.GetName())
+
+	return nil, validateStaticRoute(staticroute)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type StaticRoute.
+// Updates leaving the spec unchanged, e.g. annotations or finalizers, are always allowed.
+func (v *StaticRouteCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldStaticRoute, ok := oldObj.(*productionstackv1alpha1.StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute object for the oldObj but got %T", oldObj)
+	}
+	staticroute, ok := newObj.(*productionstackv1alpha1.StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute object for the newObj but got %T", newObj)
+	}
+	staticroutelog.Info("Validation for StaticRoute upon update", "name", staticroute.GetName())
+
+	if equality.Semantic.DeepEqual(oldStaticRoute.Spec, staticroute.Spec) {
+		return nil, nil
+	}
+	return nil, validateStaticRoute(staticroute)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type StaticRoute.
+func (v *StaticRouteCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateStaticRoute returns an Invalid error listing every bad field of the StaticRoute
+func validateStaticRoute(staticroute *productionstackv1alpha1.StaticRoute) error {
+	allErrs := productionstackv1alpha1.ValidateStaticRouteSpec(&staticroute.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(productionstackv1alpha1.GroupVersion.WithKind("StaticRoute").GroupKind(), staticroute.Name, allErrs)
+}
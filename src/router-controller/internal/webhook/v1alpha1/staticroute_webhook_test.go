@@ -0,0 +1,196 @@
+/*
+Copyright 2024-2025 The vLLM Production Stack Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	productionstackv1alpha1 "github.com/vllm-project/production-stack/router-controller/api/v1alpha1"
+)
+
+var _ = Describe("StaticRoute Webhook", func() {
+	var validator StaticRouteCustomValidator
+
+	newStaticRoute := func() *productionstackv1alpha1.StaticRoute {
+		return &productionstackv1alpha1.StaticRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-route",
+				Namespace: "default",
+			},
+			Spec: productionstackv1alpha1.StaticRouteSpec{
+				ServiceDiscovery: "static",
+				RoutingLogic:     "roundrobin",
+				StaticBackends:   "http://runtime-a:8000,https://runtime-b.example.com",
+				StaticModels:     "facebook/opt-125m,meta-llama/Llama-3.1-8B-Instruct",
+			},
+		}
+	}
+
+	DescribeTable("When creating a StaticRoute",
+		func(mutate func(*productionstackv1alpha1.StaticRoute), expectedFields ...string) {
+			staticroute := newStaticRoute()
+			mutate(staticroute)
+
+			_, err := validator.ValidateCreate(ctx, staticroute)
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+		Entry("valid spec", func(*productionstackv1alpha1.StaticRoute) {}),
+		Entry("spaces around the entries", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = "http://runtime-a:8000, http://runtime-b:8000"
+			sr.Spec.StaticModels = "facebook/opt-125m, facebook/opt-125m"
+		}),
+		Entry("more backends than models", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = "http://runtime-a:8000,http://runtime-b:8000,http://runtime-c:8000"
+		}, "spec.staticModels"),
+		Entry("backends that are not absolute http URLs", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = "runtime-a:8000,grpc://runtime-b:8000"
+		}, "spec.staticBackends[0]", "spec.staticBackends[1]"),
+		Entry("empty model names", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticModels = "facebook/opt-125m, "
+		}, "spec.staticModels[1]"),
+		Entry("empty backends and models", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+		}, "spec.staticBackends[0]", "spec.staticModels[0]"),
+		Entry("structured backends", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](1), Priority: 1},
+			}
+		}),
+		Entry("structured backends with the legacy strings", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"}}
+		}, "spec.staticBackends", "spec.staticModels"),
+		Entry("structured backends that the router could not split", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000,http://runtime-c:8000", Model: "facebook/opt-125m,facebook/opt-350m"},
+				{URL: "http://runtime-d:8000", Model: " "},
+			}
+		}, "spec.backends[0].url", "spec.backends[1].url", "spec.backends[1].model", "spec.backends[2].model"),
+		Entry("structured backends listing a URL twice", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-350m"},
+			}
+		}, "spec.backends[1].url"),
+		Entry("structured backends draining a canary", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m"},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](0)},
+			}
+		}),
+		Entry("structured backends with negative weights or a model without traffic", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](-1)},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-350m", Weight: ptr.To[int32](0)},
+				{URL: "http://runtime-c:8000", Model: "facebook/opt-350m", Weight: ptr.To[int32](0)},
+				{URL: "http://runtime-d:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](1)},
+			}
+		}, "spec.backends[0].weight", "spec.backends[1].weight"),
+		Entry("structured backends sharing the traffic by weight", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.StaticBackends = ""
+			sr.Spec.StaticModels = ""
+			sr.Spec.Backends = []productionstackv1alpha1.StaticBackend{
+				{URL: "http://runtime-a:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](9)},
+				{URL: "http://runtime-b:8000", Model: "facebook/opt-125m", Weight: ptr.To[int32](1)},
+			}
+		}),
+		Entry("no routers, the ConfigMap is mounted into the router", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterRef = nil
+			sr.Spec.RouterSelector = nil
+		}),
+		Entry("routers selected by label", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "vllm-router"}}
+			sr.Spec.RouterNamespaces = []string{"routers-a", "routers-b"}
+		}),
+		Entry("routerRef and routerSelector", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterRef = &corev1.ObjectReference{Name: "vllm-router"}
+			sr.Spec.RouterSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "vllm-router"}}
+		}, "spec.routerSelector"),
+		Entry("a router selector matching every service", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterSelector = &metav1.LabelSelector{}
+		}, "spec.routerSelector"),
+		Entry("an invalid router selector and namespace", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "role", Operator: metav1.LabelSelectorOpIn},
+			}}
+			sr.Spec.RouterNamespaces = []string{"Routers"}
+		}, "spec.routerSelector.matchExpressions[0].values", "spec.routerNamespaces[0]"),
+		Entry("session routing", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RoutingLogic = "session"
+			sr.Spec.SessionKey = "x-user-id"
+		}),
+		Entry("session routing without a session key", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RoutingLogic = "session"
+		}, "spec.sessionKey"),
+		Entry("routerNamespaces without routerSelector", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.RouterNamespaces = []string{"routers-a"}
+		}, "spec.routerNamespaces"),
+		Entry("aggregated routes of a router", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.Aggregate = true
+			sr.Spec.RouterRef = &corev1.ObjectReference{Name: "shared-router"}
+		}),
+		Entry("aggregated routes without a router or with a ConfigMap name", func(sr *productionstackv1alpha1.StaticRoute) {
+			sr.Spec.Aggregate = true
+			sr.Spec.ConfigMapName = "team-a-config"
+		}, "spec.routerRef", "spec.configMapName"),
+	)
+
+	Context("When updating a StaticRoute", func() {
+		It("should allow metadata changes of an existing invalid object", func() {
+			oldObj := newStaticRoute()
+			oldObj.Spec.StaticModels = "facebook/opt-125m"
+			newObj := oldObj.DeepCopy()
+			newObj.Annotations = map[string]string{"example.com/owner": "team-a"}
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+
+			newObj.Spec.StaticBackends = "http://runtime-a:8000"
+			_, err = validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+
+			newObj.Spec.StaticModels = ""
+			_, err = validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,102 @@
+import json
+import threading
+from unittest.mock import MagicMock
+
+import pytest
+
+from vllm_router.dynamic_config import DynamicConfigWatcher, DynamicRouterConfig
+from vllm_router.service_discovery import HealthCheckConfig
+
+
+def make_config(static_backends: str) -> DynamicRouterConfig:
+    return DynamicRouterConfig(
+        service_discovery="static",
+        routing_logic="roundrobin",
+        static_backends=static_backends,
+        static_models="facebook/opt-125m",
+    )
+
+
+@pytest.fixture
+def watcher(tmp_path) -> DynamicConfigWatcher:
+    # Skip __init__, which starts the watcher thread
+    watcher = DynamicConfigWatcher.__new__(DynamicConfigWatcher)
+    watcher.config_json = str(tmp_path / "dynamic_config.json")
+    watcher.current_config = make_config("http://localhost:9001")
+    watcher.file_config = None
+    watcher.lock = threading.Lock()
+    watcher.reconfigure_all = MagicMock()
+    return watcher
+
+
+def write_config(watcher: DynamicConfigWatcher, config: DynamicRouterConfig) -> None:
+    with open(watcher.config_json, "w") as f:
+        f.write(config.to_json_str())
+
+
+def watch_once(watcher: DynamicConfigWatcher) -> None:
+    def stop():
+        watcher.running = False
+
+    watcher.running = True
+    watcher._sleep_or_break = stop
+    watcher._watch_worker()
+
+
+def test_apply_config_when_config_is_current_does_not_reconfigure(
+    watcher: DynamicConfigWatcher,
+) -> None:
+    assert not watcher.apply_config(make_config("http://localhost:9001"))
+    watcher.reconfigure_all.assert_not_called()
+
+
+def test_apply_config_when_config_changed_reconfigures(
+    watcher: DynamicConfigWatcher,
+) -> None:
+    config = make_config("http://localhost:9002")
+    assert watcher.apply_config(config)
+    watcher.reconfigure_all.assert_called_once_with(config)
+    assert watcher.get_current_config() == config
+
+
+def test_watch_worker_keeps_a_pushed_config_until_the_file_changes(
+    watcher: DynamicConfigWatcher,
+) -> None:
+    write_config(watcher, make_config("http://localhost:9001"))
+    watch_once(watcher)
+    watcher.reconfigure_all.assert_not_called()
+
+    # The pushed config wins over the unchanged file
+    pushed = make_config("http://localhost:9002")
+    watcher.apply_config(pushed)
+    watch_once(watcher)
+    assert watcher.get_current_config() == pushed
+    watcher.reconfigure_all.assert_called_once_with(pushed)
+
+    # The synced file carries the pushed config
+    write_config(watcher, pushed)
+    watch_once(watcher)
+    assert watcher.reconfigure_all.call_count == 1
+
+    # A later change of the file is applied
+    changed = make_config("http://localhost:9003")
+    write_config(watcher, changed)
+    watch_once(watcher)
+    assert watcher.get_current_config() == changed
+    watcher.reconfigure_all.assert_called_with(changed)
+
+
+def test_from_dict_when_config_is_invalid_raises_valueerror() -> None:
+    with pytest.raises(ValueError):
+        DynamicRouterConfig.from_dict({"service_discovery": "static"})
+    with pytest.raises(ValueError):
+        DynamicRouterConfig.from_dict(json.loads('["static"]'))
+
+
+def test_from_dict_when_health_check_has_unknown_field_raises_valueerror() -> None:
+    config = json.loads(make_config("http://localhost:9001").to_json_str())
+    assert DynamicRouterConfig.from_dict(
+        {**config, "health_check": {"period_seconds": 30}}
+    ).static_health_check() == HealthCheckConfig(period_seconds=30)
+    with pytest.raises(ValueError):
+        DynamicRouterConfig.from_dict({**config, "health_check": {"interval": 30}})
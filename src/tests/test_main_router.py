@@ -0,0 +1,181 @@
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+import pytest
+
+from vllm_router.routers import main_router
+
+pytest_plugins = ("pytest_asyncio",)
+
+
+def make_request(host: str) -> SimpleNamespace:
+    return SimpleNamespace(
+        client=SimpleNamespace(host=host),
+        app=SimpleNamespace(state=SimpleNamespace(draining=False)),
+    )
+
+
+@pytest.fixture
+def healthy_components(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr(
+        main_router,
+        "get_service_discovery",
+        lambda: MagicMock(get_health=MagicMock(return_value=True)),
+    )
+    monkeypatch.setattr(
+        main_router,
+        "get_engine_stats_scraper",
+        lambda: MagicMock(get_health=MagicMock(return_value=True)),
+    )
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: None)
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize("host", ["127.0.0.1", "::1"])
+async def test_drain_when_called_from_localhost_fails_health(
+    healthy_components: None, host: str
+) -> None:
+    request = make_request(host)
+    assert (await main_router.health(request)).status_code == 200
+
+    response = await main_router.drain(request)
+    assert response.status_code == 200
+    assert request.app.state.draining
+
+    response = await main_router.health(request)
+    assert response.status_code == 503
+    assert b"draining" in response.body
+
+
+@pytest.mark.asyncio
+async def test_drain_when_called_remotely_is_forbidden(
+    healthy_components: None,
+) -> None:
+    request = make_request("10.0.0.5")
+    response = await main_router.drain(request)
+    assert response.status_code == 403
+    assert not request.app.state.draining
+    assert (await main_router.health(request)).status_code == 200
+
+
+@pytest.mark.asyncio
+async def test_drain_when_called_twice_keeps_draining(
+    healthy_components: None,
+) -> None:
+    request = make_request("127.0.0.1")
+    await main_router.drain(request)
+    response = await main_router.drain(request)
+    assert response.status_code == 200
+    assert request.app.state.draining
+
+
+DYNAMIC_CONFIG_TOKEN = "s3cr3t"
+
+
+@pytest.fixture(autouse=True)
+def dynamic_config_token(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN", DYNAMIC_CONFIG_TOKEN)
+
+
+def make_config_request(
+    config, authorization: str = f"Bearer {DYNAMIC_CONFIG_TOKEN}"
+) -> SimpleNamespace:
+    async def json():
+        return config
+
+    headers = {"Authorization": authorization} if authorization else {}
+    return SimpleNamespace(json=json, headers=headers)
+
+
+STATIC_CONFIG = {
+    "service_discovery": "static",
+    "routing_logic": "roundrobin",
+    "static_backends": "http://localhost:9001",
+    "static_models": "facebook/opt-125m",
+}
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize("authorization", ["", "Bearer wrong", DYNAMIC_CONFIG_TOKEN])
+async def test_apply_dynamic_config_when_unauthenticated_is_unauthorized(
+    monkeypatch: pytest.MonkeyPatch, authorization: str
+) -> None:
+    watcher = MagicMock()
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: watcher)
+    response = await main_router.apply_dynamic_config(
+        make_config_request(STATIC_CONFIG, authorization)
+    )
+    assert response.status_code == 401
+    watcher.apply_config.assert_not_called()
+
+
+@pytest.mark.asyncio
+async def test_apply_dynamic_config_without_token_is_forbidden(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.delenv("VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN")
+    watcher = MagicMock()
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: watcher)
+    response = await main_router.apply_dynamic_config(
+        make_config_request(STATIC_CONFIG)
+    )
+    assert response.status_code == 403
+    watcher.apply_config.assert_not_called()
+
+
+@pytest.mark.asyncio
+async def test_apply_dynamic_config_without_watcher_is_a_conflict(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: None)
+    response = await main_router.apply_dynamic_config(
+        make_config_request(STATIC_CONFIG)
+    )
+    assert response.status_code == 409
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize(
+    "config",
+    [
+        {"service_discovery": "static"},
+        {**STATIC_CONFIG, "unknown_field": 1},
+        ["static", "roundrobin"],
+    ],
+)
+async def test_apply_dynamic_config_when_config_is_invalid_is_a_bad_request(
+    monkeypatch: pytest.MonkeyPatch, config
+) -> None:
+    watcher = MagicMock()
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: watcher)
+    response = await main_router.apply_dynamic_config(make_config_request(config))
+    assert response.status_code == 400
+    watcher.apply_config.assert_not_called()
+
+
+@pytest.mark.asyncio
+async def test_apply_dynamic_config_applies_the_config(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    watcher = MagicMock()
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: watcher)
+    response = await main_router.apply_dynamic_config(
+        make_config_request(STATIC_CONFIG)
+    )
+    assert response.status_code == 200
+    watcher.apply_config.assert_called_once_with(
+        main_router.DynamicRouterConfig(**STATIC_CONFIG)
+    )
+
+
+@pytest.mark.asyncio
+async def test_apply_dynamic_config_when_reconfiguration_fails_is_an_error(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    watcher = MagicMock()
+    watcher.apply_config.side_effect = ValueError("Invalid service discovery type")
+    monkeypatch.setattr(main_router, "get_dynamic_config_watcher", lambda: watcher)
+    response = await main_router.apply_dynamic_config(
+        make_config_request(STATIC_CONFIG)
+    )
+    assert response.status_code == 500
@@ -77,3 +77,59 @@ def test_load_initial_config_from_config_json_if_required_when_config_file_is_pr
             test_parser, args
         )
         assert args.routing_logic == "roundrobin"
+
+
+STATIC_ROUTER_ARGS = [
+    "--service-discovery",
+    "static",
+    "--static-backends",
+    "http://localhost:8000",
+    "--static-models",
+    "facebook/opt-125m",
+    "--routing-logic",
+    "roundrobin",
+]
+
+
+def test_parse_args_when_ssl_certfile_and_keyfile_are_provided_sets_them(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setattr(
+        sys,
+        "argv",
+        [
+            sys.argv[0],
+            *STATIC_ROUTER_ARGS,
+            "--ssl-certfile",
+            "/etc/router/tls/tls.crt",
+            "--ssl-keyfile",
+            "/etc/router/tls/tls.key",
+        ],
+    )
+    args = parser.parse_args()
+    assert args.ssl_certfile == "/etc/router/tls/tls.crt"
+    assert args.ssl_keyfile == "/etc/router/tls/tls.key"
+
+
+def test_parse_args_when_ssl_flags_are_not_provided_serves_http(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setattr(sys, "argv", [sys.argv[0], *STATIC_ROUTER_ARGS])
+    args = parser.parse_args()
+    assert args.ssl_certfile is None
+    assert args.ssl_keyfile is None
+
+
+@pytest.mark.parametrize(
+    "ssl_args",
+    [
+        ["--ssl-certfile", "/etc/router/tls/tls.crt"],
+        ["--ssl-keyfile", "/etc/router/tls/tls.key"],
+    ],
+)
+def test_validate_args_when_only_one_ssl_file_is_provided_raises_valueerror(
+    monkeypatch: pytest.MonkeyPatch, ssl_args: list
+) -> None:
+    monkeypatch.setattr(sys, "argv", [sys.argv[0], *STATIC_ROUTER_ARGS, *ssl_args])
+    with pytest.raises(ValueError, match="must be provided together"):
+        parser.parse_args()
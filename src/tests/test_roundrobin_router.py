@@ -0,0 +1,53 @@
+from collections import Counter
+
+from vllm_router.routers.routing_logic import RoundRobinRouter
+from vllm_router.utils import SingletonABCMeta
+
+
+class EndpointInfo:
+    def __init__(self, url: str, weight: int = 1):
+        self.url = url
+        self.weight = weight
+
+
+def make_router() -> RoundRobinRouter:
+    SingletonABCMeta._instances.pop(RoundRobinRouter, None)
+    return RoundRobinRouter()
+
+
+def route(router: RoundRobinRouter, endpoints, count: int) -> list:
+    return [router.route_request(endpoints, None, None, None) for _ in range(count)]
+
+
+def test_route_request_when_weights_are_equal_takes_turns() -> None:
+    endpoints = [
+        EndpointInfo(url="http://engine2.com"),
+        EndpointInfo(url="http://engine1.com"),
+    ]
+    assert route(make_router(), endpoints, 4) == [
+        "http://engine1.com",
+        "http://engine2.com",
+        "http://engine1.com",
+        "http://engine2.com",
+    ]
+
+
+def test_route_request_when_weights_differ_shares_requests_by_weight() -> None:
+    endpoints = [
+        EndpointInfo(url="http://engine1.com", weight=3),
+        EndpointInfo(url="http://engine2.com", weight=1),
+    ]
+    urls = route(make_router(), endpoints, 8)
+    assert Counter(urls) == {"http://engine1.com": 6, "http://engine2.com": 2}
+    # The lighter engine is interleaved with the heavier one
+    assert urls[:4].count("http://engine2.com") == 1
+
+
+def test_route_request_when_an_endpoint_is_removed_routes_to_the_others() -> None:
+    router = make_router()
+    endpoints = [
+        EndpointInfo(url="http://engine1.com", weight=2),
+        EndpointInfo(url="http://engine2.com"),
+    ]
+    route(router, endpoints, 3)
+    assert set(route(router, endpoints[1:], 3)) == {"http://engine2.com"}
@@ -0,0 +1,110 @@
+import threading
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+import pytest
+
+from vllm_router.service_discovery import (
+    HealthCheckConfig,
+    K8sServiceDiscovery,
+    StaticServiceDiscovery,
+)
+
+
+def make_pod(namespace: str, name: str, pod_ip: str) -> SimpleNamespace:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(namespace=namespace, name=name, labels=None),
+        status=SimpleNamespace(
+            pod_ip=pod_ip, container_statuses=[SimpleNamespace(ready=True)]
+        ),
+    )
+
+
+@pytest.fixture
+def discovery(monkeypatch: pytest.MonkeyPatch) -> K8sServiceDiscovery:
+    # Skip __init__, which loads the kube config and starts the watchers
+    discovery = K8sServiceDiscovery.__new__(K8sServiceDiscovery)
+    discovery.port = "8000"
+    discovery.label_selector = None
+    discovery.k8s_api = MagicMock()
+    discovery.available_engines = {}
+    discovery.available_engines_lock = threading.Lock()
+    monkeypatch.setattr(discovery, "_get_model_name", lambda pod_ip: "opt-125m")
+    return discovery
+
+
+def watch_events(discovery: K8sServiceDiscovery, events: list) -> None:
+    def stream(*args, **kwargs):
+        yield from events
+        discovery.running = False
+
+    discovery.running = True
+    discovery._watch_engines("*", MagicMock(stream=stream))
+
+
+def test_watch_engines_when_pods_share_a_name_across_namespaces_keeps_both(
+    discovery: K8sServiceDiscovery,
+) -> None:
+    watch_events(
+        discovery,
+        [
+            {"type": "ADDED", "object": make_pod("team-a", "engine-0", "10.0.0.1")},
+            {"type": "ADDED", "object": make_pod("team-b", "engine-0", "10.0.0.2")},
+        ],
+    )
+    assert set(discovery.available_engines) == {"team-a/engine-0", "team-b/engine-0"}
+    assert sorted(info.url for info in discovery.get_endpoint_info()) == [
+        "http://10.0.0.1:8000",
+        "http://10.0.0.2:8000",
+    ]
+
+
+def test_watch_engines_when_a_pod_is_deleted_keeps_its_namesake(
+    discovery: K8sServiceDiscovery,
+) -> None:
+    watch_events(
+        discovery,
+        [
+            {"type": "ADDED", "object": make_pod("team-a", "engine-0", "10.0.0.1")},
+            {"type": "ADDED", "object": make_pod("team-b", "engine-0", "10.0.0.2")},
+            {"type": "DELETED", "object": make_pod("team-a", "engine-0", "10.0.0.1")},
+        ],
+    )
+    assert list(discovery.available_engines) == ["team-b/engine-0"]
+    assert discovery.available_engines["team-b/engine-0"].url == "http://10.0.0.2:8000"
+
+
+def test_static_get_endpoint_info_when_weight_is_zero_drains_the_backend() -> None:
+    discovery = StaticServiceDiscovery(
+        urls=["http://10.0.0.1:8000", "http://10.0.0.2:8000"],
+        models=["opt-125m", "opt-125m"],
+        weights=[3, 0],
+    )
+    assert [(info.url, info.weight) for info in discovery.get_endpoint_info()] == [
+        ("http://10.0.0.1:8000", 3)
+    ]
+
+
+def test_static_get_endpoint_info_when_health_check_fails_excludes_the_backend(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    # Record the probes by hand instead of the worker thread
+    monkeypatch.setattr(StaticServiceDiscovery, "_health_check_worker", lambda _: None)
+    discovery = StaticServiceDiscovery(
+        urls=["http://10.0.0.1:8000", "http://10.0.0.2:8000"],
+        models=["opt-125m", "opt-125m"],
+        health_check=HealthCheckConfig(success_threshold=2, failure_threshold=2),
+    )
+
+    def urls() -> list:
+        return [info.url for info in discovery.get_endpoint_info()]
+
+    discovery._record_probe("http://10.0.0.2:8000", False)
+    assert urls() == ["http://10.0.0.1:8000", "http://10.0.0.2:8000"]
+    discovery._record_probe("http://10.0.0.2:8000", False)
+    assert urls() == ["http://10.0.0.1:8000"]
+
+    discovery._record_probe("http://10.0.0.2:8000", True)
+    assert urls() == ["http://10.0.0.1:8000"]
+    discovery._record_probe("http://10.0.0.2:8000", True)
+    assert urls() == ["http://10.0.0.1:8000", "http://10.0.0.2:8000"]
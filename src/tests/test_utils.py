@@ -73,6 +73,19 @@ def test_parse_comma_separated_args_when_comma_separated_list_supplied_returns_l
     ]
 
 
+def test_parse_static_backend_weights_when_weights_supplied_returns_list_of_int() -> (
+    None
+):
+    assert utils.parse_static_backend_weights("3,1,0") == [3, 1, 0]
+
+
+def test_parse_static_backend_weights_when_weight_is_negative_raises_valueerror() -> (
+    None
+):
+    with pytest.raises(ValueError):
+        utils.parse_static_backend_weights("3,-1")
+
+
 def test_get_test_payload_returns_values_for_known_types() -> None:
     for model_type in utils.ModelType:
         assert isinstance(utils.ModelType.get_test_payload(model_type.name), dict)
@@ -61,6 +61,7 @@ from vllm_router.routers.routing_logic import (
     initialize_routing_logic,
 )
 from vllm_router.service_discovery import (
+    HealthCheckConfig,
     ServiceDiscoveryType,
     get_service_discovery,
     initialize_service_discovery,
@@ -82,6 +83,7 @@ from vllm_router.stats.request_stats import (
 from vllm_router.utils import (
     parse_comma_separated_args,
     parse_static_aliases,
+    parse_static_backend_weights,
     parse_static_urls,
     set_ulimit,
 )
@@ -132,6 +134,17 @@ def initialize_all(app: FastAPI, args):
             ServiceDiscoveryType.STATIC,
             urls=parse_static_urls(args.static_backends),
             models=parse_comma_separated_args(args.static_models),
+            weights=(
+                parse_static_backend_weights(args.static_backend_weights)
+                if args.static_backend_weights
+                else None
+            ),
+            # Only set by the initial dynamic config file
+            health_check=(
+                HealthCheckConfig(**args.health_check)
+                if getattr(args, "health_check", None)
+                else None
+            ),
             aliases=(
                 parse_static_aliases(args.static_aliases)
                 if args.static_aliases
@@ -261,6 +274,7 @@ app.include_router(batches_router)
 app.include_router(metrics_router)
 app.state.httpx_client_wrapper = HTTPXClientWrapper()
 app.state.semantic_cache_available = semantic_cache_available
+app.state.draining = False
 
 
 def main():
@@ -279,7 +293,13 @@ def main():
     # Workaround to avoid footguns where uvicorn drops requests with too
     # many concurrent requests active.
     set_ulimit()
-    uvicorn.run(app, host=args.host, port=args.port)
+    uvicorn.run(
+        app,
+        host=args.host,
+        port=args.port,
+        ssl_certfile=args.ssl_certfile,
+        ssl_keyfile=args.ssl_keyfile,
+    )
 
 
 if __name__ == "__main__":
@@ -16,19 +16,21 @@ import json
 import threading
 import time
 from dataclasses import dataclass
-from typing import Optional
+from typing import Dict, Optional
 
 from fastapi import FastAPI
 
 from vllm_router.log import init_logger
 from vllm_router.routers.routing_logic import reconfigure_routing_logic
 from vllm_router.service_discovery import (
+    HealthCheckConfig,
     ServiceDiscoveryType,
     reconfigure_service_discovery,
 )
 from vllm_router.utils import (
     SingletonMeta,
     parse_comma_separated_args,
+    parse_static_backend_weights,
     parse_static_urls,
 )
 
@@ -49,7 +51,10 @@ class DynamicRouterConfig:
     # Service discovery configurations
     static_backends: Optional[str] = None
     static_models: Optional[str] = None
+    static_backend_weights: Optional[str] = None
     static_aliases: Optional[str] = None
+    # The health checks of the static backends, the fields of HealthCheckConfig
+    health_check: Optional[Dict[str, int]] = None
     k8s_port: Optional[int] = None
     k8s_namespace: Optional[str] = None
     k8s_label_selector: Optional[str] = None
@@ -77,7 +82,10 @@ class DynamicRouterConfig:
             service_discovery=args.service_discovery,
             static_backends=args.static_backends,
             static_models=args.static_models,
+            static_backend_weights=args.static_backend_weights,
             static_aliases=args.static_aliases,
+            # Only set by the initial dynamic config file
+            health_check=getattr(args, "health_check", None),
             k8s_port=args.k8s_port,
             k8s_namespace=args.k8s_namespace,
             k8s_label_selector=args.k8s_label_selector,
@@ -92,6 +100,34 @@ class DynamicRouterConfig:
             config = json.load(f)
         return DynamicRouterConfig(**config)
 
+    @staticmethod
+    def from_dict(config: Dict) -> "DynamicRouterConfig":
+        """
+        Creates the config from a decoded json object, e.g. a pushed config.
+
+        Raises:
+            ValueError: if a required field is missing or a field is unknown
+        """
+        if not isinstance(config, dict):
+            raise ValueError("The dynamic config must be a json object")
+        try:
+            dynamic_config = DynamicRouterConfig(**config)
+            dynamic_config.static_health_check()
+            return dynamic_config
+        except TypeError as e:
+            raise ValueError(f"Invalid dynamic config: {e}") from e
+
+    def static_health_check(self) -> Optional[HealthCheckConfig]:
+        """
+        Returns the health checks of the static backends, None without.
+
+        Raises:
+            TypeError: if the health_check has an unknown field
+        """
+        if self.health_check is None:
+            return None
+        return HealthCheckConfig(**self.health_check)
+
     def to_json_str(self) -> str:
         return json.dumps(self, default=lambda o: o.__dict__, sort_keys=True, indent=4)
 
@@ -99,6 +135,7 @@ class DynamicRouterConfig:
 class DynamicConfigWatcher(metaclass=SingletonMeta):
     """
     Watches a config json file for changes and updates the DynamicRouterConfig accordingly.
+    A config pushed to the /dynamic_config endpoint is applied right away.
     """
 
     def __init__(
@@ -120,6 +157,10 @@ class DynamicConfigWatcher(metaclass=SingletonMeta):
         self.watch_interval = watch_interval
         self.current_config = init_config
         self.app = app
+        # The config last loaded from the file, which a pushed config
+        # overrides until the file changes
+        self.file_config: Optional[DynamicRouterConfig] = None
+        self.lock = threading.Lock()
 
         # Watcher thread
         self.running = True
@@ -139,6 +180,12 @@ class DynamicConfigWatcher(metaclass=SingletonMeta):
                 ServiceDiscoveryType.STATIC,
                 urls=parse_static_urls(config.static_backends),
                 models=parse_comma_separated_args(config.static_models),
+                weights=(
+                    parse_static_backend_weights(config.static_backend_weights)
+                    if config.static_backend_weights
+                    else None
+                ),
+                health_check=config.static_health_check(),
             )
         elif config.service_discovery == "k8s":
             reconfigure_service_discovery(
@@ -187,6 +234,21 @@ class DynamicConfigWatcher(metaclass=SingletonMeta):
         self.reconfigure_batch_api(config)
         self.reconfigure_stats(config)
 
+    def apply_config(self, config: DynamicRouterConfig) -> bool:
+        """
+        Reconfigures the router with the given config unless it is the
+        current one.
+
+        Returns:
+            True if the router was reconfigured, False otherwise
+        """
+        with self.lock:
+            if config == self.current_config:
+                return False
+            self.reconfigure_all(config)
+            self.current_config = config
+            return True
+
     def _sleep_or_break(self, check_interval: float = 1):
         """
         Sleep for self.watch_interval seconds if self.running is True.
@@ -206,15 +268,14 @@ class DynamicConfigWatcher(metaclass=SingletonMeta):
         while self.running:
             try:
                 config = DynamicRouterConfig.from_json(self.config_json)
-                if config != self.current_config:
-                    logger.info(
-                        f"DynamicConfigWatcher: Config changed, reconfiguring..."
-                    )
-                    self.reconfigure_all(config)
-                    logger.info(
-                        f"DynamicConfigWatcher: Config reconfiguration complete"
-                    )
-                    self.current_config = config
+                # A pushed config is kept until the file changes, e.g. while
+                # the kubelet has not synced the ConfigMap yet
+                if config != self.file_config:
+                    if self.apply_config(config):
+                        logger.info(
+                            f"DynamicConfigWatcher: Config file changed, reconfiguration complete"
+                        )
+                    self.file_config = config
             except Exception as e:
                 logger.warning(f"DynamicConfigWatcher: Error loading config file: {e}")
 
@@ -77,6 +77,12 @@ def validate_args(args):
             raise ValueError(
                 "Static models must be provided when using static service discovery."
             )
+        if args.static_backend_weights is not None and len(
+            args.static_backend_weights.split(",")
+        ) != len(args.static_backends.split(",")):
+            raise ValueError(
+                "Static backend weights must be provided for each static backend."
+            )
     if args.service_discovery == "k8s" and args.k8s_port is None:
         raise ValueError("K8s port must be provided when using K8s service discovery.")
     if args.routing_logic == "session" and args.session_key is None:
@@ -89,6 +95,8 @@ def validate_args(args):
         raise ValueError("Engine stats interval must be greater than 0.")
     if args.request_stats_window <= 0:
         raise ValueError("Request stats window must be greater than 0.")
+    if (args.ssl_certfile is None) != (args.ssl_keyfile is None):
+        raise ValueError("SSL certificate and key files must be provided together.")
 
 
 def parse_args():
@@ -99,6 +107,18 @@ def parse_args():
     parser.add_argument(
         "--port", type=int, default=8001, help="The port to run the server on."
     )
+    parser.add_argument(
+        "--ssl-certfile",
+        type=str,
+        default=None,
+        help="The SSL certificate file. Serves HTTPS together with --ssl-keyfile.",
+    )
+    parser.add_argument(
+        "--ssl-keyfile",
+        type=str,
+        default=None,
+        help="The SSL key file. Serves HTTPS together with --ssl-certfile.",
+    )
     parser.add_argument(
         "--service-discovery",
         type=str,
@@ -117,6 +137,14 @@ def parse_args():
         default=None,
         help="The models of static backends, separated by commas. E.g., model1,model2",
     )
+    parser.add_argument(
+        "--static-backend-weights",
+        type=str,
+        default=None,
+        help="The weights of static backends, separated by commas, 1 each by default. "
+        "The backends share the traffic in proportion to their weights, a backend "
+        "of weight 0 is drained. E.g., 3,1",
+    )
     parser.add_argument(
         "--static-aliases",
         type=str,
@@ -145,7 +173,8 @@ def parse_args():
         "--k8s-namespace",
         type=str,
         default="default",
-        help="The namespace of vLLM pods when using K8s service discovery.",
+        help="The namespace of vLLM pods when using K8s service discovery. "
+        "Several namespaces are separated by commas, * watches all namespaces.",
     )
     parser.add_argument(
         "--k8s-label-selector",
@@ -11,12 +11,15 @@
 # WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 # See the License for the specific language governing permissions and
 # limitations under the License.
+import asyncio
+import hmac
 import json
+import os
 
 from fastapi import APIRouter, BackgroundTasks, Request
 from fastapi.responses import JSONResponse, Response
 
-from vllm_router.dynamic_config import get_dynamic_config_watcher
+from vllm_router.dynamic_config import DynamicRouterConfig, get_dynamic_config_watcher
 from vllm_router.log import init_logger
 from vllm_router.protocols import ModelCard, ModelList
 from vllm_router.service_discovery import get_service_discovery
@@ -137,15 +140,92 @@ async def show_models():
     return JSONResponse(content=model_list.model_dump())
 
 
+@main_router.post("/drain")
+async def drain(request: Request) -> Response:
+    """
+    Endpoint to start draining the router before it shuts down.
+
+    Once called, /health fails so that load balancers stop sending new
+    connections while in-flight requests complete. Only requests from the
+    loopback interface, e.g. a preStop hook, are accepted.
+
+    Returns:
+        Response: A JSONResponse with status code 403 for a remote client,
+        or status code 200 once draining started.
+    """
+    if request.client is None or request.client.host not in ("127.0.0.1", "::1"):
+        return JSONResponse(
+            content={"status": "Draining is only allowed from localhost."},
+            status_code=403,
+        )
+    if not getattr(request.app.state, "draining", False):
+        logger.info("Draining the router, /health reports unavailable from now on")
+    request.app.state.draining = True
+    return JSONResponse(content={"status": "draining"}, status_code=200)
+
+
+@main_router.post("/dynamic_config")
+async def apply_dynamic_config(request: Request) -> Response:
+    """
+    Endpoint to apply a dynamic config, e.g. pushed by the router controller.
+
+    The config takes effect right away and is kept until the dynamic config
+    file changes. It requires the router to be started with
+    --dynamic-config-json and the VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN environment
+    variable, which the push must carry as its bearer token.
+
+    Returns:
+        Response: A JSONResponse with status code 403 without a token
+        configured, 401 for a push without the token, 409 without dynamic
+        config, 400 for an invalid config, 500 if the router cannot be
+        reconfigured, or 200 once the config is applied.
+    """
+    token = os.getenv("VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN")
+    if not token:
+        return JSONResponse(
+            content={
+                "status": "Pushing the dynamic config requires "
+                "VLLM_ROUTER_DYNAMIC_CONFIG_TOKEN."
+            },
+            status_code=403,
+        )
+    authorization = request.headers.get("Authorization", "")
+    if not hmac.compare_digest(authorization.encode(), f"Bearer {token}".encode()):
+        return JSONResponse(
+            content={"status": "Invalid dynamic config token."},
+            status_code=401,
+            headers={"WWW-Authenticate": "Bearer"},
+        )
+
+    watcher = get_dynamic_config_watcher()
+    if watcher is None:
+        return JSONResponse(
+            content={"status": "Dynamic config is not enabled."},
+            status_code=409,
+        )
+    try:
+        config = DynamicRouterConfig.from_dict(await request.json())
+    except ValueError as e:
+        return JSONResponse(content={"status": str(e)}, status_code=400)
+    try:
+        applied = await asyncio.to_thread(watcher.apply_config, config)
+    except Exception as e:
+        logger.error(f"Failed to apply the pushed dynamic config: {e}")
+        return JSONResponse(content={"status": str(e)}, status_code=500)
+    if applied:
+        logger.info("Applied the pushed dynamic config")
+    return JSONResponse(content={"status": "applied"}, status_code=200)
+
+
 @main_router.get("/health")
-async def health() -> Response:
+async def health(request: Request) -> Response:
     """
     Endpoint to check the health status of various components.
 
     This function verifies the health of the service discovery module and
-    the engine stats scraper. If either component is down, it returns a
-    503 response with the appropriate status message. If both components
-    are healthy, it returns a 200 OK response.
+    the engine stats scraper. If either component is down, or the router is
+    draining, it returns a 503 response with the appropriate status message.
+    If both components are healthy, it returns a 200 OK response.
 
     Returns:
         Response: A JSONResponse with status code 503 if a component is
@@ -153,6 +233,8 @@ async def health() -> Response:
         are healthy.
     """
 
+    if getattr(request.app.state, "draining", False):
+        return JSONResponse(content={"status": "Router is draining."}, status_code=503)
     if not get_service_discovery().get_health():
         return JSONResponse(
             content={"status": "Service discovery module is down."}, status_code=503
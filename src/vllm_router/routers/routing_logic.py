@@ -80,7 +80,8 @@ class RoundRobinRouter(RoutingInterface):
     def __init__(self):
         if hasattr(self, "_initialized"):
             return
-        self.req_id = 0
+        # The current weight of each engine of the smooth weighted round-robin
+        self.current_weights: Dict[str, int] = {}
         self._initialized = True
 
     def route_request(
@@ -91,8 +92,10 @@ class RoundRobinRouter(RoutingInterface):
         request: Request,
     ) -> str:
         """
-        Route the request to the appropriate engine URL using a simple
-        round-robin algorithm
+        Route the request to the appropriate engine URL using a smooth
+        weighted round-robin algorithm: the engines receive the requests in
+        proportion to their weights, interleaved, and engines of equal weight
+        take turns in the order of their URLs.
 
         Args:
             endpoints (List[EndpointInfo]): The list of engine URLs
@@ -102,9 +105,20 @@ class RoundRobinRouter(RoutingInterface):
                 indicating the request-level performance of each engine
             request (Request): The incoming request
         """
-        len_engines = len(endpoints)
-        chosen = sorted(endpoints, key=lambda e: e.url)[self.req_id % len_engines]
-        self.req_id += 1
+        total_weight = sum(e.weight for e in endpoints)
+        current_weights = {}
+        chosen = None
+        for endpoint in sorted(endpoints, key=lambda e: e.url):
+            current_weights[endpoint.url] = (
+                self.current_weights.get(endpoint.url, 0) + endpoint.weight
+            )
+            if (
+                chosen is None
+                or current_weights[endpoint.url] > current_weights[chosen.url]
+            ):
+                chosen = endpoint
+        current_weights[chosen.url] -= total_weight
+        self.current_weights = current_weights
         return chosen.url
 
 
@@ -329,12 +343,17 @@ class PrefixAwareRouter(RoutingInterface):
             longest prefix match)
         """
 
-        available_endpoints = set(endpoint.url for endpoint in endpoints)
+        weights = {endpoint.url: endpoint.weight for endpoint in endpoints}
+        available_endpoints = set(weights)
         _, matched_endpoint = await self.hashtrie.longest_prefix_match(
             request_json["prompt"], available_endpoints
         )
 
-        selected_endpoint = random.choice(list(matched_endpoint))
+        # Pick among the matching engines in proportion to their weights
+        matched_endpoint = list(matched_endpoint)
+        selected_endpoint = random.choices(
+            matched_endpoint, weights=[weights[url] for url in matched_endpoint]
+        )[0]
 
         await self.hashtrie.insert(request_json["prompt"], selected_endpoint)
 
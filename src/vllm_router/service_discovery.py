@@ -17,6 +17,7 @@ import enum
 import os
 import threading
 import time
+from concurrent.futures import ThreadPoolExecutor
 from dataclasses import dataclass
 from typing import Dict, List, Optional
 
@@ -49,6 +50,23 @@ class EndpointInfo:
     # Model label
     model_label: str
 
+    # Share of the traffic of the endpoint relative to the other endpoints
+    weight: int = 1
+
+
+@dataclass
+class HealthCheckConfig:
+    """
+    The health checks of the static backends. A backend is excluded after
+    failure_threshold consecutive failed probes of its /health endpoint and
+    included again after success_threshold consecutive successful ones.
+    """
+
+    timeout_seconds: int = 5
+    period_seconds: int = 10
+    success_threshold: int = 1
+    failure_threshold: int = 3
+
 
 class ServiceDiscovery(metaclass=abc.ABCMeta):
     @abc.abstractmethod
@@ -83,53 +101,150 @@ class StaticServiceDiscovery(ServiceDiscovery):
         self,
         urls: List[str],
         models: List[str],
-        aliases: List[str] | None,
-        model_labels: List[str] | None,
-        model_types: List[str] | None,
+        aliases: List[str] | None = None,
+        model_labels: List[str] | None = None,
+        model_types: List[str] | None = None,
+        weights: List[int] | None = None,
+        health_check: HealthCheckConfig | None = None,
     ):
         assert len(urls) == len(models), "URLs and models should have the same length"
+        assert weights is None or len(weights) == len(
+            urls
+        ), "URLs and weights should have the same length"
         self.urls = urls
         self.models = models
         self.aliases = aliases
         self.model_labels = model_labels
         self.model_types = model_types
+        self.weights = weights
         self.added_timestamp = int(time.time())
 
+        # The backends failing their health check, and the consecutive
+        # results of the health check of each backend
+        self.health_check = health_check
+        self.unhealthy_urls = set()
+        self.consecutive_successes: Dict[str, int] = {}
+        self.consecutive_failures: Dict[str, int] = {}
+        self.health_lock = threading.Lock()
+        self.health_check_thread = None
+        if health_check is not None:
+            self.stopped = threading.Event()
+            self.health_check_thread = threading.Thread(
+                target=self._health_check_worker, daemon=True
+            )
+            self.health_check_thread.start()
+
+    def _probe(self, url: str) -> bool:
+        """
+        Probe the /health endpoint of a backend.
+
+        Returns:
+            True if the backend answered with status code 200, False otherwise
+        """
+        try:
+            response = requests.get(
+                f"{url.rstrip('/')}/health", timeout=self.health_check.timeout_seconds
+            )
+            return response.status_code == 200
+        except requests.RequestException:
+            return False
+
+    def _record_probe(self, url: str, healthy: bool):
+        """
+        Count the result of a health probe of a backend and exclude or include
+        the backend once a threshold is crossed.
+        """
+        with self.health_lock:
+            if healthy:
+                self.consecutive_failures[url] = 0
+                successes = self.consecutive_successes.get(url, 0) + 1
+                self.consecutive_successes[url] = successes
+                if (
+                    url in self.unhealthy_urls
+                    and successes >= self.health_check.success_threshold
+                ):
+                    self.unhealthy_urls.discard(url)
+                    logger.info(f"Static backend {url} is healthy again")
+            else:
+                self.consecutive_successes[url] = 0
+                failures = self.consecutive_failures.get(url, 0) + 1
+                self.consecutive_failures[url] = failures
+                if (
+                    url not in self.unhealthy_urls
+                    and failures >= self.health_check.failure_threshold
+                ):
+                    self.unhealthy_urls.add(url)
+                    logger.warning(
+                        f"Static backend {url} failed {failures} consecutive "
+                        "health checks, not routing to it"
+                    )
+
+    def _health_check_worker(self):
+        """
+        Probe the backends concurrently every period_seconds until closed.
+        """
+        with ThreadPoolExecutor(max_workers=max(len(self.urls), 1)) as executor:
+            while not self.stopped.is_set():
+                for url, healthy in zip(
+                    self.urls, executor.map(self._probe, self.urls)
+                ):
+                    self._record_probe(url, healthy)
+                self.stopped.wait(self.health_check.period_seconds)
+
     def get_endpoint_info(self) -> List[EndpointInfo]:
         """
         Get the URLs of the serving engines that are available for
         querying.
 
         Returns:
-            a list of engine URLs
+            a list of engine URLs, without the engines of weight 0 and the
+            engines failing their health check
         """
 
+        model_labels = self.model_labels or [None] * len(self.urls)
+        weights = self.weights or [1] * len(self.urls)
+        with self.health_lock:
+            unhealthy_urls = set(self.unhealthy_urls)
         endpoint_infos = [
-            EndpointInfo(url, model, self.added_timestamp, model_label)
-            for url, model, model_label in zip(
-                self.urls, self.models, self.model_labels
+            EndpointInfo(url, model, self.added_timestamp, model_label, weight)
+            for url, model, model_label, weight in zip(
+                self.urls, self.models, model_labels, weights
             )
+            if weight > 0 and url not in unhealthy_urls
         ]
         return endpoint_infos
 
+    def close(self):
+        """
+        Close the service discovery module, stopping the health checks.
+        """
+        if self.health_check_thread is not None:
+            self.stopped.set()
+            self.health_check_thread.join()
+
 
 class K8sServiceDiscovery(ServiceDiscovery):
     def __init__(self, namespace: str, port: str, label_selector=None):
         """
         Initialize the Kubernetes service discovery module. This module
-        assumes all serving engine pods are in the same namespace, listening
-        on the same port, and have the same label selector.
+        assumes all serving engine pods are listening on the same port and
+        have the same label selector.
 
-        It will start a daemon thread to watch the engine pods and update
-        the url of the available engines.
+        It will start a daemon thread per watched namespace to watch the
+        engine pods and update the url of the available engines.
 
         Args:
-            namespace: the namespace of the engine pods
+            namespace: the namespace of the engine pods, a comma-separated
+                list of namespaces, or "*" for all namespaces
             port: the port of the engines
             label_selector: the label selector of the engines
         """
         self.namespace = namespace
+        self.namespaces = [ns.strip() for ns in namespace.split(",") if ns.strip()]
+        if "*" in self.namespaces:
+            self.namespaces = ["*"]
         self.port = port
+        # The available engines keyed by <namespace>/<pod name>
         self.available_engines: Dict[str, EndpointInfo] = {}
         self.available_engines_lock = threading.Lock()
         self.label_selector = label_selector
@@ -141,12 +256,18 @@ class K8sServiceDiscovery(ServiceDiscovery):
             config.load_kube_config()
 
         self.k8s_api = client.CoreV1Api()
-        self.k8s_watcher = watch.Watch()
+        self.k8s_watchers = [watch.Watch() for _ in self.namespaces]
 
         # Start watching engines
         self.running = True
-        self.watcher_thread = threading.Thread(target=self._watch_engines, daemon=True)
-        self.watcher_thread.start()
+        self.watcher_threads = [
+            threading.Thread(
+                target=self._watch_engines, args=(ns, watcher), daemon=True
+            )
+            for ns, watcher in zip(self.namespaces, self.k8s_watchers)
+        ]
+        for watcher_thread in self.watcher_threads:
+            watcher_thread.start()
 
     @staticmethod
     def _check_pod_ready(container_statuses):
@@ -199,20 +320,26 @@ class K8sServiceDiscovery(ServiceDiscovery):
             return None
         return pod.metadata.labels.get("model")
 
-    def _watch_engines(self):
+    def _watch_engines(self, namespace: str, k8s_watcher: watch.Watch):
         # TODO (ApostaC): remove the hard-coded timeouts
 
+        if namespace == "*":
+            list_pods, kwargs = self.k8s_api.list_pod_for_all_namespaces, {}
+        else:
+            list_pods = self.k8s_api.list_namespaced_pod
+            kwargs = {"namespace": namespace}
         while self.running:
             try:
-                for event in self.k8s_watcher.stream(
-                    self.k8s_api.list_namespaced_pod,
-                    namespace=self.namespace,
+                for event in k8s_watcher.stream(
+                    list_pods,
                     label_selector=self.label_selector,
                     timeout_seconds=30,
+                    **kwargs,
                 ):
                     pod = event["object"]
                     event_type = event["type"]
-                    pod_name = pod.metadata.name
+                    # Pods of different namespaces may share a name
+                    engine_name = f"{pod.metadata.namespace}/{pod.metadata.name}"
                     pod_ip = pod.status.pod_ip
                     is_pod_ready = self._check_pod_ready(pod.status.container_statuses)
                     if is_pod_ready:
@@ -222,7 +349,7 @@ class K8sServiceDiscovery(ServiceDiscovery):
                         model_name = None
                         model_label = None
                     self._on_engine_update(
-                        pod_name,
+                        engine_name,
                         pod_ip,
                         event_type,
                         is_pod_ready,
@@ -312,15 +439,19 @@ class K8sServiceDiscovery(ServiceDiscovery):
         Returns:
             True if the service discovery module is healthy, False otherwise
         """
-        return self.watcher_thread.is_alive()
+        return all(
+            watcher_thread.is_alive() for watcher_thread in self.watcher_threads
+        )
 
     def close(self):
         """
         Close the service discovery module.
         """
         self.running = False
-        self.k8s_watcher.stop()
-        self.watcher_thread.join()
+        for watcher in self.k8s_watchers:
+            watcher.stop()
+        for watcher_thread in self.watcher_threads:
+            watcher_thread.join()
 
 
 def _create_service_discovery(
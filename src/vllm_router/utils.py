@@ -132,6 +132,15 @@ def parse_comma_separated_args(comma_separated_string: str):
     return comma_separated_string.split(",")
 
 
+def parse_static_backend_weights(static_backend_weights: str):
+    weights = [int(weight) for weight in static_backend_weights.split(",")]
+    if any(weight < 0 for weight in weights):
+        raise ValueError(
+            f"Static backend weights must not be negative: {static_backend_weights}"
+        )
+    return weights
+
+
 def parse_static_aliases(static_aliases: str):
     aliases = {}
     for alias_and_model in static_aliases.split(","):